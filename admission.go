@@ -0,0 +1,71 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrTotalProcessLimitReached is returned by Register when the monitor
+// already holds the maximum number of processes.
+var ErrTotalProcessLimitReached = errors.New("total process limit reached")
+
+// admit counts the processes as waiting and adds their weight to the queue
+// weight. If the processes do not fit in the queue capacity, the total
+// process limit, or the maximum queue weight, none of them is admitted and
+// ErrQueueFull, ErrTotalProcessLimitReached, or ErrQueueWeightExceeded is
+// returned. An errored pool admits no process.
+func (w *workerPool) admit(args ...Process) error {
+	if err := w.errored(); err != nil {
+		return err
+	}
+
+	if err := w.subPools.check(args); err != nil {
+		return err
+	}
+
+	count := int64(len(args))
+	if err := w.reserveQueue(args); err != nil {
+		return err
+	}
+
+	// A process that is registered again replaces the stats of its previous
+	// run, so it is tracked already.
+	tracked := count - int64(w.registered(args))
+	if total, ok := reserve(&w.counters.tracked, tracked, int64(w.config.MaxTotalProcesses)); !ok {
+		atomic.AddInt64(&w.counters.waiting, -count)
+		return fmt.Errorf("%w: %d > %d", ErrTotalProcessLimitReached, total, w.config.MaxTotalProcesses)
+	}
+
+	var weight int64
+	for _, p := range args {
+		weight += processWeight(p)
+	}
+
+	if total, ok := reserve(&w.counters.weight, weight, int64(w.config.MaxQueueWeight)); !ok {
+		atomic.AddInt64(&w.counters.waiting, -count)
+		atomic.AddInt64(&w.counters.tracked, -tracked)
+		return fmt.Errorf("%w: %d > %d", ErrQueueWeightExceeded, total, w.config.MaxQueueWeight)
+	}
+
+	return nil
+}
+
+// reserve adds n to the counter if the result does not exceed max. A max of
+// zero means no limit. It returns the would-be total and whether it has been
+// added.
+func reserve(counter *int64, n, max int64) (int64, bool) {
+	for {
+		current := atomic.LoadInt64(counter)
+		if max > 0 && current+n > max {
+			return current + n, false
+		}
+		if atomic.CompareAndSwapInt64(counter, current, current+n) {
+			return current + n, true
+		}
+	}
+}
@@ -0,0 +1,41 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Register should fail once the total process limit is reached
+func TestWithMaxTotalProcesses(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2), WithMaxTotalProcesses(5))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	for i, p := range createProcess(6, 1, 0, processFuncWithoutLog) {
+		err := wp.Register(p)
+		if i < 5 {
+			a.NoError(err)
+		} else {
+			a.ErrorIs(err, ErrTotalProcessLimitReached)
+		}
+	}
+	wp.Wait()
+	a.Equal(int64(5), wp.Stats().TotalRegistered)
+
+	// Purged processes free their place.
+	a.Equal(5, wp.Monitor().Purge(time.Now()))
+	a.NoError(wp.Register(createProcess(5, 2, 0, processFuncWithoutLog)...))
+	a.ErrorIs(wp.Register(createProcess(1, 3, 0, processFuncWithoutLog)...), ErrTotalProcessLimitReached)
+
+	wp.Wait()
+	err = wp.Close()
+	a.NoError(err)
+}
@@ -0,0 +1,185 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"fmt"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// pinnedProcess wraps a process to run it on a specific worker.
+type pinnedProcess struct {
+	Process
+	worker WorkerName
+}
+
+// WithWorker wraps the process to run it on the worker with the given name
+// only, for processes that hold state bound to one goroutine, such as SQLite
+// connections and CGo bindings. If the worker is busy, the process waits for
+// it even if other workers are idle, the processes that are pinned to a
+// worker run in their registration order before the shared queue, and every
+// retry of the process runs on the same worker. Register returns
+// ErrWorkerNotFound if the pool has no worker with that name, and the process
+// fails with ErrWorkerNotFound if the worker is retired before it picks up
+// the process. A sleeping worker is woken up for the process. Like the
+// processes that are submitted with ForWorker, the pinned processes are not
+// frozen.
+func WithWorker(p Process, name WorkerName) Process {
+	return pinnedProcess{Process: p, worker: name}
+}
+
+// unwrap returns the wrapped process.
+func (p pinnedProcess) unwrap() Process {
+	return p.Process
+}
+
+// pinnedWorker returns the worker of the process that is set by WithWorker.
+func pinnedWorker(p Process) (WorkerName, bool) {
+	var name WorkerName
+	found := findLayer(p, func(l Process) bool {
+		pp, ok := l.(pinnedProcess)
+		if ok {
+			name = pp.worker
+		}
+		return ok
+	})
+
+	return name, found
+}
+
+// checkWorkers returns ErrWorkerNotFound if a process is pinned to a worker
+// that the pool does not have.
+func (w *workerPool) checkWorkers(args []Process) error {
+	w.workersMutex.RLock()
+	defer w.workersMutex.RUnlock()
+
+	for _, p := range args {
+		if name, ok := pinnedWorker(p); ok && !w.hasWorker(name) {
+			return fmt.Errorf("%w: %s", ErrWorkerNotFound, name)
+		}
+	}
+
+	return nil
+}
+
+// hasWorker reports whether the pool has the worker, or creates it when the
+// pool starts. The caller must hold the workers mutex.
+func (w *workerPool) hasWorker(name WorkerName) bool {
+	if _, ok := w.controls[name]; ok {
+		return true
+	}
+
+	if _, ok := w.sleeping[name]; ok {
+		return true
+	}
+
+	if len(w.workers) > 0 {
+		return false
+	}
+
+	for i := 0; i < w.size; i++ {
+		if name == WorkerName(fmt.Sprintf(defaultWorkerName, w.nextWorker+i)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dispatch publishes the process to the inbox of its worker, once the pool
+// has started and the processes that have been pinned to the worker before
+// it are delivered. It returns false if the pool is closed.
+func (w *workerPool) dispatch(p Process, name WorkerName) bool {
+	if w.queue.isClosed() {
+		return false
+	}
+
+	w.workersMutex.Lock()
+	prev, ok := w.pinned[name]
+	if !ok {
+		prev = make(chan struct{})
+		close(prev)
+	}
+	turn := make(chan struct{})
+	w.pinned[name] = turn
+	w.workersMutex.Unlock()
+
+	pc := w.controlPanel.get(p.PID())
+	go func() {
+		defer close(turn)
+
+		select {
+		case <-prev:
+		case <-pc.ctx.Done():
+			w.killPinned(p)
+			<-prev
+			return
+		}
+
+		for {
+			w.wakeWorker(name)
+			changed := w.changes.wait()
+			w.workersMutex.RLock()
+			control, ok := w.controls[name]
+			pending := !ok && w.hasWorker(name)
+			w.workersMutex.RUnlock()
+
+			switch {
+			case ok:
+				if w.deliver(p, pc, control) {
+					return
+				}
+				continue
+			case !pending:
+				w.failPinned(p, pc, name)
+				return
+			}
+
+			select {
+			case <-changed:
+			case <-pc.ctx.Done():
+				w.killPinned(p)
+				return
+			}
+		}
+	}()
+
+	return true
+}
+
+// deliver sends the process to the inbox of the worker. It returns false if
+// the worker has quit before it picked up the process, so the worker has to
+// be looked up again.
+func (w *workerPool) deliver(p Process, pc *processContext, control *workerControl) bool {
+	select {
+	case control.inbox <- p:
+	case <-control.quit:
+		return false
+	case <-pc.ctx.Done():
+		w.killPinned(p)
+	case <-w.closeSignal():
+		w.cancel(p)
+	}
+
+	return true
+}
+
+// killPinned finishes the pinned process that has been killed before its
+// worker picked it up.
+func (w *workerPool) killPinned(p Process) {
+	stats := w.processes.get(p.PID())
+	stats.Status = process.Killed
+	w.abandon(p, stats)
+}
+
+// failPinned fails the process whose worker does not exist anymore.
+func (w *workerPool) failPinned(p Process, pc *processContext, name WorkerName) {
+	stats := w.processes.get(p.PID())
+	stats.err = fmt.Errorf("%w: %s", ErrWorkerNotFound, name)
+	stats.Status = process.Failed
+	pc.cancel()
+	w.abandon(p, stats)
+}
@@ -0,0 +1,80 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// A pinned process should wait for its busy worker while other workers are idle
+func TestWithWorker(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Register(
+		WithWorker(newTestProcess("first", 1, 50*time.Millisecond, processFuncWithoutLog), "W1"),
+		WithWorker(newTestProcess("second", 2, 0, processFuncWithoutLog), "W1"),
+	))
+	a.NoError(wp.Start(context.Background()))
+
+	_, err := wp.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.NoError(err)
+	a.Equal(process.Waiting, processStats(t, wp.Monitor(), "p-2").Status)
+
+	a.NoError(wp.Wait())
+	m := wp.Monitor()
+	a.Equal(WorkerName("W1"), processStats(t, m, "p-1").WorkerName)
+	a.Equal(WorkerName("W1"), processStats(t, m, "p-2").WorkerName)
+	a.NoError(wp.Close())
+}
+
+// Register should reject a process that is pinned to an unknown worker
+func TestWithWorker_NotFound(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.ErrorIs(wp.Register(WithWorker(newTestProcess("p", 1, 0, processFuncWithoutLog), "W2")), ErrWorkerNotFound)
+	a.NoError(wp.Start(context.Background()))
+	a.ErrorIs(wp.RegisterBatch(WithWorker(newTestProcess("p", 1, 0, processFuncWithoutLog), "W9")), ErrWorkerNotFound)
+	_, ok := wp.Monitor().ProcessStats("p-1")
+	a.False(ok)
+	a.NoError(wp.Close())
+}
+
+// A pinned process should fail if its worker is retired before it runs
+func TestWithWorker_Retired(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(
+		WithWorker(newTestProcess("busy", 1, 50*time.Millisecond, processFuncWithoutLog), "W1"),
+		WithWorker(newTestProcess("pinned", 2, 0, processFuncWithoutLog), "W1"),
+	))
+	_, err := wp.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.NoError(err)
+	a.NoError(wp.Resize(0))
+
+	status, err := wp.WaitUntilStatus(context.Background(), "p-2", process.Failed)
+	a.NoError(err)
+	a.Equal(process.Failed, status)
+	a.ErrorIs(processError(t, wp.Monitor(), "p-2"), ErrWorkerNotFound)
+	a.NoError(wp.Close())
+}
+
+// The router should register a pinned process in the pool of the worker
+func TestRouterPool_WithWorker(t *testing.T) {
+	a := assert.New(t)
+	pools := []Pool{NewPool(WithWorkerCount(1)), NewPool(WithWorkerCount(1))}
+	router := NewConsistentHashRouter(pools, 10)
+	a.NoError(router.Start(context.Background()))
+	a.NoError(router.Register(WithWorker(newTestProcess("p", 1, 0, processFuncWithoutLog), "1/W0")))
+	a.ErrorIs(router.Register(WithWorker(newTestProcess("p", 2, 0, processFuncWithoutLog), "2/W0")), ErrWorkerNotFound)
+	a.NoError(router.Wait())
+	a.Equal(WorkerName("W0"), processStats(t, pools[1].Monitor(), "p-1").WorkerName)
+	a.NoError(router.Close())
+}
@@ -0,0 +1,95 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// barrierProcess is a no-op process that marks the end of a group of
+// processes.
+type barrierProcess struct {
+	pid PID
+}
+
+// Start does nothing, a barrier only waits for its group.
+func (b barrierProcess) Start(context.Context) error {
+	return nil
+}
+
+// Name returns the barrier process name.
+func (b barrierProcess) Name() string {
+	return "barrier"
+}
+
+// PID returns the barrier process id.
+func (b barrierProcess) PID() PID {
+	return b.pid
+}
+
+// RegisterBarrier registers a barrier process with barrierPID that becomes
+// dispatchable only after every process in group reaches a final state. The
+// barrier stays in Waiting state until then, unless it is killed or the pool
+// is closed first. It is a lightweight way to wait for a group of processes
+// in a linear pipeline. It returns
+// ErrProcessNotFound if a process of the group is not registered.
+func (w *workerPool) RegisterBarrier(barrierPID PID, group []PID) error {
+	dones := make([]chan struct{}, 0, len(group))
+	for _, pid := range group {
+		pc := w.controlPanel.get(pid)
+		if pc == nil {
+			return w.pidError("register the barrier", ErrProcessNotFound, pid)
+		}
+		dones = append(dones, pc.done)
+	}
+
+	b := barrierProcess{pid: barrierPID}
+	err := w.claimPIDs([]Process{b}, func(args []Process) error {
+		// The barrier is not skipped like a duplicate process.
+		if len(args) == 0 {
+			return w.pidError("register the barrier", ErrProcessActive, barrierPID)
+		}
+		if err := w.admit(b); err != nil {
+			return err
+		}
+		w.prepare(b)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	go w.awaitBarrier(b, dones)
+
+	return nil
+}
+
+// awaitBarrier queues the barrier once every process of its group has
+// reached a final state. The barrier is Killed if it is killed meanwhile,
+// and it is Cancelled if the pool is closed.
+func (w *workerPool) awaitBarrier(b barrierProcess, dones []chan struct{}) {
+	pc := w.controlPanel.get(b.pid)
+	closed := w.closeSignal()
+	for _, finished := range dones {
+		select {
+		case <-finished:
+		case <-pc.ctx.Done():
+			stats := w.processes.get(b.pid)
+			stats.Status = process.Killed
+			w.abandon(b, stats)
+			return
+		case <-closed:
+			w.cancel(b)
+			return
+		}
+	}
+
+	if !w.publish(b) {
+		w.cancel(b)
+	}
+}
@@ -0,0 +1,68 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// Barrier should start only after all processes of its group are done
+func TestWorkerPool_RegisterBarrier(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(4))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	err = wp.RegisterBarrier("barrier", []PID{"p-unknown"})
+	a.ErrorIs(err, ErrProcessNotFound)
+
+	err = wp.Register(createProcess(3, 1, 200*time.Millisecond, processFunc)...)
+	a.NoError(err)
+	err = wp.RegisterBarrier("barrier", []PID{"p-11", "p-12", "p-13"})
+	a.NoError(err)
+	time.Sleep(100 * time.Millisecond)
+	a.Equal(process.Waiting, processStats(t, wp.Monitor(), "barrier").Status)
+
+	time.Sleep(300 * time.Millisecond)
+	barrier := processStats(t, wp.Monitor(), "barrier")
+	a.Equal(process.Succeeded, barrier.Status)
+	for _, pid := range []PID{"p-11", "p-12", "p-13"} {
+		a.False(barrier.StartedAt.Before(processStats(t, wp.Monitor(), pid).FinishedAt))
+	}
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// A waiting barrier should be finished when it is killed or the pool is
+// closed
+func TestWorkerPool_RegisterBarrierClose(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(newTestProcess("job", 1, time.Minute, processFuncWithoutLog)))
+	a.NoError(wp.RegisterBarrier("killed", []PID{"p-1"}))
+	a.NoError(wp.RegisterBarrier("closed", []PID{"p-1"}))
+
+	a.NoError(wp.Kill("killed"))
+	status, err := wp.WaitUntilStatus(context.Background(), "killed", process.Killed)
+	a.NoError(err)
+	a.Equal(process.Killed, status)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_ = wp.CloseContext(ctx)
+	status, err = wp.WaitUntilStatus(context.Background(), "closed", process.Cancelled)
+	a.NoError(err)
+	a.Equal(process.Cancelled, status)
+	a.ErrorIs(processError(t, wp.Monitor(), "closed"), ErrPoolClosed)
+	a.Zero(wp.Stats().QueueDepth)
+}
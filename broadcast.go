@@ -0,0 +1,39 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import "sync"
+
+// broadcaster notifies every waiting goroutine when the pool state changes.
+// Waiters get a channel from wait, check the state, and block on the channel
+// until the next change.
+type broadcaster struct {
+	mutex sync.Mutex
+	ch    chan struct{}
+}
+
+// newBroadcaster makes a new instance of broadcaster.
+func newBroadcaster() *broadcaster {
+	return &broadcaster{
+		ch: make(chan struct{}),
+	}
+}
+
+// wait returns a channel that is closed on the next broadcast.
+func (b *broadcaster) wait() <-chan struct{} {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.ch
+}
+
+// broadcast wakes up all the waiters.
+func (b *broadcaster) broadcast() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	close(b.ch)
+	b.ch = make(chan struct{})
+}
@@ -0,0 +1,290 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/circuit"
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/hamed-yousefi/gowl/status/worker"
+)
+
+type (
+	// changeNotifier is implemented by the monitors that can tell when the
+	// state of the pool changes.
+	changeNotifier interface {
+		// changed returns a channel that is closed on the next change.
+		changed() <-chan struct{}
+	}
+
+	// cacheKey identifies a cached monitor call.
+	cacheKey struct {
+		method string
+		arg    interface{}
+	}
+
+	// cacheLookup is a cached result of a lookup by process id.
+	cacheLookup struct {
+		value interface{}
+		ok    bool
+	}
+
+	// cacheEntry is a cached monitor call result.
+	cacheEntry struct {
+		value   interface{}
+		expires time.Time
+		changed <-chan struct{}
+	}
+
+	// cachingMonitor is a Monitor that caches the results of another monitor.
+	cachingMonitor struct {
+		inner   Monitor
+		ttl     time.Duration
+		mutex   sync.RWMutex
+		entries map[cacheKey]cacheEntry
+	}
+)
+
+// NewCachingMonitor returns a Monitor that serves the results of inner from a
+// cache for ttl. It is meant for many readers that poll the monitor more
+// often than the pool state changes. If inner is the monitor of a pool, the
+// cache is also invalidated on every process or worker status transition.
+// Purge is never cached and clears the cache. The slices returned by the
+// caching monitor are shared between the callers and must not be modified.
+func NewCachingMonitor(inner Monitor, ttl time.Duration) Monitor {
+	return &cachingMonitor{
+		inner:   inner,
+		ttl:     ttl,
+		entries: make(map[cacheKey]cacheEntry),
+	}
+}
+
+// changed returns a channel that is closed on the next change of the pool
+// state.
+func (w *workerPool) changed() <-chan struct{} {
+	return w.changes.wait()
+}
+
+// valid reports whether the entry can still be served.
+func (e cacheEntry) valid(now time.Time) bool {
+	if now.After(e.expires) {
+		return false
+	}
+
+	select {
+	case <-e.changed:
+		return false
+	default:
+		return true
+	}
+}
+
+// get returns the cached result of the call, or loads it from the inner
+// monitor.
+func (c *cachingMonitor) get(key cacheKey, load func() interface{}) interface{} {
+	now := time.Now()
+	c.mutex.RLock()
+	e, ok := c.entries[key]
+	c.mutex.RUnlock()
+	if ok && e.valid(now) {
+		return e.value
+	}
+
+	// Take the change channel before loading, so a change that happens
+	// during the load invalidates the entry.
+	var changed <-chan struct{}
+	if n, ok := c.inner.(changeNotifier); ok {
+		changed = n.changed()
+	}
+	e = cacheEntry{
+		value:   load(),
+		expires: now.Add(c.ttl),
+		changed: changed,
+	}
+
+	c.mutex.Lock()
+	c.entries[key] = e
+	c.mutex.Unlock()
+
+	return e.value
+}
+
+// PoolStatus returns the cached pool status.
+func (c *cachingMonitor) PoolStatus() pool.Status {
+	return c.get(cacheKey{method: "PoolStatus"}, func() interface{} {
+		return c.inner.PoolStatus()
+	}).(pool.Status)
+}
+
+// Error returns the cached process error.
+func (c *cachingMonitor) Error(pid PID) (error, bool) { //nolint:stylecheck
+	l := c.get(cacheKey{method: "Error", arg: pid}, func() interface{} {
+		err, ok := c.inner.Error(pid)
+		return cacheLookup{value: err, ok: ok}
+	}).(cacheLookup)
+	err, _ := l.value.(error)
+
+	return err, l.ok
+}
+
+// Histogram returns the histogram of the inner monitor. It is not cached,
+// because the histogram is updated live.
+func (c *cachingMonitor) Histogram(pid PID) (*DurationHistogram, bool) {
+	return c.inner.Histogram(pid)
+}
+
+// WorkerList returns the cached worker list.
+func (c *cachingMonitor) WorkerList() []WorkerName {
+	return c.get(cacheKey{method: "WorkerList"}, func() interface{} {
+		return c.inner.WorkerList()
+	}).([]WorkerName)
+}
+
+// WorkerStatus returns the cached worker status.
+func (c *cachingMonitor) WorkerStatus(name WorkerName) worker.Status {
+	return c.get(cacheKey{method: "WorkerStatus", arg: name}, func() interface{} {
+		return c.inner.WorkerStatus(name)
+	}).(worker.Status)
+}
+
+// WorkerStats returns the cached worker stats.
+func (c *cachingMonitor) WorkerStats(name WorkerName) (WorkerStats, bool) {
+	l := c.get(cacheKey{method: "WorkerStats", arg: name}, func() interface{} {
+		stats, ok := c.inner.WorkerStats(name)
+		return cacheLookup{value: stats, ok: ok}
+	}).(cacheLookup)
+
+	return l.value.(WorkerStats), l.ok
+}
+
+// ProcessStats returns the cached process stats.
+func (c *cachingMonitor) ProcessStats(pid PID) (ProcessStats, bool) {
+	l := c.get(cacheKey{method: "ProcessStats", arg: pid}, func() interface{} {
+		stats, ok := c.inner.ProcessStats(pid)
+		return cacheLookup{value: stats, ok: ok}
+	}).(cacheLookup)
+
+	return l.value.(ProcessStats), l.ok
+}
+
+// Delta returns the cached delta.
+func (c *cachingMonitor) Delta(since time.Time) MonitorDelta {
+	return c.get(cacheKey{method: "Delta", arg: since}, func() interface{} {
+		return c.inner.Delta(since)
+	}).(MonitorDelta)
+}
+
+// CompletedProcesses returns the cached completed processes.
+func (c *cachingMonitor) CompletedProcesses() []ProcessStats {
+	return c.get(cacheKey{method: "CompletedProcesses"}, func() interface{} {
+		return c.inner.CompletedProcesses()
+	}).([]ProcessStats)
+}
+
+// Purge purges the inner monitor and clears the cache.
+func (c *cachingMonitor) Purge(olderThan time.Time) int {
+	purged := c.inner.Purge(olderThan)
+
+	c.mutex.Lock()
+	c.entries = make(map[cacheKey]cacheEntry)
+	c.mutex.Unlock()
+
+	return purged
+}
+
+// Metrics returns the cached metrics snapshot.
+func (c *cachingMonitor) Metrics() MetricsSnapshot {
+	return c.get(cacheKey{method: "Metrics"}, func() interface{} {
+		return c.inner.Metrics()
+	}).(MetricsSnapshot)
+}
+
+// TotalErrors returns the cached number of failed processes.
+func (c *cachingMonitor) TotalErrors() int {
+	return c.get(cacheKey{method: "TotalErrors"}, func() interface{} {
+		return c.inner.TotalErrors()
+	}).(int)
+}
+
+// ResetStats resets the stats of the inner monitor and clears the cache.
+func (c *cachingMonitor) ResetStats() error {
+	err := c.inner.ResetStats()
+
+	c.mutex.Lock()
+	c.entries = make(map[cacheKey]cacheEntry)
+	c.mutex.Unlock()
+
+	return err
+}
+
+// ActiveWorkerCount returns the cached number of busy workers.
+func (c *cachingMonitor) ActiveWorkerCount() int {
+	return c.get(cacheKey{method: "ActiveWorkerCount"}, func() interface{} {
+		return c.inner.ActiveWorkerCount()
+	}).(int)
+}
+
+// IdleWorkerCount returns the cached number of idle workers.
+func (c *cachingMonitor) IdleWorkerCount() int {
+	return c.get(cacheKey{method: "IdleWorkerCount"}, func() interface{} {
+		return c.inner.IdleWorkerCount()
+	}).(int)
+}
+
+// StartRate returns the cached process start rate.
+func (c *cachingMonitor) StartRate() float64 {
+	return c.get(cacheKey{method: "StartRate"}, func() interface{} {
+		return c.inner.StartRate()
+	}).(float64)
+}
+
+// ErrorCatalog returns the cached unique process errors.
+func (c *cachingMonitor) ErrorCatalog() map[int]error {
+	return c.get(cacheKey{method: "ErrorCatalog"}, func() interface{} {
+		return c.inner.ErrorCatalog()
+	}).(map[int]error)
+}
+
+// DrainAudit returns the cached drain audit.
+func (c *cachingMonitor) DrainAudit() DrainAudit {
+	return c.get(cacheKey{method: "DrainAudit"}, func() interface{} {
+		return c.inner.DrainAudit()
+	}).(DrainAudit)
+}
+
+// GroupStats returns the cached group stats.
+func (c *cachingMonitor) GroupStats(name string) GroupStats {
+	return c.get(cacheKey{method: "GroupStats", arg: name}, func() interface{} {
+		return c.inner.GroupStats(name)
+	}).(GroupStats)
+}
+
+// BroadcastStats returns the cached broadcast stats.
+func (c *cachingMonitor) BroadcastStats(pid PID) GroupStats {
+	return c.get(cacheKey{method: "BroadcastStats", arg: string(pid)}, func() interface{} {
+		return c.inner.BroadcastStats(pid)
+	}).(GroupStats)
+}
+
+// CircuitStatus returns the cached circuit state of the process name.
+func (c *cachingMonitor) CircuitStatus(name string) circuit.Status {
+	return c.get(cacheKey{method: "CircuitStatus", arg: name}, func() interface{} {
+		return c.inner.CircuitStatus(name)
+	}).(circuit.Status)
+}
+
+// GroupStatus returns the rolled-up state of the cached group stats.
+func (c *cachingMonitor) GroupStatus(name string) (process.Status, bool) {
+	return groupStatus(c.GroupStats(name))
+}
+
+// WithFilter returns a caching view of the filtered inner monitor.
+func (c *cachingMonitor) WithFilter(pattern *regexp.Regexp) Monitor {
+	return NewCachingMonitor(c.inner.WithFilter(pattern), c.ttl)
+}
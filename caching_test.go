@@ -0,0 +1,95 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// Caching monitor should serve from the cache until the pool state changes
+func TestNewCachingMonitor(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	m := NewCachingMonitor(wp.Monitor(), time.Hour)
+
+	wp.Register(createProcess(1, 1, 100*time.Millisecond, processFuncWithoutLog)...)
+	time.Sleep(50 * time.Millisecond)
+	a.Equal(process.Running, processStats(t, m, "p-11").Status)
+	a.Len(m.CompletedProcesses(), 0)
+
+	// The status transition invalidates the cache.
+	wp.Wait()
+	a.Equal(process.Succeeded, processStats(t, m, "p-11").Status)
+	a.Len(m.CompletedProcesses(), 1)
+	a.Equal(1, m.Purge(time.Now()))
+	a.Len(m.CompletedProcesses(), 0)
+	_, ok := m.ProcessStats("p-11")
+	a.False(ok)
+	_, ok = m.Error("p-11")
+	a.False(ok)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Caching monitor should expire the entries after the ttl
+func TestNewCachingMonitor_TTL(t *testing.T) {
+	a := assert.New(t)
+	inner := NewPool(WithWorkerCount(1)).Monitor()
+	m := NewCachingMonitor(inner, 20*time.Millisecond).(*cachingMonitor)
+	calls := 0
+	load := func() interface{} {
+		calls++
+		return calls
+	}
+
+	a.Equal(1, m.get(cacheKey{method: "test"}, load))
+	a.Equal(1, m.get(cacheKey{method: "test"}, load))
+	time.Sleep(30 * time.Millisecond)
+	a.Equal(2, m.get(cacheKey{method: "test"}, load))
+}
+
+func benchmarkCompletedProcesses(b *testing.B, cached bool) {
+	wp := NewPool(WithWorkerCount(4))
+	if err := wp.Start(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+	wp.Register(createProcess(1000, 1, 0, processFuncWithoutLog)...)
+	wp.Wait()
+
+	m := wp.Monitor()
+	if cached {
+		m = NewCachingMonitor(m, 10*time.Millisecond)
+	}
+
+	b.SetParallelism(16)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			m.CompletedProcesses()
+		}
+	})
+	b.StopTimer()
+
+	if err := wp.Close(); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkMonitor_CompletedProcesses(b *testing.B) {
+	benchmarkCompletedProcesses(b, false)
+}
+
+func BenchmarkCachingMonitor_CompletedProcesses(b *testing.B) {
+	benchmarkCompletedProcesses(b, true)
+}
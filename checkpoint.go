@@ -0,0 +1,139 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// ErrNoCheckpointStore is returned by Checkpoint and LoadCheckpoint when the
+// context does not belong to a process that is run by a pool.
+var ErrNoCheckpointStore = errors.New("no checkpoint store in the context")
+
+type (
+	// CheckpointStore keeps the intermediate state of the processes, so a
+	// retried process can resume from its last checkpoint.
+	CheckpointStore interface {
+		// Save stores the encoded state of the process under key.
+		Save(pid PID, key string, state []byte) error
+		// Load returns the encoded state of the process under key, and
+		// false if there is none.
+		Load(pid PID, key string) ([]byte, bool, error)
+		// Delete removes all the states of the process.
+		Delete(pid PID) error
+	}
+
+	// MemoryCheckpointStore is a CheckpointStore that keeps the states in
+	// memory. It is the default checkpoint store of the pool.
+	MemoryCheckpointStore struct {
+		mutex  sync.RWMutex
+		states map[PID]map[string][]byte
+	}
+
+	// checkpointScope is the checkpoint store and the process id of a
+	// running process.
+	checkpointScope struct {
+		store CheckpointStore
+		pid   PID
+	}
+
+	// checkpointScopeKey is the context key of the checkpoint scope.
+	checkpointScopeKey struct{}
+)
+
+// NewMemoryCheckpointStore makes a new instance of MemoryCheckpointStore.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{
+		states: make(map[PID]map[string][]byte),
+	}
+}
+
+// Save stores the state of the process under key.
+func (m *MemoryCheckpointStore) Save(pid PID, key string, state []byte) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.states[pid] == nil {
+		m.states[pid] = make(map[string][]byte)
+	}
+	m.states[pid][key] = state
+
+	return nil
+}
+
+// Load returns the state of the process under key.
+func (m *MemoryCheckpointStore) Load(pid PID, key string) ([]byte, bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	state, ok := m.states[pid][key]
+
+	return state, ok, nil
+}
+
+// Delete removes all the states of the process.
+func (m *MemoryCheckpointStore) Delete(pid PID) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.states, pid)
+
+	return nil
+}
+
+// Checkpoint saves the state of the running process under key, so the
+// process can restore it with LoadCheckpoint when it is retried. The state is
+// encoded as JSON. The ctx must be the context that the pool passed to the
+// Start method of the process. The checkpoints of a process are removed once
+// it succeeds.
+func Checkpoint(ctx context.Context, key string, state interface{}) error {
+	scope, ok := ctx.Value(checkpointScopeKey{}).(checkpointScope)
+	if !ok {
+		return ErrNoCheckpointStore
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return scope.store.Save(scope.pid, key, data)
+}
+
+// LoadCheckpoint restores the state that the running process saved under
+// key into dest. It returns false if the process has no checkpoint under key.
+func LoadCheckpoint(ctx context.Context, key string, dest interface{}) (bool, error) {
+	scope, ok := ctx.Value(checkpointScopeKey{}).(checkpointScope)
+	if !ok {
+		return false, ErrNoCheckpointStore
+	}
+
+	data, ok, err := scope.store.Load(scope.pid, key)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	return true, json.Unmarshal(data, dest)
+}
+
+// withCheckpointScope returns the context of the process with its checkpoint
+// scope.
+func (w *workerPool) withCheckpointScope(ctx context.Context, p Process) context.Context {
+	return context.WithValue(ctx, checkpointScopeKey{}, checkpointScope{
+		store: w.config.CheckpointStore,
+		pid:   p.PID(),
+	})
+}
+
+// clearCheckpoints removes the checkpoints of a succeeded process.
+func (w *workerPool) clearCheckpoints(p Process) {
+	if err := w.config.CheckpointStore.Delete(p.PID()); err != nil {
+		w.log(levelWarn, "unable to delete the checkpoints of the process",
+			Field{"name", w.processName(p)}, Field{"pid", p.PID()}, Field{"error", err})
+	}
+}
@@ -0,0 +1,69 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// A retried process should resume from its last checkpoint
+func TestCheckpoint(t *testing.T) {
+	a := assert.New(t)
+	store := NewMemoryCheckpointStore()
+	wp := NewPool(WithWorkerCount(1), WithCheckpointStore(store))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	type progress struct {
+		Step int
+	}
+	resumed := make([]int, 0)
+	steps := func(ctx context.Context, pid PID, d time.Duration) error {
+		state := progress{}
+		if _, err := LoadCheckpoint(ctx, "progress", &state); err != nil {
+			return err
+		}
+		resumed = append(resumed, state.Step)
+
+		for state.Step < 4 {
+			state.Step++
+			if err := Checkpoint(ctx, "progress", state); err != nil {
+				return err
+			}
+			if len(resumed) == 1 && state.Step == 2 {
+				return errors.New("failed after step 2")
+			}
+		}
+		return nil
+	}
+	wp.Register(WithRetry(newTestProcess("steps", 1, 0, steps), 2, FixedBackoff{}))
+	a.NoError(wp.Wait())
+
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-1").Status)
+	a.Equal([]int{0, 2}, resumed)
+	_, ok, err := store.Load("p-1", "progress")
+	a.NoError(err)
+	a.False(ok)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Checkpoints should only be available within a pool process
+func TestCheckpoint_NoStore(t *testing.T) {
+	a := assert.New(t)
+	a.ErrorIs(Checkpoint(context.Background(), "key", 1), ErrNoCheckpointStore)
+	var state int
+	ok, err := LoadCheckpoint(context.Background(), "key", &state)
+	a.False(ok)
+	a.ErrorIs(err, ErrNoCheckpointStore)
+}
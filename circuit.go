@@ -0,0 +1,172 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/circuit"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// ErrCircuitOpen is the error of a process that fails without running because
+// the circuit of its name is open.
+var ErrCircuitOpen = errors.New("circuit is open")
+
+type (
+	// circuitBreaker tracks the consecutive failures of the processes of
+	// each name and opens the circuit of a name that fails too often.
+	circuitBreaker struct {
+		mutex      sync.Mutex
+		threshold  int
+		resetAfter time.Duration
+		circuits   map[string]*circuitState
+	}
+
+	// circuitState is the state of the circuit of one process name.
+	circuitState struct {
+		status   circuit.Status
+		failures int
+		openedAt time.Time
+	}
+)
+
+// newCircuitBreaker makes a new instance of circuitBreaker. A threshold of
+// zero disables it.
+func newCircuitBreaker(threshold int, resetAfter time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold:  threshold,
+		resetAfter: resetAfter,
+		circuits:   make(map[string]*circuitState),
+	}
+}
+
+// allow reports whether a process of the name may run. Once the reset period
+// of an open circuit is over, it lets one process through as a probe and
+// moves the circuit to HalfOpen until the probe is finished.
+func (b *circuitBreaker) allow(name string) (ok, probe bool) {
+	if b.threshold <= 0 {
+		return true, false
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	state, found := b.circuits[name]
+	if !found {
+		return true, false
+	}
+
+	switch state.status {
+	case circuit.Closed:
+		return true, false
+	case circuit.Open:
+		if time.Since(state.openedAt) < b.resetAfter {
+			return false, false
+		}
+		state.status = circuit.HalfOpen
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// record counts the outcome of an attempt of a process of the name, and
+// reports whether it has opened the circuit. A failure of the probe opens
+// the circuit for another reset period, and its success closes the circuit.
+// The attempts that are neither succeeded nor failed leave the circuit
+// unchanged, and a probe that ends this way lets the next process probe.
+func (b *circuitBreaker) record(name string, probe bool, status process.Status) bool {
+	if b.threshold <= 0 {
+		return false
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	state, found := b.circuits[name]
+	if !found {
+		state = new(circuitState)
+		b.circuits[name] = state
+	}
+
+	failed := status == process.Failed || status == process.Retrying
+	switch {
+	case probe && failed:
+		state.status = circuit.Open
+		state.openedAt = time.Now()
+		return true
+	case probe && status == process.Succeeded:
+		state.status = circuit.Closed
+		state.failures = 0
+	case probe:
+		state.status = circuit.Open
+	case state.status != circuit.Closed:
+		// The processes that have started before the circuit opened do not
+		// change it.
+	case failed:
+		state.failures++
+		if state.failures >= b.threshold {
+			state.status = circuit.Open
+			state.openedAt = time.Now()
+			return true
+		}
+	case status == process.Succeeded:
+		state.failures = 0
+	}
+
+	return false
+}
+
+// status returns the state of the circuit of the name.
+func (b *circuitBreaker) status(name string) circuit.Status {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if state, ok := b.circuits[name]; ok {
+		return state.status
+	}
+
+	return circuit.Closed
+}
+
+// CircuitStatus returns the state of the circuit breaker of the process name.
+// It is Closed if the pool has no circuit breaker. The circuits are keyed by
+// the names sanitized by the NameSanitizer of the pool, so the names that
+// are sanitized the same share a circuit.
+func (w *workerPool) CircuitStatus(name string) circuit.Status {
+	return w.circuits.status(w.sanitize(name))
+}
+
+// openCircuit fails the waiting processes of the sanitized name, whose
+// circuit has just opened.
+func (w *workerPool) openCircuit(name string) {
+	w.log(levelWarn, "circuit has been opened", Field{"name", name})
+
+	pids := make([]PID, 0)
+	w.processes.each(func(pid PID, stats ProcessStats) {
+		if stats.Status == process.Waiting && stats.Process != nil && w.processName(stats.Process) == name {
+			pids = append(pids, pid)
+		}
+	})
+
+	for _, pid := range pids {
+		if p, ok := w.queue.remove(pid); ok {
+			w.rejectCircuit(p)
+		}
+	}
+}
+
+// rejectCircuit fails the process whose circuit is open without running it.
+func (w *workerPool) rejectCircuit(p Process) {
+	stats := w.processes.get(p.PID())
+	stats.err = fmt.Errorf("%w: %s", ErrCircuitOpen, p.Name())
+	stats.Status = process.Failed
+	w.controlPanel.get(p.PID()).cancel()
+	w.abandon(p, stats)
+}
@@ -0,0 +1,138 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/circuit"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// The circuit of a name should open after consecutive failures and fail its
+// waiting processes
+func TestWithCircuitBreaker(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithCircuitBreaker(2, time.Minute))
+	wp.Register(
+		newTestProcess("flaky", 1, 0, processFuncWithError),
+		newTestProcess("flaky", 2, 0, processFuncWithError),
+		newTestProcess("flaky", 3, 10*time.Millisecond, processFuncWithoutLog),
+		newTestProcess("steady", 4, 10*time.Millisecond, processFuncWithoutLog),
+	)
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	_ = wp.Wait()
+
+	a.Equal(circuit.Open, wp.Monitor().CircuitStatus("flaky"))
+	a.Equal(circuit.Closed, wp.Monitor().CircuitStatus("steady"))
+	stats := processStats(t, wp.Monitor(), "p-3")
+	a.Equal(process.Failed, stats.Status)
+	a.True(stats.StartedAt.IsZero())
+	a.ErrorIs(processError(t, wp.Monitor(), "p-3"), ErrCircuitOpen)
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-4").Status)
+
+	wp.Register(newTestProcess("flaky", 5, 0, processFuncWithoutLog))
+	_ = wp.Wait()
+	a.ErrorIs(processError(t, wp.Monitor(), "p-5"), ErrCircuitOpen)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// The circuits should be keyed by the sanitized process names
+func TestWithCircuitBreaker_SanitizedName(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithCircuitBreaker(2, time.Minute))
+	wp.Register(
+		newTestProcess("my service/sync", 1, 0, processFuncWithError),
+		newTestProcess("my.service sync", 2, 0, processFuncWithError),
+		newTestProcess("my_service_sync", 3, 0, processFuncWithoutLog),
+	)
+	a.NoError(wp.Start(context.Background()))
+	_ = wp.Wait()
+
+	a.Equal(circuit.Open, wp.Monitor().CircuitStatus("my_service_sync"))
+	a.Equal(circuit.Open, wp.Monitor().CircuitStatus("my service/sync"))
+	a.ErrorIs(processError(t, wp.Monitor(), "p-3"), ErrCircuitOpen)
+
+	a.NoError(wp.Close())
+}
+
+// A probe should close the circuit if it succeeds and keep it open if it fails
+func TestWithCircuitBreaker_Probe(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithCircuitBreaker(1, 30*time.Millisecond))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	wp.Register(newTestProcess("flaky", 1, 0, processFuncWithError))
+	_ = wp.Wait()
+	a.Equal(circuit.Open, wp.Monitor().CircuitStatus("flaky"))
+
+	time.Sleep(40 * time.Millisecond)
+	wp.Register(newTestProcess("flaky", 2, 0, processFuncWithError))
+	_ = wp.Wait()
+	a.NotErrorIs(processError(t, wp.Monitor(), "p-2"), ErrCircuitOpen)
+	a.Equal(circuit.Open, wp.Monitor().CircuitStatus("flaky"))
+
+	wp.Register(newTestProcess("flaky", 3, 0, processFuncWithoutLog))
+	_ = wp.Wait()
+	a.ErrorIs(processError(t, wp.Monitor(), "p-3"), ErrCircuitOpen)
+
+	time.Sleep(40 * time.Millisecond)
+	wp.Register(newTestProcess("flaky", 4, 0, processFuncWithoutLog))
+	_ = wp.Wait()
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-4").Status)
+	a.Equal(circuit.Closed, wp.Monitor().CircuitStatus("flaky"))
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Check the transitions of the circuit of a name
+func TestCircuitBreaker(t *testing.T) {
+	a := assert.New(t)
+	b := newCircuitBreaker(2, 0)
+
+	ok, probe := b.allow("job")
+	a.True(ok)
+	a.False(probe)
+	a.False(b.record("job", false, process.Failed))
+	a.False(b.record("job", false, process.Succeeded))
+	a.False(b.record("job", false, process.Retrying))
+	a.True(b.record("job", false, process.Failed))
+	a.Equal(circuit.Open, b.status("job"))
+
+	// A process that has started before the circuit opened does not close
+	// it.
+	a.False(b.record("job", false, process.Succeeded))
+	a.Equal(circuit.Open, b.status("job"))
+
+	ok, probe = b.allow("job")
+	a.True(ok)
+	a.True(probe)
+	a.Equal(circuit.HalfOpen, b.status("job"))
+	ok, _ = b.allow("job")
+	a.False(ok)
+
+	// A killed probe lets the next process probe.
+	a.False(b.record("job", true, process.Killed))
+	ok, probe = b.allow("job")
+	a.True(ok)
+	a.True(probe)
+	a.False(b.record("job", true, process.Succeeded))
+	a.Equal(circuit.Closed, b.status("job"))
+
+	disabled := newCircuitBreaker(0, 0)
+	a.False(disabled.record("job", false, process.Failed))
+	ok, _ = disabled.allow("job")
+	a.True(ok)
+	a.Equal(circuit.Closed, disabled.status("job"))
+}
@@ -0,0 +1,80 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// completionProcess wraps a process to call a hook once it is finished.
+type completionProcess struct {
+	Process
+	hook func(pid PID, status process.Status, err error)
+}
+
+// WithOnComplete wraps the process to call fn once the process reaches a
+// final state, with that state and the process error, which is the error
+// that the process returned when it has been killed while running, or
+// context.Canceled when it has been killed before it started. fn runs
+// in its own goroutine, so it never blocks the worker, and a panic in fn is
+// recovered and logged. WithOnComplete can be applied more than once, the
+// hooks are called in order from the outermost one.
+func WithOnComplete(p Process, fn func(pid PID, status process.Status, err error)) Process {
+	return completionProcess{Process: p, hook: fn}
+}
+
+// unwrap returns the wrapped process.
+func (c completionProcess) unwrap() Process {
+	return c.Process
+}
+
+// completionLayers returns the layers of the process that are set by
+// WithOnComplete.
+func completionLayers(p Process) []completionProcess {
+	var layers []completionProcess
+	findLayer(p, func(l Process) bool {
+		if cp, ok := l.(completionProcess); ok {
+			layers = append(layers, cp)
+		}
+		return false
+	})
+
+	return layers
+}
+
+// complete calls the completion hooks of the finished process in a new
+// goroutine.
+func (w *workerPool) complete(p Process, stats ProcessStats) {
+	layers := completionLayers(p)
+	if len(layers) == 0 {
+		return
+	}
+
+	go func() {
+		for _, l := range layers {
+			w.callHook(l, stats)
+		}
+	}()
+}
+
+// callHook calls the completion hook of the layer and logs its panic. The
+// hook gets the process id as seen by the layer, so a process that has been
+// registered through a namespace gets its original id.
+func (w *workerPool) callHook(l completionProcess, stats ProcessStats) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.log(levelError, "process completion hook has panicked",
+				Field{"name", w.processName(stats.Process)}, Field{"pid", stats.Process.PID()}, Field{"panic", r})
+		}
+	}()
+
+	err := stats.err
+	if err == nil && stats.Status == process.Killed {
+		err = context.Canceled
+	}
+	l.hook(l.PID(), stats.Status, err)
+}
@@ -0,0 +1,104 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/stretchr/testify/assert"
+)
+
+type completion struct {
+	pid    PID
+	status process.Status
+	err    error
+}
+
+// recordCompletion returns a hook that sends the completions to the channel.
+func recordCompletion(ch chan<- completion) func(PID, process.Status, error) {
+	return func(pid PID, status process.Status, err error) {
+		ch <- completion{pid: pid, status: status, err: err}
+	}
+}
+
+// The hooks should receive the final status and error of each process
+func TestWithOnComplete(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Start(context.Background()))
+
+	completions := make(chan completion, 3)
+	hook := recordCompletion(completions)
+	a.NoError(wp.Register(
+		WithOnComplete(newTestProcess("ok", 1, 0, processFuncWithoutLog), hook),
+		WithOnComplete(newTestProcess("failing", 2, 0, processFuncWithError), hook),
+		WithOnComplete(newTestProcess("killed", 3, time.Minute, processFuncWithoutLog), hook),
+	))
+	_, err := wp.WaitUntilStatus(context.Background(), "p-3", process.Running)
+	a.NoError(err)
+	a.NoError(wp.KillWait("p-3", time.Second))
+	a.Error(wp.Wait())
+
+	got := make(map[PID]completion)
+	for i := 0; i < 3; i++ {
+		select {
+		case c := <-completions:
+			got[c.pid] = c
+		case <-time.After(time.Second):
+			a.FailNow("completion hook is not called")
+		}
+	}
+	a.Equal(process.Succeeded, got["p-1"].status)
+	a.NoError(got["p-1"].err)
+	a.Equal(process.Failed, got["p-2"].status)
+	a.EqualError(got["p-2"].err, "unable to start processFunc with id: p-2")
+	a.Equal(process.Killed, got["p-3"].status)
+	a.Error(got["p-3"].err)
+
+	a.NoError(wp.Pause())
+	a.NoError(wp.Register(WithOnComplete(newTestProcess("queued", 4, 0, processFuncWithoutLog), hook)))
+	wp.Kill("p-4")
+	select {
+	case c := <-completions:
+		a.Equal(process.Killed, c.status)
+		a.ErrorIs(c.err, context.Canceled)
+	case <-time.After(time.Second):
+		a.Fail("completion hook is not called")
+	}
+
+	a.NoError(wp.Close())
+}
+
+// The hooks should be composable and a panic should not stop the next hook
+func TestWithOnComplete_Composed(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+
+	completions := make(chan completion, 2)
+	p := newTestProcess("ok", 1, 0, processFuncWithoutLog)
+	p = WithOnComplete(p, recordCompletion(completions))
+	p = WithOnComplete(p, func(PID, process.Status, error) {
+		panic("hook failure")
+	})
+	p = WithOnComplete(p, recordCompletion(completions))
+	a.NoError(wp.Namespace("tenant").Register(p))
+	a.NoError(wp.Wait())
+
+	for i := 0; i < 2; i++ {
+		select {
+		case c := <-completions:
+			a.Equal(PID("p-1"), c.pid)
+			a.Equal(process.Succeeded, c.status)
+		case <-time.After(time.Second):
+			a.FailNow("completion hook is not called")
+		}
+	}
+
+	a.NoError(wp.Close())
+}
@@ -0,0 +1,75 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import "sync"
+
+// errorCatalog keeps one error per unique error message.
+type errorCatalog struct {
+	mutex   sync.RWMutex
+	indexes map[string]int
+	errors  map[int]error
+}
+
+// newErrorCatalog makes a new instance of errorCatalog.
+func newErrorCatalog() *errorCatalog {
+	return &errorCatalog{
+		indexes: make(map[string]int),
+		errors:  make(map[int]error),
+	}
+}
+
+// add returns the catalog key and the stored error for the message of err.
+// The first error with a message is stored and returned for all the errors
+// with the same message.
+func (c *errorCatalog) add(err error) (int, error) {
+	msg := err.Error()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if i, ok := c.indexes[msg]; ok {
+		return i, c.errors[i]
+	}
+
+	i := len(c.errors) + 1
+	c.indexes[msg] = i
+	c.errors[i] = err
+
+	return i, err
+}
+
+// copy returns a copy of the catalog errors.
+func (c *errorCatalog) copy() map[int]error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	errs := make(map[int]error, len(c.errors))
+	for i, err := range c.errors {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// dedupError replaces the process error with the catalog error that has the
+// same message, if error deduplication is enabled.
+func (w *workerPool) dedupError(stats *ProcessStats) {
+	if w.errors == nil || stats.err == nil {
+		return
+	}
+
+	stats.ErrorIndex, stats.err = w.errors.add(stats.err)
+}
+
+// ErrorCatalog returns the unique process errors by their ErrorIndex. It is
+// empty if the pool has no error deduplication.
+func (w *workerPool) ErrorCatalog() map[int]error {
+	if w.errors == nil {
+		return make(map[int]error)
+	}
+
+	return w.errors.copy()
+}
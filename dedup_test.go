@@ -0,0 +1,47 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// Processes with the same error message should share one catalog entry
+func TestWithErrorDeduplication(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(10), WithErrorDeduplication())
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	refused := func(ctx context.Context, pid PID, duration time.Duration) error {
+		return errors.New("database connection refused")
+	}
+	for i := 0; i < 10; i++ {
+		wp.Register(createProcess(10, i, 0, refused)...)
+	}
+	wp.Wait()
+
+	catalog := wp.Monitor().ErrorCatalog()
+	a.Len(catalog, 1)
+	for i := 1; i <= 100; i++ {
+		pid := PID("p-" + strconv.Itoa(i))
+		stats := processStats(t, wp.Monitor(), pid)
+		a.Equal(process.Failed, stats.Status)
+		a.Equal(1, stats.ErrorIndex)
+		a.EqualError(processError(t, wp.Monitor(), pid), "database connection refused")
+		a.Same(catalog[1], processError(t, wp.Monitor(), pid))
+	}
+
+	err = wp.Close()
+	a.NoError(err)
+}
@@ -0,0 +1,232 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+var (
+	// ErrDependencyFailed is the error of a process whose dependency has
+	// failed or has been killed.
+	ErrDependencyFailed = errors.New("dependency has not succeeded")
+
+	// ErrDependencyCycle is returned by Register when the dependencies of
+	// the processes form a cycle.
+	ErrDependencyCycle = errors.New("dependency cycle")
+
+	// ErrDependencyTimeout is the error of a process whose dependencies have
+	// not succeeded within the timeout set by WithDependencyTimeout.
+	ErrDependencyTimeout = errors.New("dependency timeout")
+)
+
+// dependentProcess wraps a process to run it after its dependencies.
+type dependentProcess struct {
+	Process
+	pids []PID
+}
+
+// WithDependsOn wraps the process to queue it only after all the processes
+// with the given ids have succeeded. Until then, the process is Pending. If a
+// dependency fails or is killed, the process fails right away with
+// ErrDependencyFailed. The dependencies must be registered before the
+// process or in the same Register call, and Register returns
+// ErrDependencyCycle if they form a cycle.
+func WithDependsOn(p Process, pids ...PID) Process {
+	return dependentProcess{Process: p, pids: append([]PID(nil), pids...)}
+}
+
+// unwrap returns the wrapped process.
+func (d dependentProcess) unwrap() Process {
+	return d.Process
+}
+
+// dependencies returns the dependencies of the process that are set by
+// WithDependsOn, and its predecessor in a sequential group.
+func dependencies(p Process) []PID {
+	var pids []PID
+	findLayer(p, func(l Process) bool {
+		switch l := l.(type) {
+		case dependentProcess:
+			pids = append(pids, l.pids...)
+		case sequentialProcess:
+			pids = append(pids, l.prev)
+		}
+		return false
+	})
+
+	return pids
+}
+
+// checkDependencies returns ErrProcessNotFound if a dependency of the
+// processes is neither registered nor in args, and ErrDependencyCycle if
+// the dependencies of args form a cycle. The registered processes cannot
+// depend on args, so a cycle can only be made within args.
+func (w *workerPool) checkDependencies(args []Process) error {
+	batch := make(map[PID][]PID, len(args))
+	for _, p := range args {
+		batch[p.PID()] = dependencies(p)
+	}
+
+	for _, p := range args {
+		for _, dep := range batch[p.PID()] {
+			if _, ok := batch[dep]; !ok && w.controlPanel.get(dep) == nil {
+				return w.pidError("register the process", ErrProcessNotFound, dep)
+			}
+		}
+	}
+
+	// Depth-first search of the batch, a process that is visited again
+	// before its visit is finished closes a cycle.
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[PID]int, len(batch))
+	var visit func(pid PID) error
+	visit = func(pid PID) error {
+		switch state[pid] {
+		case visiting:
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, pid)
+		case visited:
+			return nil
+		}
+
+		state[pid] = visiting
+		for _, dep := range batch[pid] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[pid] = visited
+
+		return nil
+	}
+	for _, p := range args {
+		if err := visit(p.PID()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// awaitDependencies queues the Pending process once all its dependencies
+// have succeeded, or fails it as soon as one of them has not. Its
+// predecessor in a sequential group only has to finish. The process is
+// Killed if it is killed meanwhile, it is Cancelled if the pool or its
+// sub-pool is closed, and it fails with ErrDependencyTimeout if the
+// dependency timeout of the pool expires first.
+func (w *workerPool) awaitDependencies(p Process) {
+	stats := w.processes.get(p.PID())
+	pc := w.controlPanel.get(p.PID())
+	prev, sequential := predecessor(p)
+
+	// The sub-pool may be closed before the channel is taken.
+	done := w.closeSignal()
+	closing := w.subPools.closing()
+	if w.subPools.check([]Process{p}) != nil {
+		w.cancel(p)
+		return
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	finished := make(chan PID, len(stats.DependsOn))
+	for _, dep := range stats.DependsOn {
+		dpc := w.controlPanel.get(dep)
+		if dpc == nil {
+			// The dependency has been purged, its stats are gone too.
+			finished <- dep
+			continue
+		}
+		go func(dep PID, done <-chan struct{}) {
+			select {
+			case <-done:
+				finished <- dep
+			case <-stop:
+			}
+		}(dep, dpc.done)
+	}
+
+	var expired <-chan time.Time
+	if timeout := w.config.DependencyTimeout; timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for len(stats.BlockedBy) > 0 {
+		select {
+		case dep := <-finished:
+			status := w.processes.get(dep).Status
+			if status != process.Succeeded && !(sequential && dep == prev) {
+				stats.err = fmt.Errorf("%w: %s is %s", ErrDependencyFailed, dep, status)
+				stats.Status = process.Failed
+				pc.cancel()
+				w.abandon(p, stats)
+				return
+			}
+			stats.BlockedBy = removePID(stats.BlockedBy, dep)
+			stats.updatedAt = time.Now()
+			w.processes.put(p.PID(), stats)
+			w.notify()
+		case <-expired:
+			stats.err = fmt.Errorf("%w: blocked by %s",
+				ErrDependencyTimeout, stats.BlockedBy)
+			stats.Status = process.Failed
+			pc.cancel()
+			w.abandon(p, stats)
+			return
+		case <-pc.ctx.Done():
+			stats.Status = process.Killed
+			w.abandon(p, stats)
+			return
+		case <-closing:
+			if w.subPools.check([]Process{p}) != nil {
+				w.cancel(p)
+				return
+			}
+			closing = w.subPools.closing()
+		case <-done:
+			w.cancel(p)
+			return
+		}
+	}
+
+	stats.Status = process.Waiting
+	stats.EnqueuedAt = time.Now()
+	stats.updatedAt = stats.EnqueuedAt
+	w.processes.put(p.PID(), stats)
+	if !w.publish(p) {
+		w.cancel(p)
+		return
+	}
+	w.notify()
+}
+
+// abandon finishes a process that leaves the pool without being run.
+func (w *workerPool) abandon(p Process, stats ProcessStats) {
+	w.counters.dequeue(processWeight(p))
+	stats.FinishedAt = time.Now()
+	stats.updatedAt = stats.FinishedAt
+	w.finish(p, stats)
+}
+
+// removePID returns the list without pid.
+func removePID(list []PID, pid PID) []PID {
+	kept := make([]PID, 0, len(list))
+	for _, item := range list {
+		if item != pid {
+			kept = append(kept, item)
+		}
+	}
+
+	return kept
+}
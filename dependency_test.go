@@ -0,0 +1,133 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// A dependent process should be queued once its dependencies succeeded
+func TestWithDependsOn(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(3))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	err = wp.Register(
+		WithDependsOn(newTestProcess("report", 3, 0, processFuncWithoutLog), "p-1", "p-2"),
+		newTestProcess("extract", 1, 50*time.Millisecond, processFuncWithoutLog),
+		newTestProcess("extract", 2, 100*time.Millisecond, processFuncWithoutLog),
+	)
+	a.NoError(err)
+
+	time.Sleep(75 * time.Millisecond)
+	stats := processStats(t, wp.Monitor(), "p-3")
+	a.Equal(process.Pending, stats.Status)
+	a.Equal([]PID{"p-1", "p-2"}, stats.DependsOn)
+	a.Equal([]PID{"p-2"}, stats.BlockedBy)
+
+	a.NoError(wp.Wait())
+	stats = processStats(t, wp.Monitor(), "p-3")
+	a.Equal(process.Succeeded, stats.Status)
+	a.Empty(stats.BlockedBy)
+	a.False(stats.StartedAt.Before(processStats(t, wp.Monitor(), "p-2").FinishedAt))
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// A failed or killed dependency should fail the dependent processes at once
+func TestWithDependsOn_Failure(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	err = wp.Register(
+		newTestProcess("fail", 1, 10*time.Millisecond, processFuncWithError),
+		newTestProcess("slow", 2, time.Second, processFuncWithoutLog),
+		WithDependsOn(newTestProcess("load", 3, 0, processFuncWithoutLog), "p-2", "p-1"),
+		WithDependsOn(newTestProcess("notify", 4, 0, processFuncWithoutLog), "p-3"),
+	)
+	a.NoError(err)
+
+	status, err := wp.WaitUntilStatus(context.Background(), "p-4", process.Failed)
+	a.NoError(err)
+	a.Equal(process.Failed, status)
+	a.Equal(process.Running, processStats(t, wp.Monitor(), "p-2").Status)
+	a.ErrorIs(processError(t, wp.Monitor(), "p-3"), ErrDependencyFailed)
+	a.ErrorIs(processError(t, wp.Monitor(), "p-4"), ErrDependencyFailed)
+	a.Zero(processStats(t, wp.Monitor(), "p-3").StartedAt)
+
+	err = wp.Register(WithDependsOn(newTestProcess("cleanup", 5, 0, processFuncWithoutLog), "p-2"))
+	a.NoError(err)
+	wp.Kill("p-5")
+	status, err = wp.WaitUntilStatus(context.Background(), "p-5", process.Killed)
+	a.NoError(err)
+	a.Equal(process.Killed, status)
+
+	wp.Kill("p-2")
+	a.Error(wp.Wait())
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// A dependency that hangs should fail its dependents with
+// ErrDependencyTimeout once the timeout expires
+func TestWithDependencyTimeout(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2), WithDependencyTimeout(50*time.Millisecond))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	registered := time.Now()
+	err = wp.Register(
+		newTestProcess("hang", 1, time.Minute, processFuncWithoutLog),
+		newTestProcess("fast", 2, 0, processFuncWithoutLog),
+		WithDependsOn(newTestProcess("load", 3, 0, processFuncWithoutLog), "p-1"),
+		WithDependsOn(newTestProcess("notify", 4, 0, processFuncWithoutLog), "p-1", "p-2"),
+	)
+	a.NoError(err)
+
+	for _, pid := range []PID{"p-3", "p-4"} {
+		status, err := wp.WaitUntilStatus(context.Background(), pid, process.Failed)
+		a.NoError(err)
+		a.Equal(process.Failed, status)
+		a.ErrorIs(processError(t, wp.Monitor(), pid), ErrDependencyTimeout)
+		stats := processStats(t, wp.Monitor(), pid)
+		a.Equal([]PID{"p-1"}, stats.BlockedBy)
+		a.Less(stats.FinishedAt.Sub(registered), time.Second)
+	}
+	a.Equal(process.Running, processStats(t, wp.Monitor(), "p-1").Status)
+
+	a.NoError(wp.Kill("p-1"))
+	a.Error(wp.Wait())
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Register should reject unknown dependencies and dependency cycles
+func TestWithDependsOn_Check(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+
+	err := wp.Register(WithDependsOn(newTestProcess("a", 1, 0, processFuncWithoutLog), "p-9"))
+	a.ErrorIs(err, ErrProcessNotFound)
+	err = wp.Register(WithDependsOn(newTestProcess("a", 1, 0, processFuncWithoutLog), "p-1"))
+	a.ErrorIs(err, ErrDependencyCycle)
+	err = wp.Register(
+		WithDependsOn(newTestProcess("a", 1, 0, processFuncWithoutLog), "p-3"),
+		WithDependsOn(newTestProcess("b", 2, 0, processFuncWithoutLog), "p-1"),
+		WithDependsOn(newTestProcess("c", 3, 0, processFuncWithoutLog), "p-2"),
+	)
+	a.ErrorIs(err, ErrDependencyCycle)
+	a.Zero(wp.Stats().TotalRegistered)
+}
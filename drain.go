@@ -0,0 +1,91 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"sort"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// DrainAudit records the processes that were not finished when the pool
+// started to close. It is the post-mortem of a shutdown.
+type DrainAudit struct {
+	// InFlightAtClose is the list of processes that were running when the
+	// pool started to close.
+	InFlightAtClose []PID
+
+	// WaitingAtClose is the list of processes that were waiting when the
+	// pool started to close.
+	WaitingAtClose []PID
+
+	// CompletedDuringDrain is the list of the processes of InFlightAtClose
+	// and WaitingAtClose that reached a final state before the pool was
+	// closed.
+	CompletedDuringDrain []PID
+}
+
+// beginDrainAudit records the running and waiting processes. It is a no-op if
+// the audit has already begun. The caller must hold the pool mutex.
+func (w *workerPool) beginDrainAudit() {
+	if w.audit != nil {
+		return
+	}
+
+	audit := &DrainAudit{
+		InFlightAtClose:      make([]PID, 0),
+		WaitingAtClose:       make([]PID, 0),
+		CompletedDuringDrain: make([]PID, 0),
+	}
+	w.processes.each(func(pid PID, stats ProcessStats) {
+		switch stats.Status {
+		case process.Running:
+			audit.InFlightAtClose = append(audit.InFlightAtClose, pid)
+		case process.Waiting, process.Retrying, process.Pending:
+			audit.WaitingAtClose = append(audit.WaitingAtClose, pid)
+		}
+	})
+	sortPIDs(audit.InFlightAtClose)
+	sortPIDs(audit.WaitingAtClose)
+	w.audit = audit
+}
+
+// endDrainAudit records the audited processes that have been finished. The
+// caller must hold the pool mutex.
+func (w *workerPool) endDrainAudit() {
+	for _, list := range [][]PID{w.audit.InFlightAtClose, w.audit.WaitingAtClose} {
+		for _, pid := range list {
+			if w.processes.get(pid).Status.IsTerminal() {
+				w.audit.CompletedDuringDrain = append(w.audit.CompletedDuringDrain, pid)
+			}
+		}
+	}
+	sortPIDs(w.audit.CompletedDuringDrain)
+}
+
+// DrainAudit returns the processes that were not finished when Close or
+// CloseGraceful has been called. It is empty if the pool has not been
+// closed.
+func (w *workerPool) DrainAudit() DrainAudit {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.audit == nil {
+		return DrainAudit{}
+	}
+
+	return DrainAudit{
+		InFlightAtClose:      append([]PID(nil), w.audit.InFlightAtClose...),
+		WaitingAtClose:       append([]PID(nil), w.audit.WaitingAtClose...),
+		CompletedDuringDrain: append([]PID(nil), w.audit.CompletedDuringDrain...),
+	}
+}
+
+// sortPIDs sorts the list of process ids.
+func sortPIDs(pids []PID) {
+	sort.Slice(pids, func(i, j int) bool {
+		return pids[i] < pids[j]
+	})
+}
@@ -0,0 +1,50 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Drain audit should record the running and waiting processes at close
+func TestWorkerPool_DrainAudit(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(3))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	a.Equal(DrainAudit{}, wp.Monitor().DrainAudit())
+
+	wp.Register(createProcess(8, 1, 100*time.Millisecond, processFuncWithoutLog)...)
+	time.Sleep(50 * time.Millisecond)
+	err = wp.CloseGraceful()
+	a.NoError(err)
+
+	audit := wp.Monitor().DrainAudit()
+	a.Equal([]PID{"p-11", "p-12", "p-13"}, audit.InFlightAtClose)
+	a.Equal([]PID{"p-14", "p-15", "p-16", "p-17", "p-18"}, audit.WaitingAtClose)
+	a.Len(audit.CompletedDuringDrain, 8)
+}
+
+// Waiting processes are not completed by Close
+func TestWorkerPool_DrainAuditClose(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	wp.Register(createProcess(3, 1, 100*time.Millisecond, processFuncWithoutLog)...)
+	time.Sleep(50 * time.Millisecond)
+	err = wp.Close()
+	a.NoError(err)
+
+	audit := wp.Monitor().DrainAudit()
+	a.Equal([]PID{"p-11"}, audit.InFlightAtClose)
+	a.Equal([]PID{"p-12", "p-13"}, audit.WaitingAtClose)
+	a.Equal([]PID{"p-11"}, audit.CompletedDuringDrain)
+}
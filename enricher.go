@@ -0,0 +1,36 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import "context"
+
+type (
+	// ContextEnricher adds values to the context of a process before it
+	// starts. The enrichers of a pool are chained, so each enricher receives
+	// the context that is returned by the previous one.
+	ContextEnricher interface {
+		// Enrich returns the context that is passed to the Start method of
+		// the process.
+		Enrich(ctx context.Context, p Process) context.Context
+	}
+
+	// ContextEnricherFunc is an adapter to use an ordinary function as a
+	// ContextEnricher.
+	ContextEnricherFunc func(ctx context.Context, p Process) context.Context
+)
+
+// Enrich calls f(ctx, p).
+func (f ContextEnricherFunc) Enrich(ctx context.Context, p Process) context.Context {
+	return f(ctx, p)
+}
+
+// enrich applies the context enrichers of the pool in order.
+func (w *workerPool) enrich(ctx context.Context, p Process) context.Context {
+	for _, e := range w.config.ContextEnrichers {
+		ctx = e.Enrich(ctx, p)
+	}
+
+	return ctx
+}
@@ -0,0 +1,53 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+type enricherKey string
+
+// The process should receive the values of all the chained enrichers
+func TestWithContextEnrichers(t *testing.T) {
+	a := assert.New(t)
+	enricher := func(key enricherKey) ContextEnricher {
+		return ContextEnricherFunc(func(ctx context.Context, p Process) context.Context {
+			return context.WithValue(ctx, key, string(key)+"-"+p.PID().String())
+		})
+	}
+	// The last enricher sees the values of the previous ones.
+	chained := ContextEnricherFunc(func(ctx context.Context, p Process) context.Context {
+		return context.WithValue(ctx, enricherKey("c"), ctx.Value(enricherKey("a")))
+	})
+	wp := NewPool(WithWorkerCount(1), WithContextEnrichers(enricher("a"), enricher("b")), WithContextEnrichers(chained))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	values := make([]interface{}, 0)
+	wp.Register(newTestProcess("enriched", 1, 0, func(ctx context.Context, pid PID, d time.Duration) error {
+		for _, key := range []enricherKey{"a", "b", "c"} {
+			if ctx.Value(key) == nil {
+				return errors.New("missing context value " + string(key))
+			}
+			values = append(values, ctx.Value(key))
+		}
+		return nil
+	}))
+	a.NoError(wp.Wait())
+
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-1").Status)
+	a.Equal([]interface{}{"a-p-1", "b-p-1", "a-p-1"}, values)
+
+	err = wp.Close()
+	a.NoError(err)
+}
@@ -0,0 +1,56 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+)
+
+// ErrMaxErrorsReached is returned by Register and AwaitIdle when the pool
+// has stopped dequeuing processes because too many of them failed.
+var ErrMaxErrorsReached = errors.New("maximum number of process errors reached")
+
+// TotalErrors returns the number of processes that have failed. The count is
+// pool-wide, the same for the filtered monitors, and it is zeroed by
+// ResetStats. A namespaced monitor counts the failed processes of its
+// namespace instead.
+func (w *workerPool) TotalErrors() int {
+	return int(atomic.LoadInt64(&w.counters.failed))
+}
+
+// checkErrors stops the pool from dequeuing processes if the number of
+// failed processes has reached the maximum. The running processes are not
+// interrupted, so with more than one worker a few more processes may fail
+// before the pool is idle.
+func (w *workerPool) checkErrors() {
+	if w.config.MaxErrors <= 0 || w.TotalErrors() < w.config.MaxErrors {
+		return
+	}
+
+	w.statusMutex.Lock()
+	errored := w.status == pool.Running || w.status == pool.Paused
+	if errored {
+		w.transition(pool.Errored)
+	}
+	w.statusMutex.Unlock()
+
+	if errored {
+		w.queue.halt()
+		w.log(levelError, "pool has stopped dequeuing processes, too many processes have failed",
+			Field{"errors", w.TotalErrors()}, Field{"max", w.config.MaxErrors})
+	}
+}
+
+// errored returns ErrMaxErrorsReached if the pool is errored.
+func (w *workerPool) errored() error {
+	if w.PoolStatus() == pool.Errored {
+		return ErrMaxErrorsReached
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// The pool should stop dequeuing processes after the maximum number of errors
+func TestWorkerPool_MaxErrors(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithMaxErrors(5))
+	a.NoError(wp.Register(createProcess(10, 1, 0, processFuncWithError)...))
+	a.NoError(wp.Start(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	a.ErrorIs(wp.AwaitIdle(ctx), ErrMaxErrorsReached)
+
+	m := wp.Monitor()
+	a.Equal(pool.Errored, m.PoolStatus())
+	a.Equal(5, m.TotalErrors())
+	for i := 11; i <= 15; i++ {
+		a.Equal(process.Failed, processStats(t, m, PID("p-"+strconv.Itoa(i))).Status)
+	}
+	for i := 16; i <= 20; i++ {
+		stats := processStats(t, m, PID("p-"+strconv.Itoa(i)))
+		a.Equal(process.Waiting, stats.Status)
+		a.True(stats.StartedAt.IsZero())
+	}
+
+	a.ErrorIs(wp.Register(newTestProcess("late", 21, 0, processFunc)), ErrMaxErrorsReached)
+	a.Error(wp.Start(context.Background()))
+	a.ErrorIs(wp.CloseGraceful(), ErrPoolClosed)
+	a.Equal(pool.Closed, m.PoolStatus())
+	a.Equal(process.Cancelled, processStats(t, m, "p-16").Status)
+}
+
+// A pool without the maximum number of errors should run all processes
+func TestWorkerPool_TotalErrors(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Register(createProcess(4, 1, 0, processFuncWithError)...))
+	a.NoError(wp.Register(createProcess(3, 2, 0, processFuncWithoutLog)...))
+	a.NoError(wp.Start(context.Background()))
+
+	a.Error(wp.Wait())
+	a.Equal(pool.Running, wp.Monitor().PoolStatus())
+	a.Equal(4, wp.Monitor().TotalErrors())
+	a.NoError(wp.Monitor().ResetStats())
+	a.Equal(0, wp.Monitor().TotalErrors())
+	a.NoError(wp.Close())
+}
@@ -0,0 +1,141 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+)
+
+// MultiError is a list of errors that is returned as a single error.
+type MultiError struct {
+	// Errors is the list of the aggregated errors.
+	Errors []error
+}
+
+// PoolError is the error of a pool operation. It wraps a sentinel error, such
+// as ErrPoolNotRunning or ErrProcessNotFound, so errors.Is matches it, and
+// holds the state of the pool when the operation failed, which errors.As
+// gives access to.
+type PoolError struct {
+	// Op is the operation that failed, such as "start the pool".
+	Op string
+
+	// Err is the sentinel error.
+	Err error
+
+	// CurrentStatus is the status of the pool when the operation failed.
+	CurrentStatus pool.Status
+
+	// ExpectedStatus is the status that the operation needs. It is the
+	// current status if the operation did not fail because of the status.
+	ExpectedStatus pool.Status
+
+	// PID is the process id that the operation is about, if any.
+	PID PID
+}
+
+// SourceError is an error annotated with the source location where it
+// happened.
+type SourceError struct {
+	// File is the source file name.
+	File string
+
+	// Line is the line number in the source file.
+	Line int
+
+	// Err is the annotated error.
+	Err error
+}
+
+// AnnotateError wraps err in a SourceError that holds the file and line of
+// the caller. The skip argument is the number of stack frames to ascend, with
+// 1 identifying the caller of AnnotateError. Processes can call it inside
+// Start to make the errors that Monitor returns easier to debug. It returns
+// nil if err is nil.
+func AnnotateError(err error, skip int) error {
+	if err == nil {
+		return nil
+	}
+
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return err
+	}
+
+	return &SourceError{
+		File: file,
+		Line: line,
+		Err:  err,
+	}
+}
+
+// Error returns the error message prefixed with the source location.
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+// Unwrap returns the annotated error.
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// Error returns the operation, the error, the process id if any, and the
+// pool status.
+func (e *PoolError) Error() string {
+	msg := e.Err.Error()
+	if e.PID != "" {
+		msg += ": " + e.PID.String()
+	}
+	msg += ", status: " + e.CurrentStatus.String()
+	if e.Op != "" {
+		msg = "unable to " + e.Op + ", " + msg
+	}
+
+	return msg
+}
+
+// Unwrap returns the sentinel error.
+func (e *PoolError) Unwrap() error {
+	return e.Err
+}
+
+// poolError makes a PoolError of the operation that needs the pool to be in
+// the expected status.
+func (w *workerPool) poolError(op string, err error, expected pool.Status) error {
+	return &PoolError{Op: op, Err: err, CurrentStatus: w.PoolStatus(), ExpectedStatus: expected}
+}
+
+// opError makes a PoolError of the operation that has not failed because of
+// the pool status.
+func (w *workerPool) opError(op string, err error) error {
+	status := w.PoolStatus()
+	return &PoolError{Op: op, Err: err, CurrentStatus: status, ExpectedStatus: status}
+}
+
+// pidError makes a PoolError of the operation about the process.
+func (w *workerPool) pidError(op string, err error, pid PID) error {
+	status := w.PoolStatus()
+	return &PoolError{Op: op, Err: err, CurrentStatus: status, ExpectedStatus: status, PID: pid}
+}
+
+// Error returns the messages of all errors.
+func (m *MultiError) Error() string {
+	messages := make([]string, 0, len(m.Errors))
+	for _, err := range m.Errors {
+		messages = append(messages, err.Error())
+	}
+
+	return fmt.Sprintf("%d errors occurred: %s", len(m.Errors), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the aggregated errors, so errors.Is and errors.As can match
+// any of them.
+func (m *MultiError) Unwrap() []error {
+	return m.Errors
+}
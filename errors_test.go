@@ -0,0 +1,78 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+)
+
+var errDiskFull = errors.New("disk is full")
+
+// Annotated process error should hold the source location of the failure
+func TestAnnotateError(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	err = wp.Register(newTestProcess("p-1", 1, 0, func(ctx context.Context, pid PID, d time.Duration) error {
+		return AnnotateError(errDiskFull, 1)
+	}))
+	a.NoError(err)
+	time.Sleep(100 * time.Millisecond)
+	err = wp.Close()
+	a.NoError(err)
+
+	srcErr, ok := processError(t, wp.Monitor(), "p-1").(*SourceError)
+	a.True(ok)
+	a.True(strings.HasSuffix(srcErr.File, "_test.go"))
+	a.Greater(srcErr.Line, 0)
+	a.ErrorIs(srcErr, errDiskFull)
+	a.Nil(AnnotateError(nil, 1))
+}
+
+// The pool operations should return a PoolError that wraps a sentinel error
+func TestPoolError(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+
+	err := wp.Close()
+	a.ErrorIs(err, ErrPoolNotRunning)
+	var poolErr *PoolError
+	a.True(errors.As(err, &poolErr))
+	a.Equal("close the pool", poolErr.Op)
+	a.Equal(pool.Created, poolErr.CurrentStatus)
+	a.Equal(pool.Running, poolErr.ExpectedStatus)
+	a.Equal("unable to close the pool, pool is not running, status: Created", err.Error())
+
+	a.NoError(wp.Start(context.Background()))
+	a.ErrorIs(wp.Start(context.Background()), ErrPoolAlreadyRunning)
+	a.ErrorIs(wp.Resume(), ErrInvalidPoolStatus)
+
+	err = wp.Register(
+		newTestProcess("job", 1, 0, processFuncWithoutLog),
+		newTestProcess("job", 1, 0, processFuncWithoutLog),
+	)
+	a.ErrorIs(err, ErrDuplicatePID)
+	a.True(errors.As(err, &poolErr))
+	a.Equal(PID("p-1"), poolErr.PID)
+	a.Equal(pool.Running, poolErr.CurrentStatus)
+
+	err = wp.KillWait("p-2", time.Second)
+	a.ErrorIs(err, ErrProcessNotFound)
+	a.Equal("unable to kill the process, process not found: p-2, status: Running", err.Error())
+
+	a.NoError(wp.Close())
+	a.ErrorIs(wp.Lock(), ErrPoolNotRunning)
+	a.NoError(wp.Reset())
+	a.ErrorIs(wp.Reset(), ErrInvalidPoolStatus)
+}
@@ -0,0 +1,149 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+const (
+	// ProcessStarted is sent when a worker starts a process.
+	ProcessStarted EventType = iota
+	// ProcessSucceeded is sent when a process ends without error.
+	ProcessSucceeded
+	// ProcessFailed is sent when a process ends with error.
+	ProcessFailed
+	// ProcessKilled is sent when a process has been killed.
+	ProcessKilled
+	// ProcessCancelled is sent when a process has been cancelled because the
+	// pool has been closed.
+	ProcessCancelled
+	// PoolStarted is sent when the pool starts.
+	PoolStarted
+	// PoolClosed is sent when the pool is closed.
+	PoolClosed
+	// PoolPaused is sent when the pool is paused.
+	PoolPaused
+	// PoolResumed is sent when the paused pool is resumed.
+	PoolResumed
+)
+
+var (
+	eventType2String = map[EventType]string{
+		ProcessStarted:   "ProcessStarted",
+		ProcessSucceeded: "ProcessSucceeded",
+		ProcessFailed:    "ProcessFailed",
+		ProcessKilled:    "ProcessKilled",
+		ProcessCancelled: "ProcessCancelled",
+		PoolStarted:      "PoolStarted",
+		PoolClosed:       "PoolClosed",
+		PoolPaused:       "PoolPaused",
+		PoolResumed:      "PoolResumed",
+	}
+
+	// status2EventType maps the final process states to their event.
+	status2EventType = map[process.Status]EventType{
+		process.Succeeded: ProcessSucceeded,
+		process.Failed:    ProcessFailed,
+		process.Killed:    ProcessKilled,
+		process.Cancelled: ProcessCancelled,
+	}
+)
+
+type (
+	// EventType is the kind of an Event.
+	EventType int
+
+	// Event is a state change of the pool or of one of its processes.
+	Event struct {
+		// Type is the kind of the event.
+		Type EventType
+
+		// PID is the id of the process. It is empty for the pool events.
+		PID PID
+
+		// WorkerName is the name of the worker that runs the process. It is
+		// empty for the pool events and the processes that never ran.
+		WorkerName WorkerName
+
+		// Timestamp is the time of the state change.
+		Timestamp time.Time
+	}
+
+	// subscribers holds the channels that receive the pool events.
+	subscribers struct {
+		mutex    sync.RWMutex
+		channels []chan<- Event
+	}
+)
+
+// String returns string value of the event type.
+func (t EventType) String() string {
+	return eventType2String[t]
+}
+
+// add adds the channel to the subscribers.
+func (s *subscribers) add(ch chan<- Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.channels = append(s.channels, ch)
+}
+
+// remove removes the channel from the subscribers.
+func (s *subscribers) remove(ch chan<- Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	kept := make([]chan<- Event, 0, len(s.channels))
+	for _, c := range s.channels {
+		if c != ch {
+			kept = append(kept, c)
+		}
+	}
+	s.channels = kept
+}
+
+// send sends the event to each subscriber. A subscriber whose channel is
+// full misses the event.
+func (s *subscribers) send(e Event) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, ch := range s.channels {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe makes the pool send its events and the events of its processes
+// to ch. The events are sent without blocking, so an event is dropped if ch
+// is full, and a slow subscriber never stalls the workers. Use a buffered
+// channel to keep up with a busy pool.
+func (w *workerPool) Subscribe(ch chan<- Event) {
+	w.subscribers.add(ch)
+}
+
+// Unsubscribe stops sending the events to ch. The pool never closes ch.
+func (w *workerPool) Unsubscribe(ch chan<- Event) {
+	w.subscribers.remove(ch)
+}
+
+// emit sends the event of the given type to the subscribers.
+func (w *workerPool) emit(t EventType, pid PID, wn WorkerName) {
+	w.subscribers.send(Event{Type: t, PID: pid, WorkerName: wn, Timestamp: time.Now()})
+}
+
+// emitFinish sends the event of the final state of the process.
+func (w *workerPool) emitFinish(stats ProcessStats) {
+	if t, ok := status2EventType[stats.Status]; ok {
+		w.emit(t, stats.Process.PID(), stats.WorkerName)
+	}
+}
@@ -0,0 +1,95 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// drainEvents returns the events that are buffered in the channel.
+func drainEvents(ch <-chan Event) []Event {
+	events := make([]Event, 0)
+	for {
+		select {
+		case e := <-ch:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+// Every subscriber should receive the pool and process events
+func TestWorkerPool_Subscribe(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	first := make(chan Event, 16)
+	second := make(chan Event, 16)
+	wp.Subscribe(first)
+	wp.Subscribe(second)
+
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(
+		newTestProcess("ok", 1, 0, processFuncWithoutLog),
+		newTestProcess("failing", 2, 0, processFuncWithError),
+	))
+	a.Error(wp.Wait())
+	a.NoError(wp.Pause())
+	a.NoError(wp.Resume())
+	a.NoError(wp.Close())
+
+	expected := []struct {
+		eventType EventType
+		pid       PID
+	}{
+		{PoolStarted, ""},
+		{ProcessStarted, "p-1"},
+		{ProcessSucceeded, "p-1"},
+		{ProcessStarted, "p-2"},
+		{ProcessFailed, "p-2"},
+		{PoolPaused, ""},
+		{PoolResumed, ""},
+		{PoolClosed, ""},
+	}
+	for _, ch := range []chan Event{first, second} {
+		events := drainEvents(ch)
+		if !a.Len(events, len(expected)) {
+			continue
+		}
+		for i, e := range events {
+			a.Equal(expected[i].eventType, e.Type, e.Type.String())
+			a.Equal(expected[i].pid, e.PID)
+			a.False(e.Timestamp.IsZero())
+		}
+		a.Equal(WorkerName("W0"), events[1].WorkerName)
+		a.Empty(events[0].WorkerName)
+	}
+}
+
+// A full subscriber should miss events without stalling the pool, and an
+// unsubscribed channel should not receive events anymore
+func TestWorkerPool_SubscribeFull(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	full := make(chan Event, 1)
+	wp.Subscribe(full)
+	a.NoError(wp.Start(context.Background()))
+
+	a.NoError(wp.Register(createProcess(10, 1, 0, processFuncWithoutLog)...))
+	a.NoError(wp.Wait())
+	a.Equal(int64(10), wp.Stats().TotalSucceeded)
+	events := drainEvents(full)
+	a.Len(events, 1)
+	a.Equal(PoolStarted, events[0].Type)
+
+	wp.Unsubscribe(full)
+	a.NoError(wp.Register(createProcess(1, 2, 0, processFuncWithoutLog)...))
+	a.NoError(wp.Wait())
+	a.NoError(wp.Close())
+	a.Empty(drainEvents(full))
+}
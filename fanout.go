@@ -0,0 +1,115 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// broadcastSeparator separates the base process id from the worker name
+	// in the ids of the copies of a broadcast process.
+	broadcastSeparator = "@"
+)
+
+// broadcastProcess is the copy of a broadcast process that runs on one
+// worker.
+type broadcastProcess struct {
+	Process
+	worker WorkerName
+}
+
+// PID returns the base process id followed by the name of the worker.
+func (b broadcastProcess) PID() PID {
+	return b.Process.PID() + PID(broadcastSeparator+string(b.worker))
+}
+
+// unwrap returns the wrapped process.
+func (b broadcastProcess) unwrap() Process {
+	return b.Process
+}
+
+// broadcastBase returns the base process id of a copy of a broadcast
+// process.
+func broadcastBase(p Process) (PID, bool) {
+	var wn WorkerName
+	found := findLayer(p, func(l Process) bool {
+		bp, ok := l.(broadcastProcess)
+		if ok {
+			wn = bp.worker
+		}
+		return ok
+	})
+	if !found {
+		return "", false
+	}
+
+	return PID(strings.TrimSuffix(string(p.PID()), broadcastSeparator+string(wn))), true
+}
+
+// BroadcastRegister registers a copy of the process for each worker of the
+// pool, so the copies run on all the workers at the same time, for tasks
+// such as cache warm-ups that must run once per worker. The id of each copy
+// is the id of the process followed by "@" and the name of its worker, such
+// as "p-1@W0". Kill with the id of the process kills all its copies, and
+// Monitor.BroadcastStats counts them in each state. The broadcast covers
+// the workers of the pool at the time of the call, the workers that are
+// added later do not run the process. It returns the same errors as
+// RegisterBatch.
+func (w *workerPool) BroadcastRegister(p Process) error {
+	return w.RegisterBatch(w.broadcastCopies(p)...)
+}
+
+// broadcastCopies returns a copy of the process pinned to each worker of the
+// pool.
+func (w *workerPool) broadcastCopies(p Process) []Process {
+	workers := w.broadcastWorkers()
+	copies := make([]Process, 0, len(workers))
+	for _, wn := range workers {
+		copies = append(copies, WithWorker(broadcastProcess{Process: p, worker: wn}, wn))
+	}
+
+	return copies
+}
+
+// broadcastWorkers returns the names of the running and the sleeping
+// workers, or of the workers that the pool creates when it starts.
+func (w *workerPool) broadcastWorkers() []WorkerName {
+	w.workersMutex.RLock()
+	defer w.workersMutex.RUnlock()
+
+	workers := append(append([]WorkerName(nil), w.workers...), w.sleepingWorkers()...)
+	if len(workers) > 0 {
+		return workers
+	}
+	for i := 0; i < w.size; i++ {
+		workers = append(workers, WorkerName(fmt.Sprintf(defaultWorkerName, w.nextWorker+i)))
+	}
+
+	return workers
+}
+
+// BroadcastStats returns the number of the copies of the broadcast process
+// pid in each state.
+func (w *workerPool) BroadcastStats(pid PID) GroupStats {
+	return groupStats(w.broadcast(pid, nil))
+}
+
+// broadcast returns the stats of the copies of the broadcast process pid
+// that match, or of all of them if match is nil.
+func (w *workerPool) broadcast(pid PID, match func(ProcessStats) bool) []ProcessStats {
+	list := make([]ProcessStats, 0)
+	w.processes.each(func(_ PID, stats ProcessStats) {
+		if stats.Process == nil || (match != nil && !match(stats)) {
+			return
+		}
+		if base, ok := broadcastBase(stats.Process); ok && base == pid {
+			list = append(list, stats)
+		}
+	})
+
+	return list
+}
@@ -0,0 +1,92 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// A broadcast process should run once on every worker at the same time
+func TestWorkerPool_BroadcastRegister(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(3))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	a.NoError(wp.BroadcastRegister(newTestProcess("warmup", 1, 50*time.Millisecond, processFuncWithoutLog)))
+	a.NoError(wp.Wait())
+
+	for _, wn := range []WorkerName{"W0", "W1", "W2"} {
+		stats := processStats(t, wp.Monitor(), PID("p-1@"+string(wn)))
+		a.Equal(process.Succeeded, stats.Status)
+		a.Equal(wn, stats.WorkerName)
+	}
+	a.Equal(GroupStats{Total: 3, Succeeded: 3}, wp.Monitor().BroadcastStats("p-1"))
+	a.Equal(GroupStats{}, wp.Monitor().BroadcastStats("p-2"))
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Killing the base process id should kill all the copies
+func TestWorkerPool_BroadcastKill(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	a.NoError(wp.BroadcastRegister(newTestProcess("warmup", 1, time.Second, processFuncWithoutLog)))
+	wp.Kill("p-1")
+	_ = wp.Wait()
+
+	a.Equal(GroupStats{Total: 2, Killed: 2}, wp.Monitor().BroadcastStats("p-1"))
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// The workers added after a broadcast should not run the process
+func TestWorkerPool_BroadcastPointInTime(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.BroadcastRegister(newTestProcess("warmup", 1, 10*time.Millisecond, processFuncWithoutLog)))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	a.NoError(wp.Scale(2))
+	a.NoError(wp.Wait())
+
+	a.Equal(GroupStats{Total: 2, Succeeded: 2}, wp.Monitor().BroadcastStats("p-1"))
+	_, ok := wp.Monitor().ProcessStats("p-1@W2")
+	a.False(ok)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// A broadcast through a namespace should stay in the namespace
+func TestNamespacePool_BroadcastRegister(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	ns := wp.Namespace("a")
+	a.NoError(ns.BroadcastRegister(newTestProcess("warmup", 1, time.Second, processFuncWithoutLog)))
+	ns.Kill("p-1")
+	_ = wp.Wait()
+
+	a.Equal(GroupStats{Total: 2, Killed: 2}, ns.Monitor().BroadcastStats("p-1"))
+	a.Equal(GroupStats{Total: 2, Killed: 2}, wp.Monitor().BroadcastStats("a/p-1"))
+	a.Equal(GroupStats{}, wp.Namespace("b").Monitor().BroadcastStats("p-1"))
+
+	err = wp.Close()
+	a.NoError(err)
+}
@@ -0,0 +1,125 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+type (
+	// filteredMonitor is a view of the pool monitor that only exposes the
+	// processes whose name matches a pattern.
+	filteredMonitor struct {
+		*workerPool
+		patterns []*regexp.Regexp
+	}
+)
+
+// WithFilter returns a view of the monitor that only exposes the processes
+// whose name matches pattern. The pool-level methods, such as PoolStatus and
+// WorkerList, are not filtered.
+func (w *workerPool) WithFilter(pattern *regexp.Regexp) Monitor {
+	return &filteredMonitor{workerPool: w, patterns: []*regexp.Regexp{pattern}}
+}
+
+// match reports whether the process of stats is exposed by the view.
+func (f *filteredMonitor) match(stats ProcessStats) bool {
+	if stats.Process == nil {
+		return false
+	}
+
+	for _, pattern := range f.patterns {
+		if !pattern.MatchString(stats.Process.Name()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WithFilter returns a narrower view that only exposes the processes whose
+// name matches pattern and the patterns of f.
+func (f *filteredMonitor) WithFilter(pattern *regexp.Regexp) Monitor {
+	patterns := append(make([]*regexp.Regexp, 0, len(f.patterns)+1), f.patterns...)
+
+	return &filteredMonitor{workerPool: f.workerPool, patterns: append(patterns, pattern)}
+}
+
+// Error returns the process error if the process matches the filter.
+func (f *filteredMonitor) Error(pid PID) (error, bool) { //nolint:stylecheck
+	stats, ok := f.ProcessStats(pid)
+	return stats.err, ok
+}
+
+// Histogram returns the histogram of the process if the process matches the
+// filter.
+func (f *filteredMonitor) Histogram(pid PID) (*DurationHistogram, bool) {
+	if _, ok := f.ProcessStats(pid); !ok {
+		return nil, false
+	}
+
+	return f.workerPool.Histogram(pid)
+}
+
+// ProcessStats returns the process stats if the process matches the filter,
+// otherwise an empty ProcessStats and false.
+func (f *filteredMonitor) ProcessStats(pid PID) (ProcessStats, bool) {
+	if stats, ok := f.workerPool.ProcessStats(pid); ok && f.match(stats) {
+		return stats, true
+	}
+
+	return ProcessStats{}, false
+}
+
+// Delta returns the delta of the processes that match the filter.
+func (f *filteredMonitor) Delta(since time.Time) MonitorDelta {
+	delta := f.workerPool.Delta(since)
+	delta.Processes = f.filter(delta.Processes)
+
+	return delta
+}
+
+// CompletedProcesses returns the completed processes that match the filter.
+func (f *filteredMonitor) CompletedProcesses() []ProcessStats {
+	return f.filter(f.workerPool.CompletedProcesses())
+}
+
+// Purge removes the stats of the completed processes that match the filter.
+func (f *filteredMonitor) Purge(olderThan time.Time) int {
+	return f.purge(olderThan, f.match)
+}
+
+// GroupStats returns the group stats of the processes that match the
+// filter.
+func (f *filteredMonitor) GroupStats(name string) GroupStats {
+	return groupStats(f.group(name, f.match))
+}
+
+// GroupStatus returns the rolled-up state of the processes of the group that
+// match the filter.
+func (f *filteredMonitor) GroupStatus(name string) (process.Status, bool) {
+	return groupStatus(f.GroupStats(name))
+}
+
+// BroadcastStats returns the broadcast stats of the processes that match the
+// filter.
+func (f *filteredMonitor) BroadcastStats(pid PID) GroupStats {
+	return groupStats(f.broadcast(pid, f.match))
+}
+
+// filter returns the stats that match the filter.
+func (f *filteredMonitor) filter(list []ProcessStats) []ProcessStats {
+	filtered := make([]ProcessStats, 0, len(list))
+	for _, stats := range list {
+		if f.match(stats) {
+			filtered = append(filtered, stats)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,53 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// Filtered monitor should only expose the processes whose name matches
+func TestWorkerPool_WithFilter(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(
+		newTestProcess("db-read", 1, 0, processFuncWithoutLog),
+		newTestProcess("db-write", 2, 0, processFuncWithError),
+		newTestProcess("http-get", 3, 0, processFuncWithError),
+	)
+	wp.Wait()
+
+	m := wp.Monitor().WithFilter(regexp.MustCompile("^db-.*"))
+	a.Equal(process.Succeeded, processStats(t, m, "p-1").Status)
+	a.Equal(process.Failed, processStats(t, m, "p-2").Status)
+	a.Error(processError(t, m, "p-2"))
+	stats, ok := m.ProcessStats("p-3")
+	a.False(ok)
+	a.Equal(ProcessStats{}, stats)
+	err, ok = m.Error("p-3")
+	a.False(ok)
+	a.NoError(err)
+	a.Len(m.CompletedProcesses(), 2)
+	a.Len(m.Delta(time.Time{}).Processes, 2)
+	for _, stats := range m.CompletedProcesses() {
+		a.Regexp("^db-", stats.Process.Name())
+	}
+
+	a.Len(m.WithFilter(regexp.MustCompile("write$")).CompletedProcesses(), 1)
+	a.Equal(2, m.Purge(time.Now()))
+	a.Equal(process.Failed, processStats(t, wp.Monitor(), "p-3").Status)
+
+	err = wp.Close()
+	a.NoError(err)
+}
@@ -0,0 +1,88 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// Freeze stops the workers from taking processes from the queue until Thaw is
+// called. The running processes are not interrupted, and Register keeps
+// adding processes to the queue. Together with RegisterBatch, it lets a
+// caller prepare a batch of processes before any of them runs. The processes
+// that are submitted to a worker with ForWorker are not frozen. It returns
+// an error if the pool is not running or is already frozen.
+func (w *workerPool) Freeze() error {
+	if status := w.PoolStatus(); status != pool.Running {
+		return w.poolError("freeze the pool", ErrPoolNotRunning, pool.Running)
+	}
+
+	if !w.queue.freeze() {
+		return w.opError("freeze the pool", ErrPoolFrozen)
+	}
+
+	return nil
+}
+
+// Thaw lets the workers take processes from the queue again after Freeze. It
+// returns an error if the pool is not frozen.
+func (w *workerPool) Thaw() error {
+	if !w.queue.thaw() {
+		return w.opError("thaw the pool", ErrPoolNotFrozen)
+	}
+
+	return nil
+}
+
+// RegisterBatch adds the processes to the queue at once, so the processes of
+// other callers are not interleaved between them. The processes keep their
+// order, unless they have different priorities. The start jitter and the
+// rate limit backpressure do not apply to a batch. It returns the same
+// errors as Register, and an error if the pool is closed.
+func (w *workerPool) RegisterBatch(args ...Process) error {
+	args = expandPipelines(args)
+	for _, p := range args {
+		if err := w.checkName(p); err != nil {
+			return err
+		}
+	}
+
+	ready := make([]Process, 0, len(args))
+	err := w.claimPIDs(args, func(args []Process) error {
+		if err := w.checkDependencies(args); err != nil {
+			return err
+		}
+
+		if err := w.checkWorkers(args); err != nil {
+			return err
+		}
+
+		if err := w.admit(args...); err != nil {
+			return err
+		}
+
+		for _, p := range args {
+			w.prepare(p)
+			if _, pinned := pinnedWorker(p); pinned || w.processes.get(p.PID()).Status == process.Pending {
+				w.publish(p)
+			} else {
+				ready = append(ready, p)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !w.queue.pushAll(ready) {
+		return w.poolError("register the processes", ErrPoolClosed, pool.Running)
+	}
+	w.wake()
+
+	return nil
+}
@@ -0,0 +1,83 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A batch registered while the pool is frozen should run contiguously
+func TestWorkerPool_FreezeRegisterBatch(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.Error(wp.Freeze())
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	mutex := new(sync.Mutex)
+	order := make([]string, 0)
+	record := func(ctx context.Context, pid PID, d time.Duration) error {
+		mutex.Lock()
+		order = append(order, pid.String())
+		mutex.Unlock()
+		return nil
+	}
+
+	a.NoError(wp.Freeze())
+	a.ErrorIs(wp.Freeze(), ErrPoolFrozen)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 1; g <= 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 1; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				a.NoError(wp.Register(newTestProcess("other", g*1000+i, 0, record)))
+				time.Sleep(time.Millisecond)
+			}
+		}(g)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	batch := make([]Process, 0, 5)
+	for i := 1; i <= 5; i++ {
+		batch = append(batch, newTestProcess("batch", i, 0, record))
+	}
+	a.NoError(wp.RegisterBatch(batch...))
+	time.Sleep(10 * time.Millisecond)
+	a.Zero(wp.Stats().TotalSucceeded)
+
+	a.NoError(wp.Thaw())
+	a.ErrorIs(wp.Thaw(), ErrPoolNotFrozen)
+	time.Sleep(10 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+	a.NoError(wp.Wait())
+
+	first := -1
+	for i, pid := range order {
+		if len(pid) == 3 && strings.HasPrefix(pid, "p-") {
+			first = i
+			break
+		}
+	}
+	a.GreaterOrEqual(first, 1)
+	a.Equal([]string{"p-1", "p-2", "p-3", "p-4", "p-5"}, order[first:first+5])
+
+	err = wp.Close()
+	a.NoError(err)
+}
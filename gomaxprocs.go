@@ -0,0 +1,74 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+var (
+	// gomaxprocsMutex guards the GOMAXPROCS overrides, because GOMAXPROCS
+	// is a global setting.
+	gomaxprocsMutex sync.Mutex
+
+	// gomaxprocsActive is the number of running processes that override
+	// GOMAXPROCS.
+	gomaxprocsActive int
+
+	// gomaxprocsOriginal is the GOMAXPROCS value before the first running
+	// override.
+	gomaxprocsOriginal int
+)
+
+// gomaxprocsProcess wraps a process to run it with a GOMAXPROCS override.
+type gomaxprocsProcess struct {
+	Process
+	n int
+}
+
+// WithProcessGOMAXPROCS wraps the process to run it with GOMAXPROCS set to n.
+// The original value is restored after Start returns. GOMAXPROCS is global,
+// so it also applies to every other goroutine while the process is running.
+// If several wrapped processes run concurrently, a warning is logged with the
+// loggers of the pool, the last one to start wins, and the original value is
+// restored when all of them are finished.
+func WithProcessGOMAXPROCS(p Process, n int) Process {
+	return gomaxprocsProcess{Process: p, n: n}
+}
+
+// unwrap returns the wrapped process.
+func (g gomaxprocsProcess) unwrap() Process {
+	return g.Process
+}
+
+// Start sets GOMAXPROCS, runs the process, and restores GOMAXPROCS.
+func (g gomaxprocsProcess) Start(ctx context.Context) error {
+	gomaxprocsMutex.Lock()
+	if gomaxprocsActive == 0 {
+		gomaxprocsOriginal = runtime.GOMAXPROCS(g.n)
+	} else {
+		logContext(ctx, levelWarn,
+			"process overrides GOMAXPROCS while other processes are overriding it",
+			Field{"name", g.Name()}, Field{"pid", g.PID()},
+			Field{"overrides", gomaxprocsActive})
+		runtime.GOMAXPROCS(g.n)
+	}
+	gomaxprocsActive++
+	gomaxprocsMutex.Unlock()
+
+	defer func() {
+		gomaxprocsMutex.Lock()
+		defer gomaxprocsMutex.Unlock()
+
+		gomaxprocsActive--
+		if gomaxprocsActive == 0 {
+			runtime.GOMAXPROCS(gomaxprocsOriginal)
+		}
+	}()
+
+	return g.Process.Start(ctx)
+}
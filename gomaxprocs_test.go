@@ -0,0 +1,68 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// GOMAXPROCS should be overridden while the process is running and restored
+// after
+func TestWithProcessGOMAXPROCS(t *testing.T) {
+	a := assert.New(t)
+	original := runtime.GOMAXPROCS(0)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	var during int
+	p := newTestProcess("matrix", 1, 50*time.Millisecond, func(ctx context.Context, pid PID, duration time.Duration) error {
+		during = runtime.GOMAXPROCS(0)
+		return busyLoop(ctx, pid, duration)
+	})
+	wp.Register(WithProcessGOMAXPROCS(p, 1))
+	wp.Wait()
+
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-1").Status)
+	a.Equal(1, during)
+	a.Equal(original, runtime.GOMAXPROCS(0))
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Concurrent GOMAXPROCS overrides should be logged with the pool loggers
+func TestWithProcessGOMAXPROCS_Concurrent(t *testing.T) {
+	a := assert.New(t)
+	logger := &recordingLogger{}
+	wp := NewPool(WithWorkerCount(2), WithLogger(logger))
+	a.NoError(wp.Start(context.Background()))
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	pFunc := func(ctx context.Context, pid PID, duration time.Duration) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+	wp.Register(
+		WithProcessGOMAXPROCS(newTestProcess("matrix", 1, 0, pFunc), 1),
+		WithProcessGOMAXPROCS(newTestProcess("matrix", 2, 0, pFunc), 1),
+	)
+	<-started
+	<-started
+	close(release)
+	wp.Wait()
+
+	a.Contains(logger.messages, "process overrides GOMAXPROCS while other processes are overriding it")
+	a.NoError(wp.Close())
+}
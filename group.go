@@ -0,0 +1,249 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// ErrGroupNotFound is returned by KillGroup when the group has no process.
+var ErrGroupNotFound = errors.New("group not found")
+
+type (
+	// groupProcess wraps a process to add it to a group.
+	groupProcess struct {
+		Process
+		group string
+	}
+
+	// GroupStats holds the number of the processes of a group in each
+	// state.
+	GroupStats struct {
+		// Total is the number of the processes of the group.
+		Total int
+
+		// Pending is the number of the processes that wait for their
+		// dependencies.
+		Pending int
+
+		// Waiting is the number of the processes in the queue.
+		Waiting int
+
+		// Running is the number of the running processes.
+		Running int
+
+		// Retrying is the number of the processes that wait to run again.
+		Retrying int
+
+		// Succeeded is the number of the processes that succeeded.
+		Succeeded int
+
+		// Failed is the number of the processes that failed.
+		Failed int
+
+		// Killed is the number of the processes that have been killed.
+		Killed int
+
+		// Cancelled is the number of the processes that have been cancelled.
+		Cancelled int
+	}
+)
+
+// WithGroup wraps the process to add it to the group name, so it can be
+// managed together with the other processes of the group by KillGroup,
+// WaitGroup, Monitor.GroupStats, and Monitor.GroupStatus. A process belongs
+// to at most one group, so if WithGroup is applied more than once, the
+// outermost group wins.
+func WithGroup(p Process, name string) Process {
+	return groupProcess{Process: p, group: name}
+}
+
+// unwrap returns the wrapped process.
+func (g groupProcess) unwrap() Process {
+	return g.Process
+}
+
+// processGroup returns the group of the process that is set by WithGroup, or
+// an empty string.
+func processGroup(p Process) string {
+	group := ""
+	findLayer(p, func(l Process) bool {
+		gp, ok := l.(groupProcess)
+		if ok {
+			group = gp.group
+		}
+		return ok
+	})
+
+	return group
+}
+
+// Finished reports whether all the processes of the group are in a final
+// state.
+func (s GroupStats) Finished() bool {
+	return s.Succeeded+s.Failed+s.Killed+s.Cancelled == s.Total
+}
+
+// Status rolls the states of the processes of the group up into one state.
+// It is Running if a process is running or retrying. Otherwise it is the
+// first state that a process of the group is in, in the order Failed,
+// Killed, Cancelled, Waiting, Pending, and Succeeded, so a failure shows
+// before the group is finished.
+func (s GroupStats) Status() process.Status {
+	switch {
+	case s.Running > 0 || s.Retrying > 0:
+		return process.Running
+	case s.Failed > 0:
+		return process.Failed
+	case s.Killed > 0:
+		return process.Killed
+	case s.Cancelled > 0:
+		return process.Cancelled
+	case s.Waiting > 0:
+		return process.Waiting
+	case s.Pending > 0:
+		return process.Pending
+	default:
+		return process.Succeeded
+	}
+}
+
+// KillGroup kills every Waiting, Pending, Retrying, and Running process of
+// the group name, like calling Kill on each of them. It returns
+// ErrGroupNotFound if the group has no process.
+func (w *workerPool) KillGroup(name string) error {
+	return w.killGroup(name, w.group(name, nil))
+}
+
+// WaitGroup blocks until all the processes of the group name are in a final
+// state, and then returns a *MultiError that aggregates the errors of the
+// failed processes of the group, or nil if none has failed. It returns right
+// away if the group has no process, and the error of ctx if ctx is done
+// first.
+func (w *workerPool) WaitGroup(ctx context.Context, name string) error {
+	return w.waitGroup(ctx, func() []ProcessStats {
+		return w.group(name, nil)
+	})
+}
+
+// GroupStats returns the number of the processes of the group name in each
+// state.
+func (w *workerPool) GroupStats(name string) GroupStats {
+	return groupStats(w.group(name, nil))
+}
+
+// GroupStatus returns the rolled-up state of the processes of the group
+// name, or false if the group has no process.
+func (w *workerPool) GroupStatus(name string) (process.Status, bool) {
+	return groupStatus(w.GroupStats(name))
+}
+
+// group returns the stats of the processes of the group name that match, or
+// of all of them if match is nil.
+func (w *workerPool) group(name string,
+	match func(ProcessStats) bool) []ProcessStats {
+	list := make([]ProcessStats, 0)
+	w.processes.each(func(_ PID, stats ProcessStats) {
+		if stats.Group != name || stats.Process == nil {
+			return
+		}
+		if match == nil || match(stats) {
+			list = append(list, stats)
+		}
+	})
+
+	return list
+}
+
+// killGroup kills the processes of the list that are not finished. It
+// returns ErrGroupNotFound if the list is empty.
+func (w *workerPool) killGroup(name string, list []ProcessStats) error {
+	if len(list) == 0 {
+		return fmt.Errorf("%w: %s", ErrGroupNotFound, name)
+	}
+
+	for _, stats := range list {
+		if !stats.Status.IsTerminal() {
+			_ = w.Kill(stats.Process.PID())
+		}
+	}
+
+	return nil
+}
+
+// waitGroup blocks until all the processes returned by members are finished
+// and returns their errors, or until ctx is done.
+func (w *workerPool) waitGroup(ctx context.Context,
+	members func() []ProcessStats) error {
+	for {
+		changed := w.changes.wait()
+		list := members()
+		if groupStats(list).Finished() {
+			return processErrors(list)
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// groupStatus returns the rolled-up state of the group stats, or false if
+// the group has no process.
+func groupStatus(stats GroupStats) (process.Status, bool) {
+	if stats.Total == 0 {
+		return process.Waiting, false
+	}
+
+	return stats.Status(), true
+}
+
+// add returns the sum of the group stats.
+func (s GroupStats) add(o GroupStats) GroupStats {
+	s.Total += o.Total
+	s.Pending += o.Pending
+	s.Waiting += o.Waiting
+	s.Running += o.Running
+	s.Retrying += o.Retrying
+	s.Succeeded += o.Succeeded
+	s.Failed += o.Failed
+	s.Killed += o.Killed
+	s.Cancelled += o.Cancelled
+
+	return s
+}
+
+// groupStats counts the processes of the list in each state.
+func groupStats(list []ProcessStats) GroupStats {
+	stats := GroupStats{Total: len(list)}
+	for _, s := range list {
+		switch s.Status {
+		case process.Pending:
+			stats.Pending++
+		case process.Waiting:
+			stats.Waiting++
+		case process.Running:
+			stats.Running++
+		case process.Retrying:
+			stats.Retrying++
+		case process.Succeeded:
+			stats.Succeeded++
+		case process.Failed:
+			stats.Failed++
+		case process.Killed:
+			stats.Killed++
+		case process.Cancelled:
+			stats.Cancelled++
+		}
+	}
+
+	return stats
+}
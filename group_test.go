@@ -0,0 +1,147 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// KillGroup should kill the processes of the group only
+func TestWorkerPool_KillGroup(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Register(
+		WithGroup(newTestProcess("sync-1", 1, time.Minute, processFuncWithoutLog), "sync"),
+		WithGroup(newTestProcess("sync-2", 2, time.Minute, processFuncWithoutLog), "sync"),
+		newTestProcess("other", 3, 10*time.Millisecond, processFuncWithoutLog),
+	))
+	a.NoError(wp.Start(context.Background()))
+	_, err := wp.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.NoError(err)
+
+	// A process that is killed before it starts has no error.
+	a.NoError(wp.KillGroup("sync"))
+	if err := wp.WaitGroup(context.Background(), "sync"); err != nil {
+		a.ErrorIs(err, errCancelled)
+	}
+	stats := wp.Monitor().GroupStats("sync")
+	a.Equal(GroupStats{Total: 2, Killed: 2}, stats)
+	a.True(stats.Finished())
+	a.Equal("sync", processStats(t, wp.Monitor(), "p-1").Group)
+
+	if err := wp.Wait(); err != nil {
+		a.ErrorIs(err, errCancelled)
+	}
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-3").Status)
+	a.NoError(wp.Close())
+}
+
+// WaitGroup should return the errors of the failed processes of the group
+func TestWorkerPool_WaitGroup(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.WaitGroup(context.Background(), "import"))
+	a.NoError(wp.Register(
+		WithGroup(newTestProcess("import-1", 1, 0, processFuncWithoutLog), "import"),
+		WithGroup(newTestProcess("import-2", 2, 0, processFuncWithError), "import"),
+		WithGroup(WithGroup(newTestProcess("export", 3, 0, processFuncWithoutLog), "import"), "export"),
+	))
+	a.Equal(GroupStats{Total: 2, Waiting: 2}, wp.Monitor().GroupStats("import"))
+	a.NoError(wp.Start(context.Background()))
+
+	err := wp.WaitGroup(context.Background(), "import")
+	var multi *MultiError
+	a.ErrorAs(err, &multi)
+	a.Len(multi.Errors, 1)
+	stats := wp.Monitor().GroupStats("import")
+	a.Equal(GroupStats{Total: 2, Succeeded: 1, Failed: 1}, stats)
+	a.Equal(1, wp.Monitor().GroupStats("export").Total)
+	a.NoError(wp.Close())
+}
+
+// The group methods of a namespace should only see the processes of the namespace
+func TestNamespacePool_Group(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Namespace("a").Register(WithGroup(newTestProcess("a", 1, time.Minute, processFuncWithoutLog), "g")))
+	a.NoError(wp.Namespace("b").Register(WithGroup(newTestProcess("b", 1, 0, processFuncWithoutLog), "g")))
+	a.Equal(1, wp.Namespace("a").Monitor().GroupStats("g").Total)
+	a.Equal(2, wp.Monitor().GroupStats("g").Total)
+
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Namespace("b").WaitGroup(context.Background(), "g"))
+	a.Equal(1, wp.Monitor().GroupStats("g").Running+wp.Monitor().GroupStats("g").Waiting)
+	a.NoError(wp.Namespace("a").KillGroup("g"))
+	if err := wp.WaitGroup(context.Background(), "g"); err != nil {
+		a.ErrorIs(err, errCancelled)
+	}
+	a.Equal(GroupStats{Total: 2, Succeeded: 1, Killed: 1}, wp.Monitor().GroupStats("g"))
+	a.NoError(wp.Close())
+}
+
+// KillGroup should return ErrGroupNotFound for a group without process
+func TestWorkerPool_KillGroupNotFound(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.ErrorIs(wp.KillGroup("missing"), ErrGroupNotFound)
+	a.ErrorIs(wp.Namespace("a").KillGroup("missing"), ErrGroupNotFound)
+}
+
+// WaitGroup should return when its context is done
+func TestWorkerPool_WaitGroupContext(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Register(WithGroup(newTestProcess("slow", 1, time.Minute, processFuncWithoutLog), "g")))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	a.ErrorIs(wp.WaitGroup(ctx, "g"), context.DeadlineExceeded)
+}
+
+// GroupStatus should roll the states of the group up
+func TestWorkerPool_GroupStatus(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	_, ok := wp.Monitor().GroupStatus("g")
+	a.False(ok)
+
+	a.NoError(wp.Register(
+		WithGroup(newTestProcess("failing", 1, 0, processFuncWithError), "g"),
+		WithGroup(newTestProcess("slow", 2, time.Minute, processFuncWithoutLog), "g"),
+		WithGroup(newTestProcess("last", 3, 0, processFuncWithoutLog), "g"),
+	))
+	status, ok := wp.Monitor().GroupStatus("g")
+	a.True(ok)
+	a.Equal(process.Waiting, status)
+
+	a.NoError(wp.Start(context.Background()))
+	_, err := wp.WaitUntilStatus(context.Background(), "p-2", process.Running)
+	a.NoError(err)
+	status, _ = wp.Monitor().GroupStatus("g")
+	a.Equal(process.Running, status)
+
+	a.NoError(wp.KillWait("p-2", time.Second))
+	a.Eventually(func() bool {
+		status, _ := wp.Monitor().GroupStatus("g")
+		return status == process.Failed
+	}, time.Second, 5*time.Millisecond)
+	a.NoError(wp.Close())
+}
+
+// Group status should follow the precedence of the states
+func TestGroupStats_Status(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(process.Running, GroupStats{Total: 2, Retrying: 1, Failed: 1}.Status())
+	a.Equal(process.Failed, GroupStats{Total: 3, Failed: 1, Killed: 1, Waiting: 1}.Status())
+	a.Equal(process.Killed, GroupStats{Total: 2, Killed: 1, Succeeded: 1}.Status())
+	a.Equal(process.Cancelled, GroupStats{Total: 2, Cancelled: 1, Waiting: 1}.Status())
+	a.Equal(process.Waiting, GroupStats{Total: 2, Waiting: 1, Pending: 1}.Status())
+	a.Equal(process.Pending, GroupStats{Total: 2, Pending: 1, Succeeded: 1}.Status())
+	a.Equal(process.Succeeded, GroupStats{Total: 2, Succeeded: 2}.Status())
+}
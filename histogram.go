@@ -0,0 +1,141 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// DurationHistogram records the running time of each attempt of a
+	// process. It is safe for concurrent use, so it can be read while the
+	// workers record new samples.
+	DurationHistogram struct {
+		mutex   sync.RWMutex
+		samples []time.Duration
+		window  int
+		next    int
+	}
+
+	// histogramMap is a thread safe map of the histograms of the processes.
+	// 		Key: PID
+	// 		Value: *DurationHistogram
+	histogramMap struct {
+		internal sync.Map
+	}
+)
+
+// newDurationHistogram makes a new instance of DurationHistogram. A positive
+// window keeps only the last window samples.
+func newDurationHistogram(window int) *DurationHistogram {
+	return &DurationHistogram{window: window}
+}
+
+// record adds a sample. With a window, the oldest sample is replaced once
+// the window is full.
+func (h *DurationHistogram) record(d time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.window <= 0 || len(h.samples) < h.window {
+		h.samples = append(h.samples, d)
+		return
+	}
+
+	h.samples[h.next] = d
+	h.next = (h.next + 1) % h.window
+}
+
+// sorted returns a sorted copy of the samples.
+func (h *DurationHistogram) sorted() []time.Duration {
+	h.mutex.RLock()
+	samples := append([]time.Duration(nil), h.samples...)
+	h.mutex.RUnlock()
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+
+	return samples
+}
+
+// Count returns the number of samples.
+func (h *DurationHistogram) Count() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return len(h.samples)
+}
+
+// Sum returns the total duration of the samples.
+func (h *DurationHistogram) Sum() time.Duration {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	var sum time.Duration
+	for _, d := range h.samples {
+		sum += d
+	}
+
+	return sum
+}
+
+// Min returns the shortest sample, or zero if there is no sample.
+func (h *DurationHistogram) Min() time.Duration {
+	return h.Percentile(0)
+}
+
+// Max returns the longest sample, or zero if there is no sample.
+func (h *DurationHistogram) Max() time.Duration {
+	return h.Percentile(100)
+}
+
+// P50 returns the median of the samples.
+func (h *DurationHistogram) P50() time.Duration {
+	return h.Percentile(50)
+}
+
+// P90 returns the 90th percentile of the samples.
+func (h *DurationHistogram) P90() time.Duration {
+	return h.Percentile(90)
+}
+
+// P99 returns the 99th percentile of the samples.
+func (h *DurationHistogram) P99() time.Duration {
+	return h.Percentile(99)
+}
+
+// Percentile returns the nearest-rank p-th percentile of the samples, for p
+// in [0, 100], or zero if there is no sample.
+func (h *DurationHistogram) Percentile(p int) time.Duration {
+	return percentile(h.sorted(), p)
+}
+
+func (c *histogramMap) record(pid PID, d time.Duration, window int) {
+	in, _ := c.internal.LoadOrStore(pid, newDurationHistogram(window))
+	h, _ := in.(*DurationHistogram)
+	h.record(d)
+}
+
+func (c *histogramMap) get(pid PID) *DurationHistogram {
+	in, _ := c.internal.Load(pid)
+	h, _ := in.(*DurationHistogram)
+	return h
+}
+
+func (c *histogramMap) delete(pid PID) {
+	c.internal.Delete(pid)
+}
+
+// Histogram returns the running time histogram of the attempts of the
+// process. The histogram keeps the attempts of the previous runs of the
+// process id, and it is updated live by the workers. It returns false if no
+// attempt of the process has finished yet.
+func (w *workerPool) Histogram(pid PID) (*DurationHistogram, bool) {
+	h := w.histograms.get(pid)
+	return h, h != nil
+}
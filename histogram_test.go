@@ -0,0 +1,93 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Histogram should summarize the samples
+func TestDurationHistogram(t *testing.T) {
+	a := assert.New(t)
+	h := newDurationHistogram(0)
+	a.Equal(0, h.Count())
+	a.Equal(time.Duration(0), h.P99())
+
+	for i := 1; i <= 100; i++ {
+		h.record(time.Duration(i) * time.Millisecond)
+	}
+	a.Equal(100, h.Count())
+	a.Equal(5050*time.Millisecond, h.Sum())
+	a.Equal(time.Millisecond, h.Min())
+	a.Equal(100*time.Millisecond, h.Max())
+	a.Equal(50*time.Millisecond, h.P50())
+	a.Equal(90*time.Millisecond, h.P90())
+	a.Equal(99*time.Millisecond, h.P99())
+}
+
+// Histogram with a window should keep the last samples only
+func TestDurationHistogram_Window(t *testing.T) {
+	a := assert.New(t)
+	h := newDurationHistogram(3)
+	for i := 1; i <= 5; i++ {
+		h.record(time.Duration(i) * time.Second)
+	}
+	a.Equal(3, h.Count())
+	a.Equal(3*time.Second, h.Min())
+	a.Equal(5*time.Second, h.Max())
+	a.Equal(12*time.Second, h.Sum())
+}
+
+// Histogram should be safe for concurrent writes and reads
+func TestDurationHistogram_Concurrent(t *testing.T) {
+	h := newDurationHistogram(10)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				h.record(time.Duration(j))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				h.P99()
+				h.Sum()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 10, h.Count())
+}
+
+// Each attempt of a retried process should be a sample of its histogram
+func TestWorkerPool_Histogram(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	_, ok := wp.Monitor().Histogram("p-1")
+	a.False(ok)
+
+	failing := newTestProcess("flaky", 1, 0, func(context.Context, PID, time.Duration) error {
+		time.Sleep(10 * time.Millisecond)
+		return errFlaky
+	})
+	a.NoError(wp.Register(WithRetry(failing, 3, FixedBackoff{})))
+	a.NoError(wp.Start(context.Background()))
+	a.Error(wp.Wait())
+
+	h, ok := wp.Monitor().Histogram("p-1")
+	a.True(ok)
+	a.Equal(3, h.Count())
+	a.GreaterOrEqual(h.Min(), 10*time.Millisecond)
+	a.GreaterOrEqual(h.Sum(), 30*time.Millisecond)
+	a.NoError(wp.Close())
+}
@@ -0,0 +1,146 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// ErrProcessActive is returned by Register when a process has the id of a
+// registered process that has not reached a final state yet.
+var ErrProcessActive = errors.New("process is still active")
+
+// historyProcess wraps a process to keep the stats of the previous runs of
+// its id.
+type historyProcess struct {
+	Process
+}
+
+// WithPreserveHistory wraps the process to keep the stats of the previous
+// runs of its id when it is registered again. The previous runs are in
+// ProcessStats.History, oldest first. Without it, the stats of the previous
+// run are replaced.
+func WithPreserveHistory(p Process) Process {
+	return historyProcess{Process: p}
+}
+
+// unwrap returns the wrapped process.
+func (h historyProcess) unwrap() Process {
+	return h.Process
+}
+
+// preservesHistory reports whether the process is wrapped by
+// WithPreserveHistory.
+func preservesHistory(p Process) bool {
+	return findLayer(p, func(l Process) bool {
+		_, ok := l.(historyProcess)
+		return ok
+	})
+}
+
+// checkPIDs returns ErrProcessActive if a process of args has the id of a
+// registered process that has not reached a final state, or of another
+// process of args. The id of a finished process can be registered again.
+func (w *workerPool) checkPIDs(args []Process) error {
+	seen := make(map[PID]struct{}, len(args))
+	for _, p := range args {
+		if _, ok := seen[p.PID()]; ok {
+			return w.pidError("register the process", ErrDuplicatePID, p.PID())
+		}
+		seen[p.PID()] = struct{}{}
+
+		if w.config.PIDIsolation && strings.HasPrefix(string(p.PID()), archivePrefix) {
+			return w.pidError("register the process", ErrReservedPID, p.PID())
+		}
+
+		if stats, ok := w.ProcessStats(p.PID()); ok && !stats.Status.IsTerminal() {
+			return w.pidError("register the process", ErrProcessActive, p.PID())
+		}
+	}
+
+	return nil
+}
+
+// claimPIDs skips the processes of args whose id is used by an active
+// process like deduplicate, checks the ids of the others like checkPIDs, and
+// calls register with them. The ids are claimed under one lock until
+// register returns, so register must prepare the processes that it accepts.
+// Otherwise, concurrent registrations of an id could all be accepted.
+func (w *workerPool) claimPIDs(args []Process, register func(args []Process) error) error {
+	w.registerMutex.Lock()
+	defer w.registerMutex.Unlock()
+
+	args = w.deduplicate(args)
+	if err := w.checkPIDs(args); err != nil {
+		return err
+	}
+
+	return register(args)
+}
+
+// registered returns the number of the processes of args whose id is
+// registered.
+func (w *workerPool) registered(args []Process) int {
+	n := 0
+	for _, p := range args {
+		if _, ok := w.ProcessStats(p.PID()); ok {
+			n++
+		}
+	}
+
+	return n
+}
+
+// replace keeps the stats of the previous run of the id of the process in
+// the history of the new run if the process preserves it.
+func (w *workerPool) replace(p Process, stats *ProcessStats) {
+	old, ok := w.ProcessStats(p.PID())
+	if ok && preservesHistory(p) {
+		history := old.History
+		old.History = nil
+		stats.History = append(history, old)
+	}
+}
+
+// deduplicate returns args without the processes whose id is used by an
+// active process, if the pool skips them. Each skipped process is recorded
+// in the history of the active process. It is only called by claimPIDs, so
+// a process that is registered concurrently is either skipped or prepared.
+func (w *workerPool) deduplicate(args []Process) []Process {
+	if !w.config.Deduplication {
+		return args
+	}
+
+	kept := make([]Process, 0, len(args))
+	for _, p := range args {
+		stats, ok := w.ProcessStats(p.PID())
+		if !ok || stats.Status.IsTerminal() {
+			kept = append(kept, p)
+			continue
+		}
+
+		// The stats belong to the workers of the active process, so the
+		// skipped process is kept by its control panel.
+		now := time.Now()
+		w.controlPanel.get(p.PID()).skip(ProcessStats{
+			Process:    p,
+			Status:     process.Skipped,
+			Priority:   processPriority(p),
+			Cycle:      stats.Cycle,
+			Group:      processGroup(p),
+			FinishedAt: now,
+			EnqueuedAt: now,
+			updatedAt:  now,
+		})
+		w.log(levelDebug, "process has been skipped, its id is active", Field{"name", w.processName(p)},
+			Field{"pid", p.PID()}, Field{"status", stats.Status})
+	}
+
+	return kept
+}
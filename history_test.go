@@ -0,0 +1,137 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// A finished process should be registered again under the same id
+func TestWorkerPool_RegisterAgain(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithMaxTotalProcesses(1))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(newTestProcess("job", 1, 0, processFuncWithError)))
+	a.Error(wp.Wait())
+	a.Equal(process.Failed, processStats(t, wp.Monitor(), "p-1").Status)
+
+	a.NoError(wp.Register(newTestProcess("job", 1, 0, processFuncWithoutLog)))
+	a.NoError(wp.Wait())
+	stats := processStats(t, wp.Monitor(), "p-1")
+	a.Equal(process.Succeeded, stats.Status)
+	a.Empty(stats.History)
+	a.NoError(processError(t, wp.Monitor(), "p-1"))
+	a.NoError(wp.Close())
+}
+
+// An active process should not be registered twice
+func TestWorkerPool_RegisterActive(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Register(newTestProcess("job", 1, time.Minute, processFuncWithoutLog)))
+	a.ErrorIs(wp.Register(newTestProcess("job", 1, 0, processFuncWithoutLog)), ErrProcessActive)
+	a.ErrorIs(wp.RegisterBatch(
+		newTestProcess("a", 2, 0, processFuncWithoutLog),
+		newTestProcess("b", 2, 0, processFuncWithoutLog),
+	), ErrDuplicatePID)
+	_, ok := wp.Monitor().ProcessStats("p-2")
+	a.False(ok)
+	a.Equal(time.Minute, processStats(t, wp.Monitor(), "p-1").Process.(mockProcess).sleepTime)
+}
+
+// Concurrent registrations of an id should accept only one process
+func TestWorkerPool_RegisterActiveConcurrent(t *testing.T) {
+	a := assert.New(t)
+	for _, opts := range [][]PoolOption{{WithWorkerCount(1)}, {WithWorkerCount(1), WithDeduplication()}} {
+		wp := NewPool(opts...)
+		for i := 0; i < 50; i++ {
+			gate := make(chan struct{})
+			errs := make(chan error, 8)
+			for g := 0; g < 8; g++ {
+				go func() {
+					<-gate
+					errs <- wp.Register(newTestProcess("job", i, time.Minute, processFuncWithoutLog))
+				}()
+			}
+			close(gate)
+
+			accepted := 0
+			for g := 0; g < 8; g++ {
+				if err := <-errs; err == nil {
+					accepted++
+				} else {
+					a.ErrorIs(err, ErrProcessActive)
+				}
+			}
+			if wp.(*workerPool).config.Deduplication {
+				a.Equal(8, accepted)
+				a.Len(processStats(t, wp.Monitor(), PID("p-"+strconv.Itoa(i))).History, 7)
+			} else {
+				a.Equal(1, accepted)
+			}
+		}
+		a.Equal(int64(50), wp.Stats().TotalRegistered)
+	}
+}
+
+// WithPreserveHistory should keep the stats of the previous runs
+func TestWithPreserveHistory(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+	for i := 0; i < 3; i++ {
+		a.NoError(wp.Register(WithPreserveHistory(newTestProcess("job", 1, 0, processFuncWithoutLog))))
+		_ = wp.Wait()
+	}
+
+	stats := processStats(t, wp.Monitor(), "p-1")
+	a.Equal(process.Succeeded, stats.Status)
+	a.Len(stats.History, 2)
+	for _, run := range stats.History {
+		a.Equal(process.Succeeded, run.Status)
+		a.Empty(run.History)
+		a.False(run.FinishedAt.After(stats.StartedAt))
+	}
+	a.NoError(wp.Close())
+}
+
+// WithDeduplication should skip a process whose id is active
+func TestWithDeduplication(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithDeduplication())
+	a.NoError(wp.Register(newTestProcess("job", 1, 100*time.Millisecond, processFuncWithoutLog)))
+	a.NoError(wp.Register(newTestProcess("job", 1, time.Minute, processFuncWithoutLog)))
+	a.NoError(wp.RegisterBatch(
+		newTestProcess("job", 1, time.Minute, processFuncWithoutLog),
+		newTestProcess("other", 2, 0, processFuncWithoutLog),
+	))
+	a.Equal(int64(2), wp.Stats().TotalRegistered)
+
+	stats := processStats(t, wp.Monitor(), "p-1")
+	a.Equal(process.Waiting, stats.Status)
+	a.Len(stats.History, 2)
+	for _, skipped := range stats.History {
+		a.Equal(process.Skipped, skipped.Status)
+		a.Equal(time.Minute, skipped.Process.(mockProcess).sleepTime)
+	}
+
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Wait())
+	stats = processStats(t, wp.Monitor(), "p-1")
+	a.Equal(process.Succeeded, stats.Status)
+	a.Len(stats.History, 2)
+
+	// A finished id runs again and its skipped processes are gone.
+	a.NoError(wp.Register(newTestProcess("job", 1, 0, processFuncWithoutLog)))
+	a.NoError(wp.Wait())
+	a.Empty(processStats(t, wp.Monitor(), "p-1").History)
+	a.NoError(wp.Close())
+}
@@ -0,0 +1,121 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/worker"
+)
+
+// idleTimer returns a timer that fires when the worker has been idle for the
+// idle worker timeout since idleSince, or nil if the workers never sleep.
+func (w *workerPool) idleTimer(idleSince time.Time) *time.Timer {
+	if w.config.IdleWorkerTimeout <= 0 {
+		return nil
+	}
+
+	return time.NewTimer(w.config.IdleWorkerTimeout - time.Since(idleSince))
+}
+
+// sleep retires the idle worker until wake starts it again under the same
+// name. It returns false if the worker must keep running, because it has
+// been retired, the queue has work, or the pool is down to its minimum
+// number of workers.
+func (w *workerPool) sleep(wn WorkerName, control *workerControl) bool {
+	w.workersMutex.Lock()
+	defer w.workersMutex.Unlock()
+
+	// A process that is published after this check finds the worker
+	// sleeping and wakes it up.
+	if w.controls[wn] != control || !w.queue.isEmpty() || len(w.workers) <= w.config.MinWorkers {
+		return false
+	}
+
+	close(control.quit)
+	delete(w.controls, wn)
+	w.sleeping[wn] = control.exited
+	workers := make([]WorkerName, 0, len(w.workers))
+	for _, name := range w.workers {
+		if name != wn {
+			workers = append(workers, name)
+		}
+	}
+	w.workers = workers
+	w.log(levelDebug, "worker is sleeping", Field{"worker", wn})
+
+	return true
+}
+
+// wake starts as many sleeping workers as needed to run the waiting
+// processes, next to the idle workers.
+func (w *workerPool) wake() {
+	if w.config.IdleWorkerTimeout <= 0 {
+		return
+	}
+
+	w.workersMutex.Lock()
+	defer w.workersMutex.Unlock()
+
+	need := int(atomic.LoadInt64(&w.counters.waiting))
+	for _, wn := range w.workers {
+		if w.workersStats.get(wn) == worker.Waiting {
+			need--
+		}
+	}
+
+	names := w.sleepingWorkers()
+	if need < 0 {
+		need = 0
+	}
+	if need < len(names) {
+		names = names[:need]
+	}
+	w.wakeLocked(names...)
+}
+
+// wakeWorker starts the worker if it is sleeping.
+func (w *workerPool) wakeWorker(name WorkerName) {
+	w.workersMutex.Lock()
+	defer w.workersMutex.Unlock()
+
+	if _, ok := w.sleeping[name]; ok {
+		w.wakeLocked(name)
+	}
+}
+
+// sleepingWorkers returns the names of the sleeping workers in order. The
+// caller must hold the workers mutex.
+func (w *workerPool) sleepingWorkers() []WorkerName {
+	names := make([]WorkerName, 0, len(w.sleeping))
+	for wn := range w.sleeping {
+		names = append(names, wn)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+
+	return names
+}
+
+// wakeLocked starts the given sleeping workers again, if the pool is open.
+// The caller must hold the workers mutex.
+func (w *workerPool) wakeLocked(names ...WorkerName) {
+	if len(names) == 0 || !w.PoolStatus().IsOpen() {
+		return
+	}
+
+	for _, wn := range names {
+		// The worker goroutine is returning, wait for it so the stats of
+		// the new one are not deleted by the old one.
+		<-w.sleeping[wn]
+		delete(w.sleeping, wn)
+		w.startWorker(wn)
+	}
+	w.log(levelDebug, "workers have been woken up", Field{"workers", len(names)})
+	w.notify()
+}
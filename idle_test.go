@@ -0,0 +1,91 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// Idle workers should sleep and wake up under the same names for new work
+func TestWithIdleWorkerTimeout(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2), WithIdleWorkerTimeout(50*time.Millisecond))
+	a.NoError(wp.Start(context.Background()))
+	a.Len(wp.Monitor().WorkerList(), 2)
+
+	a.Eventually(func() bool {
+		return len(wp.Monitor().WorkerList()) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	a.NoError(wp.Register(createProcess(4, 1, 10*time.Millisecond, processFuncWithoutLog)...))
+	a.ElementsMatch([]WorkerName{"W0", "W1"}, wp.Monitor().WorkerList())
+	a.Eventually(func() bool {
+		return processStats(t, wp.Monitor(), "p-13").Status == process.Succeeded
+	}, time.Second, 10*time.Millisecond)
+
+	a.Eventually(func() bool {
+		return len(wp.Monitor().WorkerList()) == 0
+	}, time.Second, 10*time.Millisecond)
+	a.NoError(wp.Close())
+}
+
+// Process pinned to a sleeping worker should wake it up
+func TestWithIdleWorkerTimeout_Pinned(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2), WithIdleWorkerTimeout(50*time.Millisecond))
+	a.NoError(wp.Start(context.Background()))
+	a.Eventually(func() bool {
+		return len(wp.Monitor().WorkerList()) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	p := newTestProcess("pinned", 1, 10*time.Millisecond, processFuncWithoutLog)
+	a.NoError(wp.Register(WithWorker(p, "W1")))
+	a.Eventually(func() bool {
+		return processStats(t, wp.Monitor(), "p-1").Status == process.Succeeded
+	}, time.Second, 10*time.Millisecond)
+	a.Equal(WorkerName("W1"), processStats(t, wp.Monitor(), "p-1").WorkerName)
+	a.NoError(wp.Close())
+}
+
+// Workers should keep running without an idle worker timeout
+func TestWithIdleWorkerTimeout_Disabled(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Start(context.Background()))
+	time.Sleep(100 * time.Millisecond)
+	a.Len(wp.Monitor().WorkerList(), 2)
+	a.NoError(wp.Close())
+}
+
+// Workers should not sleep below the minimum and should wake up for the load
+func TestWithMinWorkers(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(4), WithIdleWorkerTimeout(50*time.Millisecond), WithMinWorkers(1))
+	a.NoError(wp.Start(context.Background()))
+	a.Eventually(func() bool {
+		return len(wp.Monitor().WorkerList()) == 1
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	a.Len(wp.Monitor().WorkerList(), 1)
+
+	// One more process than the idle worker wakes up one worker.
+	a.NoError(wp.Register(createProcess(2, 1, 200*time.Millisecond, processFuncWithoutLog)...))
+	a.Len(wp.Monitor().WorkerList(), 2)
+
+	a.NoError(wp.Register(createProcess(6, 2, 200*time.Millisecond, processFuncWithoutLog)...))
+	a.Len(wp.Monitor().WorkerList(), 4)
+	a.NoError(wp.Wait())
+	a.Equal(int64(8), wp.Stats().TotalSucceeded)
+
+	a.Eventually(func() bool {
+		return len(wp.Monitor().WorkerList()) == 1
+	}, time.Second, 10*time.Millisecond)
+	a.NoError(wp.Close())
+}
@@ -0,0 +1,103 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// isolationGracePeriod is the time that the lingering goroutines of an
+	// isolated process have to exit after they are cancelled.
+	isolationGracePeriod = 500 * time.Millisecond
+)
+
+type (
+	// goroutineGroup tracks the goroutines that a process starts with Go.
+	goroutineGroup struct {
+		wg      sync.WaitGroup
+		running int64
+	}
+
+	// goroutineGroupKey is the context key of the goroutine group.
+	goroutineGroupKey struct{}
+)
+
+// Go runs fn in a new goroutine on behalf of the process that owns ctx. If
+// the pool has process isolation, the goroutine belongs to the process: when
+// Start returns, the context that is passed to fn is cancelled and the worker
+// waits for fn to return before the process is finished. Without isolation,
+// Go is the same as the go statement.
+func Go(ctx context.Context, fn func(ctx context.Context)) {
+	g, ok := ctx.Value(goroutineGroupKey{}).(*goroutineGroup)
+	if !ok {
+		go fn(ctx)
+		return
+	}
+
+	g.wg.Add(1)
+	atomic.AddInt64(&g.running, 1)
+	go func() {
+		defer func() {
+			atomic.AddInt64(&g.running, -1)
+			g.wg.Done()
+		}()
+		fn(ctx)
+	}()
+}
+
+// wait waits for the goroutines of the group to return. It returns false if
+// they are still running after timeout.
+func (g *goroutineGroup) wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// start runs the process. With process isolation, the process runs with its
+// own goroutine group, and the goroutines that outlive Start are cancelled.
+// With a memory limit, the limit is set while the process is running, once
+// the processes that run with another limit are finished.
+func (w *workerPool) start(ctx context.Context, p Process) error {
+	if w.config.ProcessMemoryLimit > 0 {
+		restore, err := w.limitMemory(ctx)
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+
+	if !w.config.ProcessIsolation {
+		return p.Start(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	g := new(goroutineGroup)
+	err := p.Start(context.WithValue(ctx, goroutineGroupKey{}, g))
+	cancel()
+
+	if n := atomic.LoadInt64(&g.running); n > 0 {
+		w.log(levelWarn, "process left goroutines running after it returned",
+			Field{"name", w.processName(p)}, Field{"pid", p.PID()}, Field{"goroutines", n})
+		if !g.wait(isolationGracePeriod) {
+			w.log(levelError, "process has goroutines that ignore the cancellation",
+				Field{"name", w.processName(p)}, Field{"pid", p.PID()}, Field{"goroutines", atomic.LoadInt64(&g.running)})
+		}
+	}
+
+	return err
+}
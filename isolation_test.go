@@ -0,0 +1,49 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// Goroutines that outlive Start should be cancelled with process isolation
+func TestWithProcessIsolation(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithProcessIsolation())
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	var returnedAt time.Time
+	cancelledAt := make(chan time.Time, 1)
+	wp.Register(newTestProcess("leaky", 1, 0, func(ctx context.Context, pid PID, duration time.Duration) error {
+		Go(ctx, func(ctx context.Context) {
+			select {
+			case <-time.After(10 * time.Second):
+			case <-ctx.Done():
+			}
+			cancelledAt <- time.Now()
+		})
+		returnedAt = time.Now()
+		return nil
+	}))
+	wp.Wait()
+
+	select {
+	case at := <-cancelledAt:
+		a.Less(at.Sub(returnedAt), 500*time.Millisecond)
+	default:
+		a.Fail("goroutine is still running after the process finished")
+	}
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-1").Status)
+
+	err = wp.Close()
+	a.NoError(err)
+}
@@ -0,0 +1,96 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// errorFieldKey is the key of the fields that are made by ErrorField.
+const errorFieldKey = "error"
+
+type (
+	// NoopLogger is a Logger that discards every message. A pool without
+	// any logger behaves as if it had a NoopLogger.
+	NoopLogger struct{}
+
+	// StdLogger is a Logger that writes each message as one line with a
+	// standard library logger. The line holds the level, the message, and
+	// the fields as key=value pairs.
+	StdLogger struct {
+		logger *log.Logger
+	}
+)
+
+// StringField makes a field with a string value.
+func StringField(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// IntField makes a field with an integer value.
+func IntField(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// ErrorField makes a field with the error under the "error" key.
+func ErrorField(err error) Field {
+	return Field{Key: errorFieldKey, Value: err}
+}
+
+// Debug discards the message.
+func (NoopLogger) Debug(string, ...Field) {}
+
+// Info discards the message.
+func (NoopLogger) Info(string, ...Field) {}
+
+// Warn discards the message.
+func (NoopLogger) Warn(string, ...Field) {}
+
+// Error discards the message.
+func (NoopLogger) Error(string, ...Field) {}
+
+// NewStdLogger makes a new instance of StdLogger that writes with l. A nil l
+// means the standard logger of the log package.
+func NewStdLogger(l *log.Logger) *StdLogger {
+	if l == nil {
+		l = log.Default()
+	}
+
+	return &StdLogger{logger: l}
+}
+
+// Debug writes the message with the DEBUG level.
+func (s *StdLogger) Debug(msg string, fields ...Field) {
+	s.write("DEBUG", msg, fields)
+}
+
+// Info writes the message with the INFO level.
+func (s *StdLogger) Info(msg string, fields ...Field) {
+	s.write("INFO", msg, fields)
+}
+
+// Warn writes the message with the WARN level.
+func (s *StdLogger) Warn(msg string, fields ...Field) {
+	s.write("WARN", msg, fields)
+}
+
+// Error writes the message with the ERROR level.
+func (s *StdLogger) Error(msg string, fields ...Field) {
+	s.write("ERROR", msg, fields)
+}
+
+// write formats the message and its fields as one line.
+func (s *StdLogger) write(level, msg string, fields []Field) {
+	var b strings.Builder
+	b.WriteString(level)
+	b.WriteString(" ")
+	b.WriteString(msg)
+	for _, f := range fields {
+		fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+	}
+	s.logger.Println(b.String())
+}
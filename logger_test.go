@@ -0,0 +1,62 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Field helpers should make key-value pairs
+func TestFieldHelpers(t *testing.T) {
+	a := assert.New(t)
+	err := errors.New("boom")
+	a.Equal(Field{"name", "job"}, StringField("name", "job"))
+	a.Equal(Field{"attempt", 2}, IntField("attempt", 2))
+	a.Equal(Field{"error", err}, ErrorField(err))
+}
+
+// StdLogger should write one line per message with its level and fields
+func TestStdLogger(t *testing.T) {
+	a := assert.New(t)
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0))
+	logger.Debug("debug")
+	logger.Info("info", StringField("name", "job"))
+	logger.Warn("warn", IntField("attempt", 2))
+	logger.Error("error", ErrorField(errors.New("boom")))
+
+	a.Equal("DEBUG debug\nINFO info name=job\nWARN warn attempt=2\nERROR error error=boom\n", buf.String())
+}
+
+// Pool should send its internal events to the logger
+func TestWithLogger_Events(t *testing.T) {
+	a := assert.New(t)
+	var buf bytes.Buffer
+	wp := NewPool(WithWorkerCount(1), WithName("orders"), WithLogger(NewStdLogger(log.New(&buf, "", 0))))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(newTestProcess("job", 1, 10*time.Millisecond, processFuncWithoutLog)))
+	a.NoError(wp.Wait())
+	a.NoError(wp.Close())
+
+	a.Contains(buf.String(), "DEBUG worker has started pool=orders worker=W0\n")
+	a.Contains(buf.String(), "DEBUG process has been dispatched pool=orders name=job pid=p-1 worker=W0 attempt=1\n")
+	a.Contains(buf.String(), "DEBUG worker has stopped pool=orders worker=W0\n")
+}
+
+// NoopLogger should discard the messages
+func TestNoopLogger(t *testing.T) {
+	var logger Logger = NoopLogger{}
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+}
@@ -37,13 +37,97 @@ type (
 	}
 
 	// processContext represents a cancellation context by holding a context and
-	// a cancel function.
+	// a cancel function. The done channel is closed when the process reaches a
+	// final state.
 	processContext struct {
 		ctx    context.Context
 		cancel context.CancelFunc
+		done   chan struct{}
+
+		mutex    sync.Mutex
+		reason   error
+		skipped  []ProcessStats
+		running  bool
+		detached bool
 	}
 )
 
+// begin records that an attempt of the process is running on a worker.
+func (pc *processContext) begin() {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	pc.running = true
+}
+
+// end records that the attempt has returned. It returns false if the pool
+// has given up on the attempt meanwhile, so the worker must not record its
+// outcome.
+func (pc *processContext) end() bool {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	pc.running = false
+
+	return !pc.detached
+}
+
+// detach gives up on the running attempt of the process, so the pool can
+// finish the process without waiting for it. It returns false if no attempt
+// is running.
+func (pc *processContext) detach() bool {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	if !pc.running || pc.detached {
+		return false
+	}
+	pc.detached = true
+
+	return true
+}
+
+// kill cancels the process and records the reason of the kill, unless the
+// process has already been cancelled.
+func (pc *processContext) kill(reason error) {
+	pc.mutex.Lock()
+	if pc.ctx.Err() == nil && pc.reason == nil {
+		pc.reason = reason
+	}
+	pc.mutex.Unlock()
+
+	pc.cancel()
+}
+
+// killReason returns the reason of the kill, or nil.
+func (pc *processContext) killReason() error {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	return pc.reason
+}
+
+// skip records a process with the same id that has been skipped while the
+// process is active.
+func (pc *processContext) skip(stats ProcessStats) {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	pc.skipped = append(pc.skipped, stats)
+}
+
+// withSkipped returns the history followed by the skipped processes.
+func (pc *processContext) withSkipped(history []ProcessStats) []ProcessStats {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	if len(pc.skipped) == 0 {
+		return history
+	}
+
+	return append(history[:len(history):len(history)], pc.skipped...)
+}
+
 func (c *controlPanelMap) put(pid PID, pc *processContext) {
 	c.internal.Store(pid, pc)
 }
@@ -54,16 +138,41 @@ func (c *controlPanelMap) get(pid PID) *processContext {
 	return cancel
 }
 
+func (c *controlPanelMap) delete(pid PID) {
+	c.internal.Delete(pid)
+}
+
 func (c *workerStatsMap) put(name WorkerName, status worker.Status) {
 	c.internal.Store(name, status)
 }
 
+func (c *workerStatsMap) delete(name WorkerName) {
+	c.internal.Delete(name)
+}
+
 func (c *workerStatsMap) get(name WorkerName) worker.Status {
 	in, _ := c.internal.Load(name)
 	status, _ := in.(worker.Status)
 	return status
 }
 
+// lookup returns the status of the worker, or false if there is no such
+// worker.
+func (c *workerStatsMap) lookup(name WorkerName) (worker.Status, bool) {
+	in, ok := c.internal.Load(name)
+	status, _ := in.(worker.Status)
+	return status, ok
+}
+
+func (c *workerStatsMap) each(fn func(name WorkerName, status worker.Status)) {
+	c.internal.Range(func(key, value interface{}) bool {
+		name, _ := key.(WorkerName)
+		status, _ := value.(worker.Status)
+		fn(name, status)
+		return true
+	})
+}
+
 func (c *processStatusMap) put(pid PID, stats ProcessStats) {
 	c.internal.Store(pid, stats)
 }
@@ -73,3 +182,16 @@ func (c *processStatusMap) get(pid PID) ProcessStats {
 	stats, _ := in.(ProcessStats)
 	return stats
 }
+
+func (c *processStatusMap) delete(pid PID) {
+	c.internal.Delete(pid)
+}
+
+func (c *processStatusMap) each(fn func(pid PID, stats ProcessStats)) {
+	c.internal.Range(func(key, value interface{}) bool {
+		pid, _ := key.(PID)
+		stats, _ := value.(ProcessStats)
+		fn(pid, stats)
+		return true
+	})
+}
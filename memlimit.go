@@ -0,0 +1,83 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+)
+
+var (
+	// memoryLimitMutex guards the memory limit overrides, because the memory
+	// limit is a global setting.
+	memoryLimitMutex sync.Mutex
+
+	// memoryLimitActive is the number of running processes that override
+	// the memory limit.
+	memoryLimitActive int
+
+	// memoryLimitWaiting is the number of processes that wait for the
+	// running ones to finish because they need another limit.
+	memoryLimitWaiting int
+
+	// memoryLimitCurrent is the limit of the running processes.
+	memoryLimitCurrent int64
+
+	// memoryLimitOriginal is the memory limit before the first running
+	// override.
+	memoryLimitOriginal int64
+
+	// memoryLimitReleased is closed when the last running process that
+	// overrides the memory limit finishes.
+	memoryLimitReleased = make(chan struct{})
+)
+
+// limitMemory sets the memory limit of the pool and returns a function that
+// restores the original limit. The processes that need the same limit run
+// concurrently, while a process that needs another limit waits until none of
+// them is running, so no two processes run with different limits. Once a
+// process waits, the next ones wait too, so it is not starved. It returns
+// the context error if ctx is done while the process waits.
+func (w *workerPool) limitMemory(ctx context.Context) (func(), error) {
+	limit := w.config.ProcessMemoryLimit
+	memoryLimitMutex.Lock()
+	if memoryLimitActive > 0 && (memoryLimitCurrent != limit || memoryLimitWaiting > 0) {
+		memoryLimitWaiting++
+		for memoryLimitActive > 0 {
+			released := memoryLimitReleased
+			memoryLimitMutex.Unlock()
+			select {
+			case <-released:
+			case <-ctx.Done():
+				memoryLimitMutex.Lock()
+				memoryLimitWaiting--
+				memoryLimitMutex.Unlock()
+				return nil, ctx.Err()
+			}
+			memoryLimitMutex.Lock()
+		}
+		memoryLimitWaiting--
+	}
+
+	if memoryLimitActive == 0 {
+		memoryLimitOriginal = debug.SetMemoryLimit(limit)
+		memoryLimitCurrent = limit
+	}
+	memoryLimitActive++
+	memoryLimitMutex.Unlock()
+
+	return func() {
+		memoryLimitMutex.Lock()
+		defer memoryLimitMutex.Unlock()
+
+		memoryLimitActive--
+		if memoryLimitActive == 0 {
+			debug.SetMemoryLimit(memoryLimitOriginal)
+			close(memoryLimitReleased)
+			memoryLimitReleased = make(chan struct{})
+		}
+	}, nil
+}
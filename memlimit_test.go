@@ -0,0 +1,138 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// allocate returns a process function that keeps size bytes alive and
+// records the number of garbage collections while it is running.
+func allocate(size int, gcs *uint32) pTestFunc {
+	return func(ctx context.Context, pid PID, duration time.Duration) error {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		chunks := make([][]byte, 0, size>>20)
+		for i := 0; i < size>>20; i++ {
+			chunk := make([]byte, 1<<20)
+			chunk[0] = 1
+			chunks = append(chunks, chunk)
+		}
+		runtime.ReadMemStats(&after)
+		*gcs = after.NumGC - before.NumGC
+		runtime.KeepAlive(chunks)
+		return nil
+	}
+}
+
+// Memory limit should be set while the process is running and restored
+func TestWithPerProcessMemoryLimit(t *testing.T) {
+	a := assert.New(t)
+	original := debug.SetMemoryLimit(-1)
+	wp := NewPool(WithWorkerCount(2), WithPerProcessMemoryLimit(50<<20))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	var limit int64
+	wp.Register(newTestProcess("limit", 1, 0, func(ctx context.Context, pid PID, duration time.Duration) error {
+		limit = debug.SetMemoryLimit(-1)
+		return nil
+	}))
+	wp.Wait()
+	a.Equal(int64(50<<20), limit)
+	a.Equal(original, debug.SetMemoryLimit(-1))
+
+	var small, large uint32
+	wp.Register(newTestProcess("small", 2, 0, allocate(40<<20, &small)))
+	wp.Wait()
+	wp.Register(newTestProcess("large", 3, 0, allocate(60<<20, &large)))
+	wp.Wait()
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-2").Status)
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-3").Status)
+	a.Greater(large, small)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Processes with the same memory limit should run concurrently and restore
+// the limit after the last one
+func TestWithPerProcessMemoryLimit_Concurrent(t *testing.T) {
+	a := assert.New(t)
+	original := debug.SetMemoryLimit(-1)
+	wp := NewPool(WithWorkerCount(2), WithPerProcessMemoryLimit(50<<20))
+	a.NoError(wp.Start(context.Background()))
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	pFunc := func(ctx context.Context, pid PID, duration time.Duration) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+	wp.Register(newTestProcess("limit", 1, 0, pFunc), newTestProcess("limit", 2, 0, pFunc))
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			a.Fail("the processes did not run concurrently")
+		}
+	}
+	a.Equal(int64(50<<20), debug.SetMemoryLimit(-1))
+	close(release)
+	wp.Wait()
+	a.Equal(original, debug.SetMemoryLimit(-1))
+
+	a.NoError(wp.Close())
+}
+
+// A process with another memory limit should wait for the running processes
+func TestWithPerProcessMemoryLimit_Different(t *testing.T) {
+	a := assert.New(t)
+	original := debug.SetMemoryLimit(-1)
+	small := NewPool(WithWorkerCount(1), WithPerProcessMemoryLimit(50<<20))
+	large := NewPool(WithWorkerCount(1), WithPerProcessMemoryLimit(60<<20))
+	a.NoError(small.Start(context.Background()))
+	a.NoError(large.Start(context.Background()))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	a.NoError(small.Register(newTestProcess("small", 1, 0, func(ctx context.Context, pid PID, duration time.Duration) error {
+		close(started)
+		<-release
+		return nil
+	})))
+	<-started
+
+	limits := make(chan int64, 1)
+	a.NoError(large.Register(newTestProcess("large", 1, 0, func(ctx context.Context, pid PID, duration time.Duration) error {
+		limits <- debug.SetMemoryLimit(-1)
+		return nil
+	})))
+	select {
+	case limit := <-limits:
+		a.Fail("the processes ran with different limits")
+		limits <- limit
+	case <-time.After(50 * time.Millisecond):
+	}
+	a.Equal(int64(50<<20), debug.SetMemoryLimit(-1))
+
+	close(release)
+	a.Equal(int64(60<<20), <-limits)
+	large.Wait()
+	small.Wait()
+	a.Equal(original, debug.SetMemoryLimit(-1))
+
+	a.NoError(small.Close())
+	a.NoError(large.Close())
+}
@@ -0,0 +1,182 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+// Package metrics exposes the metrics of gowl pools to Prometheus. It is a
+// separate package, so the core package does not depend on the Prometheus
+// client.
+package metrics
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/hamed-yousefi/gowl"
+	"github.com/hamed-yousefi/gowl/plugin/noop"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+const (
+	// poolLabel is the label that holds the pool name.
+	poolLabel = "pool"
+
+	// statusLabel is the label that holds the final process status.
+	statusLabel = "status"
+
+	// nameLabel is the label that holds the sanitized process name.
+	nameLabel = "name"
+)
+
+// Collector is a prometheus.Collector that reads the metrics of a set of
+// named pools on each scrape.
+type Collector struct {
+	mutex sync.RWMutex
+	pools map[string]gowl.Pool
+
+	queueDepth      *prometheus.Desc
+	activeWorkers   *prometheus.Desc
+	idleWorkers     *prometheus.Desc
+	processes       *prometheus.Desc
+	averageDuration *prometheus.Desc
+}
+
+// ProcessCollector is a prometheus.Collector of the finished processes by
+// name. It is an observability plugin, so pass it to the pools with
+// gowl.WithObservabilityPlugin. The names are sanitized by the NameSanitizer
+// of each pool before they are used as labels.
+type ProcessCollector struct {
+	noop.NoopPlugin
+
+	processes *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+}
+
+var (
+	_ prometheus.Collector     = (*Collector)(nil)
+	_ prometheus.Collector     = (*ProcessCollector)(nil)
+	_ gowl.ObservabilityPlugin = (*ProcessCollector)(nil)
+)
+
+// NewCollector returns a collector whose metric names start with namespace,
+// such as "gowl". Add the pools to the collector and register it to a
+// prometheus.Registry.
+func NewCollector(namespace string) *Collector {
+	name := func(metric string) string {
+		return prometheus.BuildFQName(namespace, "", metric)
+	}
+
+	return &Collector{
+		pools: make(map[string]gowl.Pool),
+		queueDepth: prometheus.NewDesc(name("queue_depth"),
+			"Number of processes waiting to be consumed.", []string{poolLabel}, nil),
+		activeWorkers: prometheus.NewDesc(name("workers_active"),
+			"Number of workers that are running a process.", []string{poolLabel}, nil),
+		idleWorkers: prometheus.NewDesc(name("workers_idle"),
+			"Number of workers that are waiting for a process.", []string{poolLabel}, nil),
+		processes: prometheus.NewDesc(name("processes_total"),
+			"Number of finished processes by final status.", []string{poolLabel, statusLabel}, nil),
+		averageDuration: prometheus.NewDesc(name("process_duration_average_seconds"),
+			"Average running time of the finished processes.", []string{poolLabel}, nil),
+	}
+}
+
+// Add adds the pool to the collector under name. It returns an error if the
+// name is already used.
+func (c *Collector) Add(name string, pool gowl.Pool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.pools[name]; ok {
+		return errors.New("pool " + name + " is already collected")
+	}
+	c.pools[name] = pool
+
+	return nil
+}
+
+// Remove removes the pool with the given name from the collector.
+func (c *Collector) Remove(name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.pools, name)
+}
+
+// Describe sends the descriptors of the metrics of the collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.queueDepth
+	ch <- c.activeWorkers
+	ch <- c.idleWorkers
+	ch <- c.processes
+	ch <- c.averageDuration
+}
+
+// Collect sends the current metrics of every pool.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.RLock()
+	names := make([]string, 0, len(c.pools))
+	for name := range c.pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pools := make([]gowl.Pool, 0, len(names))
+	for _, name := range names {
+		pools = append(pools, c.pools[name])
+	}
+	c.mutex.RUnlock()
+
+	for i, name := range names {
+		m := pools[i].Monitor().Metrics()
+		ch <- prometheus.MustNewConstMetric(c.queueDepth, prometheus.GaugeValue, float64(m.QueueDepth), name)
+		ch <- prometheus.MustNewConstMetric(c.activeWorkers, prometheus.GaugeValue, float64(m.ActiveWorkers), name)
+		ch <- prometheus.MustNewConstMetric(c.idleWorkers, prometheus.GaugeValue, float64(m.IdleWorkers), name)
+		ch <- prometheus.MustNewConstMetric(c.processes, prometheus.CounterValue, float64(m.TotalSucceeded), name, "succeeded")
+		ch <- prometheus.MustNewConstMetric(c.processes, prometheus.CounterValue, float64(m.TotalFailed), name, "failed")
+		ch <- prometheus.MustNewConstMetric(c.processes, prometheus.CounterValue, float64(m.TotalKilled), name, "killed")
+		ch <- prometheus.MustNewConstMetric(c.processes, prometheus.CounterValue, float64(m.TotalCancelled), name, "cancelled")
+		ch <- prometheus.MustNewConstMetric(c.averageDuration, prometheus.GaugeValue, m.AverageDuration.Seconds(), name)
+	}
+}
+
+// NewProcessCollector returns a process collector whose metric names start
+// with namespace, such as "gowl".
+func NewProcessCollector(namespace string) *ProcessCollector {
+	return &ProcessCollector{
+		processes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "process_runs_total",
+			Help:      "Number of finished processes by name and final status.",
+		}, []string{nameLabel, statusLabel}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "process_duration_seconds",
+			Help:      "Running time of the finished processes by name.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{nameLabel}),
+	}
+}
+
+// ObserveProcess counts the finished process and records its running time.
+func (c *ProcessCollector) ObserveProcess(name string, status process.Status, _, runTime time.Duration) {
+	c.processes.WithLabelValues(name, strings.ToLower(status.String())).Inc()
+	if runTime > 0 {
+		c.duration.WithLabelValues(name).Observe(runTime.Seconds())
+	}
+}
+
+// Describe sends the descriptors of the metrics of the collector.
+func (c *ProcessCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.processes.Describe(ch)
+	c.duration.Describe(ch)
+}
+
+// Collect sends the metrics of the finished processes.
+func (c *ProcessCollector) Collect(ch chan<- prometheus.Metric) {
+	c.processes.Collect(ch)
+	c.duration.Collect(ch)
+}
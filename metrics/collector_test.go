@@ -0,0 +1,108 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl"
+)
+
+type testProcess struct {
+	name string
+	pid  gowl.PID
+}
+
+func (t testProcess) Start(ctx context.Context) error {
+	select {
+	case <-time.After(10 * time.Millisecond):
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func (t testProcess) Name() string {
+	return t.name
+}
+
+func (t testProcess) PID() gowl.PID {
+	return t.pid
+}
+
+// The collector should expose the metrics of each named pool
+func TestCollector(t *testing.T) {
+	a := assert.New(t)
+	orders, emails := gowl.NewPool(gowl.WithWorkerCount(2)), gowl.NewPool(gowl.WithWorkerCount(1))
+	c := NewCollector("gowl")
+	a.NoError(c.Add("orders", orders))
+	a.NoError(c.Add("emails", emails))
+	a.Error(c.Add("orders", orders))
+
+	registry := prometheus.NewPedanticRegistry()
+	a.NoError(registry.Register(c))
+
+	a.NoError(orders.Start(context.Background()))
+	a.NoError(emails.Start(context.Background()))
+	a.NoError(orders.Register(testProcess{"order", "o-1"}, testProcess{"order", "o-2"}, testProcess{"order", "o-3"}))
+	a.NoError(emails.Register(testProcess{"email", "e-1"}))
+	a.NoError(orders.Wait())
+	a.NoError(emails.Wait())
+
+	expected := `
+# HELP gowl_processes_total Number of finished processes by final status.
+# TYPE gowl_processes_total counter
+gowl_processes_total{pool="emails",status="cancelled"} 0
+gowl_processes_total{pool="emails",status="failed"} 0
+gowl_processes_total{pool="emails",status="killed"} 0
+gowl_processes_total{pool="emails",status="succeeded"} 1
+gowl_processes_total{pool="orders",status="cancelled"} 0
+gowl_processes_total{pool="orders",status="failed"} 0
+gowl_processes_total{pool="orders",status="killed"} 0
+gowl_processes_total{pool="orders",status="succeeded"} 3
+# HELP gowl_workers_idle Number of workers that are waiting for a process.
+# TYPE gowl_workers_idle gauge
+gowl_workers_idle{pool="emails"} 1
+gowl_workers_idle{pool="orders"} 2
+`
+	a.NoError(testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"gowl_processes_total", "gowl_workers_idle"))
+	a.Equal(16, testutil.CollectAndCount(c))
+
+	c.Remove("emails")
+	a.Equal(8, testutil.CollectAndCount(c))
+
+	a.NoError(orders.Close())
+	a.NoError(emails.Close())
+}
+
+// The process collector should label the metrics with the sanitized names
+func TestProcessCollector(t *testing.T) {
+	a := assert.New(t)
+	c := NewProcessCollector("gowl")
+	registry := prometheus.NewPedanticRegistry()
+	a.NoError(registry.Register(c))
+
+	wp := gowl.NewPool(gowl.WithWorkerCount(1), gowl.WithObservabilityPlugin(c))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(testProcess{"my service/operation v2.0", "p-1"}))
+	a.NoError(wp.Wait())
+
+	expected := `
+# HELP gowl_process_runs_total Number of finished processes by name and final status.
+# TYPE gowl_process_runs_total counter
+gowl_process_runs_total{name="my_service_operation_v2_0",status="succeeded"} 1
+`
+	a.NoError(testutil.GatherAndCompare(registry, strings.NewReader(expected), "gowl_process_runs_total"))
+	a.Equal(2, testutil.CollectAndCount(c))
+
+	a.NoError(wp.Close())
+}
@@ -0,0 +1,61 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// MigrateProcess moves a waiting process from source to target. The process
+// is taken out of the source queue, registered to target with the same PID,
+// and marked as Killed in the source monitor. If target rejects the process,
+// it is put back at the front of the source queue and the error of Register
+// is returned. A process that is not waiting in the source queue, such as a
+// running one, cannot be migrated.
+func MigrateProcess(pid PID, source Pool, target Pool) error {
+	src, ok := source.(*workerPool)
+	if !ok {
+		status := source.Monitor().PoolStatus()
+		return &PoolError{Op: "migrate the process", Err: ErrUnsupportedPool, CurrentStatus: status,
+			ExpectedStatus: status, PID: pid}
+	}
+
+	p, ok := src.queue.remove(pid)
+	if !ok {
+		if stats := src.processes.get(pid); stats.Process != nil {
+			return src.pidError("migrate the process", fmt.Errorf("%w: %s", ErrInvalidProcessStatus, stats.Status), pid)
+		}
+		return src.pidError("migrate the process", ErrProcessNotFound, pid)
+	}
+
+	if err := target.Register(p); err != nil {
+		src.queue.pushFront(p)
+		return err
+	}
+
+	src.migrated(p)
+
+	return nil
+}
+
+// migrated marks the process that has been taken out of the queue as Killed.
+func (w *workerPool) migrated(p Process) {
+	w.counters.dequeue(processWeight(p))
+	pc := w.controlPanel.get(p.PID())
+	pc.cancel()
+
+	stats := w.processes.get(p.PID())
+	stats.Status = process.Killed
+	stats.FinishedAt = time.Now()
+	stats.updatedAt = stats.FinishedAt
+	w.processes.put(p.PID(), stats)
+	w.counters.finish(stats.Status)
+
+	close(pc.done)
+	w.notify()
+}
@@ -0,0 +1,45 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// Migrated process should run in the target pool
+func TestMigrateProcess(t *testing.T) {
+	a := assert.New(t)
+	source := NewPool(WithWorkerCount(1))
+	target := NewPool(WithWorkerCount(1))
+	a.NoError(source.Start(context.Background()))
+	a.NoError(target.Start(context.Background()))
+
+	source.Register(createProcess(2, 1, 100*time.Millisecond, processFuncWithoutLog)...)
+	time.Sleep(20 * time.Millisecond)
+
+	err := MigrateProcess("p-11", source, target)
+	a.ErrorIs(err, ErrInvalidProcessStatus)
+	var poolErr *PoolError
+	a.ErrorAs(err, &poolErr)
+	a.Equal(PID("p-11"), poolErr.PID)
+	a.ErrorIs(MigrateProcess("p-0", source, target), ErrProcessNotFound)
+	a.NoError(MigrateProcess("p-12", source, target))
+	source.Wait()
+	target.Wait()
+
+	a.Equal(process.Succeeded, processStats(t, source.Monitor(), "p-11").Status)
+	a.Equal(process.Killed, processStats(t, source.Monitor(), "p-12").Status)
+	a.Equal(process.Succeeded, processStats(t, target.Monitor(), "p-12").Status)
+	a.Equal(int64(1), source.Stats().TotalKilled)
+
+	a.NoError(source.Close())
+	a.NoError(target.Close())
+}
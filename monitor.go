@@ -0,0 +1,131 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+)
+
+type (
+	// MonitorDelta holds the processes that changed since a point in time,
+	// together with the current pool-level stats. It lets dashboards poll the
+	// monitor without reading every process stats on each tick.
+	MonitorDelta struct {
+		// Since is the time that the delta is calculated from.
+		Since time.Time
+
+		// PoolStatus is the current pool status.
+		PoolStatus pool.Status
+
+		// PoolStats is the current pool stats snapshot.
+		PoolStats PoolStats
+
+		// Processes is the list of processes whose status changed after Since.
+		Processes []ProcessStats
+	}
+)
+
+// Delta returns the stats of the processes whose status changed after since,
+// plus the current pool status and stats.
+func (w *workerPool) Delta(since time.Time) MonitorDelta {
+	delta := MonitorDelta{
+		Since:      since,
+		PoolStatus: w.PoolStatus(),
+		PoolStats:  w.Stats(),
+		Processes:  make([]ProcessStats, 0),
+	}
+
+	w.processes.each(func(_ PID, stats ProcessStats) {
+		if stats.updatedAt.After(since) {
+			delta.Processes = append(delta.Processes, stats)
+		}
+	})
+
+	return delta
+}
+
+// CompletedProcesses returns the stats of the processes that have reached a
+// final state.
+func (w *workerPool) CompletedProcesses() []ProcessStats {
+	completed := make([]ProcessStats, 0)
+	w.processes.each(func(_ PID, stats ProcessStats) {
+		if stats.Status.IsTerminal() && !stats.FinishedAt.IsZero() {
+			completed = append(completed, stats)
+		}
+	})
+
+	return completed
+}
+
+// Purge removes the stats of the processes that have reached a final state
+// before olderThan and returns the number of removed processes. Waiting and
+// running processes are untouched. It keeps the memory of a long-running
+// pool bounded.
+func (w *workerPool) Purge(olderThan time.Time) int {
+	return w.purge(olderThan, func(ProcessStats) bool { return true })
+}
+
+// ResetStats zeroes the process counters of the pool and removes the stats
+// of the processes that have reached a final state, to start fresh metrics
+// between two batches without restarting the pool. Waiting and running
+// processes are untouched. The counters are pool-wide, so it resets them for
+// the filtered monitors too, while a namespaced monitor only removes the
+// stats of its namespace. It returns an error if the pool is not running.
+func (w *workerPool) ResetStats() error {
+	if status := w.PoolStatus(); status != pool.Running {
+		return w.poolError("reset the stats", ErrPoolNotRunning, pool.Running)
+	}
+
+	w.purge(time.Now(), func(ProcessStats) bool { return true })
+	w.counters.reset()
+	w.workerCounters.reset()
+	w.starts.reset(time.Now())
+	w.publishStats()
+
+	return nil
+}
+
+// Metrics returns a snapshot of the pool metrics. The metrics are pool-wide,
+// the same for the filtered monitors, and they are zeroed by ResetStats. A
+// namespaced monitor counts the processes of its namespace instead.
+func (w *workerPool) Metrics() MetricsSnapshot {
+	stats := w.Stats()
+	snapshot := MetricsSnapshot{
+		QueueDepth:     stats.QueueDepth,
+		ActiveWorkers:  stats.ActiveWorkers,
+		IdleWorkers:    stats.IdleWorkers,
+		TotalSucceeded: stats.TotalSucceeded,
+		TotalFailed:    stats.TotalFailed,
+		TotalKilled:    stats.TotalKilled,
+		TotalCancelled: stats.TotalCancelled,
+		TotalDropped:   stats.TotalDropped,
+		ProcessesRun:   atomic.LoadInt64(&w.counters.ran),
+	}
+	if snapshot.ProcessesRun > 0 {
+		snapshot.AverageDuration = time.Duration(atomic.LoadInt64(&w.counters.runTime) / snapshot.ProcessesRun)
+	}
+
+	return snapshot
+}
+
+// purge removes the stats of the completed processes that finished before
+// olderThan and match the predicate.
+func (w *workerPool) purge(olderThan time.Time, match func(ProcessStats) bool) int {
+	purged := 0
+	w.processes.each(func(pid PID, stats ProcessStats) {
+		if stats.Status.IsTerminal() && !stats.FinishedAt.IsZero() && stats.FinishedAt.Before(olderThan) && match(stats) {
+			w.processes.delete(pid)
+			w.controlPanel.delete(pid)
+			w.histograms.delete(pid)
+			atomic.AddInt64(&w.counters.tracked, -1)
+			purged++
+		}
+	})
+
+	return purged
+}
@@ -0,0 +1,124 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// Delta should only return the processes that changed after the given time
+func TestMonitor_Delta(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(5))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(createProcess(5, 1, 10*time.Millisecond, processFunc)...)
+	time.Sleep(200 * time.Millisecond)
+
+	since := time.Now()
+	wp.Register(createProcess(5, 2, 10*time.Millisecond, processFunc)...)
+	time.Sleep(200 * time.Millisecond)
+
+	delta := wp.Monitor().Delta(since)
+	pids := make([]string, 0)
+	for _, stats := range delta.Processes {
+		pids = append(pids, stats.Process.PID().String())
+	}
+	sort.Strings(pids)
+	a.Equal([]string{"p-21", "p-22", "p-23", "p-24", "p-25"}, pids)
+	a.Equal(pool.Running, delta.PoolStatus)
+	a.Equal(int64(10), delta.PoolStats.TotalSucceeded)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Purge should remove the finished processes
+func TestMonitor_Purge(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(5))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	err = wp.Register(createProcess(10, 1, 10*time.Millisecond, processFunc)...)
+	a.NoError(err)
+	err = wp.Register(newTestProcess("long", 1, time.Second, processFunc))
+	a.NoError(err)
+	time.Sleep(200 * time.Millisecond)
+	a.Len(wp.Monitor().CompletedProcesses(), 10)
+
+	a.Equal(10, wp.Monitor().Purge(time.Now()))
+	a.Empty(wp.Monitor().CompletedProcesses())
+	a.Equal(process.Running, processStats(t, wp.Monitor(), "p-1").Status)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// ResetStats should start fresh counters between two batches
+func TestMonitor_ResetStats(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(5))
+	a.Error(wp.Monitor().ResetStats())
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	err = wp.Register(createProcess(10, 1, 10*time.Millisecond, processFuncWithoutLog)...)
+	a.NoError(err)
+	a.NoError(wp.Wait())
+
+	err = wp.Register(newTestProcess("long", 1, 300*time.Millisecond, processFuncWithoutLog))
+	a.NoError(err)
+	time.Sleep(50 * time.Millisecond)
+	a.NoError(wp.Monitor().ResetStats())
+	a.Empty(wp.Monitor().CompletedProcesses())
+	a.Equal(process.Running, processStats(t, wp.Monitor(), "p-1").Status)
+	a.Zero(wp.Stats().TotalRegistered)
+
+	err = wp.Register(createProcess(9, 2, 10*time.Millisecond, processFuncWithoutLog)...)
+	a.NoError(err)
+	a.NoError(wp.Wait())
+	err = wp.Close()
+	a.NoError(err)
+	a.Equal(int64(10), wp.Stats().TotalSucceeded)
+	a.Equal(int64(9), wp.Stats().TotalRegistered)
+}
+
+// Metrics should return the counters and the average process duration
+func TestMonitor_Metrics(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	err = wp.Register(createProcess(2, 1, 50*time.Millisecond, processFuncWithoutLog)...)
+	a.NoError(err)
+	err = wp.Register(newTestProcess("fail", 1, 10*time.Millisecond, processFuncWithError))
+	a.NoError(err)
+	err = wp.Register(newTestProcess("long", 2, time.Second, processFuncWithoutLog))
+	a.NoError(err)
+	time.Sleep(150 * time.Millisecond)
+
+	metrics := wp.Monitor().Metrics()
+	a.Zero(metrics.QueueDepth)
+	a.Equal(1, metrics.ActiveWorkers)
+	a.Equal(1, metrics.IdleWorkers)
+	a.Equal(int64(2), metrics.TotalSucceeded)
+	a.Equal(int64(1), metrics.TotalFailed)
+	a.Zero(metrics.TotalKilled)
+	a.Equal(int64(3), metrics.ProcessesRun)
+	a.InDelta(float64(110*time.Millisecond/3), float64(metrics.AverageDuration), float64(15*time.Millisecond))
+
+	wp.Kill("p-2")
+	a.Error(wp.Wait())
+	a.Equal(int64(1), wp.Monitor().Metrics().TotalKilled)
+	err = wp.Close()
+	a.NoError(err)
+}
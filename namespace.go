@@ -0,0 +1,506 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/circuit"
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+const (
+	// namespaceSeparator separates the namespace from the process id and
+	// name.
+	namespaceSeparator = "/"
+)
+
+type (
+	// namespacedProcess is a process that has been registered through a
+	// namespace. Its id and name are prefixed with the namespace.
+	namespacedProcess struct {
+		Process
+		ns string
+	}
+
+	// namespacePool is a view of a pool that isolates the processes of a
+	// namespace. The workers and the pool lifecycle are shared with the
+	// underlying pool.
+	namespacePool struct {
+		*workerPool
+		ns string
+	}
+
+	// namespaceMonitor is a view of the pool monitor that only exposes the
+	// processes of a namespace.
+	namespaceMonitor struct {
+		*workerPool
+		ns       string
+		patterns []*regexp.Regexp
+	}
+
+	// namespaceHandle submits processes of a namespace to a specific worker.
+	namespaceHandle struct {
+		WorkerHandle
+		ns string
+	}
+)
+
+// PID returns the process id prefixed with the namespace.
+func (p namespacedProcess) PID() PID {
+	return PID(p.ns+namespaceSeparator) + p.Process.PID()
+}
+
+// Name returns the process name prefixed with the namespace.
+func (p namespacedProcess) Name() string {
+	return p.ns + namespaceSeparator + p.Process.Name()
+}
+
+// unwrap returns the wrapped process.
+func (p namespacedProcess) unwrap() Process {
+	return p.Process
+}
+
+// Namespace returns a view of the pool for the namespace ns. The processes
+// that are registered through the view get ns prepended to their PID and
+// name, so the namespaces of one pool never collide, and the monitor of the
+// view only shows the processes of the namespace with their original PID.
+// The workers are shared, so the methods about the workers and the pool
+// lifecycle, such as Start, Close, Resize, Stats, and Wait, apply to the
+// whole pool.
+func (w *workerPool) Namespace(ns string) Pool {
+	return &namespacePool{workerPool: w, ns: ns}
+}
+
+// Namespace returns a nested namespace of the view.
+func (n *namespacePool) Namespace(ns string) Pool {
+	return n.workerPool.Namespace(n.ns + namespaceSeparator + ns)
+}
+
+// wrap adds the namespace to the process.
+func (n *namespacePool) wrap(p Process) Process {
+	return namespacedProcess{Process: p, ns: n.ns}
+}
+
+// pid adds the namespace to the process id.
+func (n *namespacePool) pid(pid PID) PID {
+	return PID(n.ns+namespaceSeparator) + pid
+}
+
+// Register adds the processes to the pool within the namespace.
+func (n *namespacePool) Register(args ...Process) error {
+	args = expandPipelines(args)
+	wrapped := make([]Process, 0, len(args))
+	for _, p := range args {
+		wrapped = append(wrapped, n.wrap(p))
+	}
+
+	return n.workerPool.Register(wrapped...)
+}
+
+// RegisterBatch adds the processes to the queue at once within the
+// namespace.
+func (n *namespacePool) RegisterBatch(args ...Process) error {
+	args = expandPipelines(args)
+	wrapped := make([]Process, 0, len(args))
+	for _, p := range args {
+		wrapped = append(wrapped, n.wrap(p))
+	}
+
+	return n.workerPool.RegisterBatch(wrapped...)
+}
+
+// RegisterSequential registers a sequential group of processes within the
+// namespace.
+func (n *namespacePool) RegisterSequential(args ...Process) error {
+	wrapped := make([]Process, 0, len(args))
+	for _, p := range args {
+		wrapped = append(wrapped, n.wrap(p))
+	}
+
+	return n.workerPool.RegisterSequential(wrapped...)
+}
+
+// BroadcastRegister registers a copy of the process for each worker within
+// the namespace.
+func (n *namespacePool) BroadcastRegister(p Process) error {
+	copies := n.broadcastCopies(p)
+	for i, c := range copies {
+		copies[i] = n.wrap(c)
+	}
+
+	return n.workerPool.RegisterBatch(copies...)
+}
+
+// Kill cancels the process of the namespace.
+func (n *namespacePool) Kill(pid PID) error {
+	return n.workerPool.Kill(n.pid(pid))
+}
+
+// KillWithReason kills the process of the namespace with a reason.
+func (n *namespacePool) KillWithReason(pid PID, reason error) error {
+	return n.workerPool.KillWithReason(n.pid(pid), reason)
+}
+
+// KillWait cancels the process of the namespace and waits for it to return.
+func (n *namespacePool) KillWait(pid PID, timeout time.Duration) error {
+	return n.workerPool.KillWait(n.pid(pid), timeout)
+}
+
+// KillGroup kills the processes of the group in the namespace.
+func (n *namespacePool) KillGroup(name string) error {
+	return n.killGroup(name, n.workerPool.group(name, n.member))
+}
+
+// WaitGroup blocks until the processes of the group in the namespace are
+// finished or ctx is done.
+func (n *namespacePool) WaitGroup(ctx context.Context, name string) error {
+	return n.waitGroup(ctx, func() []ProcessStats {
+		return n.workerPool.group(name, n.member)
+	})
+}
+
+// member reports whether the process of stats belongs to the namespace.
+func (n *namespacePool) member(stats ProcessStats) bool {
+	_, ok := n.owns(stats.Process)
+	return ok
+}
+
+// Monitor returns a monitor that only shows the processes of the namespace.
+func (n *namespacePool) Monitor() Monitor {
+	return &namespaceMonitor{workerPool: n.workerPool, ns: n.ns}
+}
+
+// SubmitWithResult registers the process within the namespace and returns
+// the channel of its result.
+func (n *namespacePool) SubmitWithResult(p Process) (<-chan interface{}, error) {
+	return n.workerPool.SubmitWithResult(n.wrap(p))
+}
+
+// RegisterBarrier registers a barrier within the namespace that waits for
+// the group of processes of the namespace.
+func (n *namespacePool) RegisterBarrier(barrierPID PID, group []PID) error {
+	pids := make([]PID, 0, len(group))
+	for _, pid := range group {
+		pids = append(pids, n.pid(pid))
+	}
+
+	return n.workerPool.RegisterBarrier(n.pid(barrierPID), pids)
+}
+
+// NotifyOn sends the result of the process of the namespace to ch.
+func (n *namespacePool) NotifyOn(pid PID, ch chan<- ProcessResult) error {
+	results := make(chan ProcessResult, 1)
+	if err := n.workerPool.NotifyOn(n.pid(pid), results); err != nil {
+		return err
+	}
+
+	go func() {
+		defer close(ch)
+		for r := range results {
+			r.PID = pid
+			ch <- r
+		}
+	}()
+
+	return nil
+}
+
+// Reorder sorts the waiting processes of the namespace with less. The
+// processes of the other namespaces keep their position.
+func (n *namespacePool) Reorder(less func(a, b Process) bool) (int, error) {
+	match := func(p Process) bool {
+		_, ok := n.owns(p)
+		return ok
+	}
+
+	return n.reorder(match, func(a, b Process) bool {
+		ua, _ := n.owns(a)
+		ub, _ := n.owns(b)
+		return less(ua, ub)
+	})
+}
+
+// CompletionStream returns a channel of the results of the processes of the
+// namespace in completion order.
+func (n *namespacePool) CompletionStream() <-chan ProcessResult {
+	stream := n.workerPool.CompletionStream()
+	out := make(chan ProcessResult, n.size)
+
+	go func() {
+		defer close(out)
+		for r := range stream {
+			if p, ok := n.owns(n.processes.get(r.PID).Process); ok {
+				r.PID = p.PID()
+				out <- r
+			}
+		}
+	}()
+
+	return out
+}
+
+// RegisterSync registers the process within the namespace and waits until a
+// worker picks it up.
+func (n *namespacePool) RegisterSync(ctx context.Context, p Process) error {
+	return n.workerPool.RegisterSync(ctx, n.wrap(p))
+}
+
+// WaitUntilStatus blocks until the process of the namespace reaches one of
+// the statuses.
+func (n *namespacePool) WaitUntilStatus(ctx context.Context, pid PID, statuses ...process.Status) (process.Status, error) {
+	return n.workerPool.WaitUntilStatus(ctx, n.pid(pid), statuses...)
+}
+
+// ForWorker returns a handle to submit processes of the namespace to a
+// specific worker.
+func (n *namespacePool) ForWorker(name WorkerName) (WorkerHandle, error) {
+	handle, err := n.workerPool.ForWorker(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &namespaceHandle{WorkerHandle: handle, ns: n.ns}, nil
+}
+
+// Submit registers the process within the namespace to run on the worker.
+func (h *namespaceHandle) Submit(p Process) error {
+	return h.WorkerHandle.Submit(namespacedProcess{Process: p, ns: h.ns})
+}
+
+// owns returns the original process if p belongs to the namespace.
+func (n *namespacePool) owns(p Process) (Process, bool) {
+	np, ok := p.(namespacedProcess)
+	if !ok || np.ns != n.ns {
+		return nil, false
+	}
+
+	return np.Process, true
+}
+
+// view returns the stats with the original process if the process belongs
+// to the namespace and matches the filters.
+func (m *namespaceMonitor) view(stats ProcessStats) (ProcessStats, bool) {
+	np, ok := stats.Process.(namespacedProcess)
+	if !ok || np.ns != m.ns {
+		return ProcessStats{}, false
+	}
+
+	for _, pattern := range m.patterns {
+		if !pattern.MatchString(np.Process.Name()) {
+			return ProcessStats{}, false
+		}
+	}
+
+	stats.Process = np.Process
+
+	return stats, true
+}
+
+// views returns the stats of the list that belong to the namespace.
+func (m *namespaceMonitor) views(list []ProcessStats) []ProcessStats {
+	filtered := make([]ProcessStats, 0, len(list))
+	for _, stats := range list {
+		if v, ok := m.view(stats); ok {
+			filtered = append(filtered, v)
+		}
+	}
+
+	return filtered
+}
+
+// pid adds the namespace to the process id.
+func (m *namespaceMonitor) pid(pid PID) PID {
+	return PID(m.ns+namespaceSeparator) + pid
+}
+
+// Error returns the error of the process of the namespace.
+func (m *namespaceMonitor) Error(pid PID) (error, bool) { //nolint:stylecheck
+	stats, ok := m.ProcessStats(pid)
+	return stats.err, ok
+}
+
+// Histogram returns the histogram of the process of the namespace.
+func (m *namespaceMonitor) Histogram(pid PID) (*DurationHistogram, bool) {
+	if _, ok := m.ProcessStats(pid); !ok {
+		return nil, false
+	}
+
+	return m.workerPool.Histogram(m.pid(pid))
+}
+
+// ProcessStats returns the stats of the process of the namespace.
+func (m *namespaceMonitor) ProcessStats(pid PID) (ProcessStats, bool) {
+	stats, _ := m.workerPool.ProcessStats(m.pid(pid))
+	return m.view(stats)
+}
+
+// Delta returns the delta of the processes of the namespace.
+func (m *namespaceMonitor) Delta(since time.Time) MonitorDelta {
+	delta := m.workerPool.Delta(since)
+	delta.Processes = m.views(delta.Processes)
+
+	return delta
+}
+
+// CompletedProcesses returns the completed processes of the namespace.
+func (m *namespaceMonitor) CompletedProcesses() []ProcessStats {
+	return m.views(m.workerPool.CompletedProcesses())
+}
+
+// Purge removes the stats of the completed processes of the namespace.
+func (m *namespaceMonitor) Purge(olderThan time.Time) int {
+	return m.purge(olderThan, func(stats ProcessStats) bool {
+		_, ok := m.view(stats)
+		return ok
+	})
+}
+
+// ResetStats removes the stats of the completed processes of the namespace.
+// The pool-wide counters and the other namespaces are untouched. It returns
+// an error if the pool is not running.
+func (m *namespaceMonitor) ResetStats() error {
+	if status := m.PoolStatus(); status != pool.Running {
+		return m.poolError("reset the stats", ErrPoolNotRunning, pool.Running)
+	}
+
+	m.Purge(time.Now())
+
+	return nil
+}
+
+// Metrics returns a snapshot of the metrics of the processes of the
+// namespace. The workers are shared by the pool, so ActiveWorkers and
+// IdleWorkers are pool-wide, and TotalDropped is always zero because the
+// dropped processes have no stats.
+func (m *namespaceMonitor) Metrics() MetricsSnapshot {
+	stats := m.Stats()
+	snapshot := MetricsSnapshot{ActiveWorkers: stats.ActiveWorkers, IdleWorkers: stats.IdleWorkers}
+	var runTime time.Duration
+	m.each(func(stats ProcessStats) {
+		switch stats.Status {
+		case process.Waiting:
+			snapshot.QueueDepth++
+		case process.Succeeded:
+			snapshot.TotalSucceeded++
+		case process.Failed:
+			snapshot.TotalFailed++
+		case process.Killed:
+			snapshot.TotalKilled++
+		case process.Cancelled:
+			snapshot.TotalCancelled++
+		}
+		if stats.Status.IsTerminal() && !stats.StartedAt.IsZero() && !stats.FinishedAt.IsZero() {
+			snapshot.ProcessesRun++
+			runTime += stats.ExecutionDuration()
+		}
+	})
+	if snapshot.ProcessesRun > 0 {
+		snapshot.AverageDuration = runTime / time.Duration(snapshot.ProcessesRun)
+	}
+
+	return snapshot
+}
+
+// TotalErrors returns the number of processes of the namespace that have
+// failed.
+func (m *namespaceMonitor) TotalErrors() int {
+	total := 0
+	m.each(func(stats ProcessStats) {
+		if stats.Status == process.Failed {
+			total++
+		}
+	})
+
+	return total
+}
+
+// ErrorCatalog returns the unique errors of the processes of the namespace
+// by their ErrorIndex.
+func (m *namespaceMonitor) ErrorCatalog() map[int]error {
+	catalog := m.workerPool.ErrorCatalog()
+	used := make(map[int]bool)
+	m.each(func(stats ProcessStats) {
+		used[stats.ErrorIndex] = true
+	})
+	for index := range catalog {
+		if !used[index] {
+			delete(catalog, index)
+		}
+	}
+
+	return catalog
+}
+
+// each calls f with the stats of every process of the namespace.
+func (m *namespaceMonitor) each(f func(ProcessStats)) {
+	m.processes.each(func(_ PID, stats ProcessStats) {
+		if v, ok := m.view(stats); ok {
+			f(v)
+		}
+	})
+}
+
+// DrainAudit returns the drain audit of the processes of the namespace.
+func (m *namespaceMonitor) DrainAudit() DrainAudit {
+	audit := m.workerPool.DrainAudit()
+	audit.InFlightAtClose = m.pids(audit.InFlightAtClose)
+	audit.WaitingAtClose = m.pids(audit.WaitingAtClose)
+	audit.CompletedDuringDrain = m.pids(audit.CompletedDuringDrain)
+
+	return audit
+}
+
+// GroupStats returns the group stats of the processes of the namespace.
+func (m *namespaceMonitor) GroupStats(name string) GroupStats {
+	return groupStats(m.group(name, func(stats ProcessStats) bool {
+		_, ok := m.view(stats)
+		return ok
+	}))
+}
+
+// GroupStatus returns the rolled-up state of the processes of the group in
+// the namespace.
+func (m *namespaceMonitor) GroupStatus(name string) (process.Status, bool) {
+	return groupStatus(m.GroupStats(name))
+}
+
+// BroadcastStats returns the broadcast stats of the processes of the
+// namespace.
+func (m *namespaceMonitor) BroadcastStats(pid PID) GroupStats {
+	return groupStats(m.broadcast(m.pid(pid), func(stats ProcessStats) bool {
+		_, ok := m.view(stats)
+		return ok
+	}))
+}
+
+// CircuitStatus returns the circuit state of the process name in the
+// namespace.
+func (m *namespaceMonitor) CircuitStatus(name string) circuit.Status {
+	return m.workerPool.CircuitStatus(m.ns + namespaceSeparator + name)
+}
+
+// pids returns the original ids of the processes of the namespace.
+func (m *namespaceMonitor) pids(list []PID) []PID {
+	var pids []PID
+	for _, pid := range list {
+		if v, ok := m.view(m.processes.get(pid)); ok {
+			pids = append(pids, v.Process.PID())
+		}
+	}
+
+	return pids
+}
+
+// WithFilter returns a view that only exposes the processes of the
+// namespace whose original name matches pattern.
+func (m *namespaceMonitor) WithFilter(pattern *regexp.Regexp) Monitor {
+	patterns := append(make([]*regexp.Regexp, 0, len(m.patterns)+1), m.patterns...)
+
+	return &namespaceMonitor{workerPool: m.workerPool, ns: m.ns, patterns: append(patterns, pattern)}
+}
@@ -0,0 +1,116 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// Namespace monitor should only show the processes of its namespace
+func TestWorkerPool_Namespace(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	nsA := wp.Namespace("ns-a")
+	nsB := wp.Namespace("ns-b")
+	a.NoError(nsA.Register(createProcess(3, 1, 0, processFuncWithoutLog)...))
+	a.NoError(nsB.Register(createProcess(2, 1, 0, processFuncWithError)...))
+	wp.Wait()
+
+	completed := nsA.Monitor().CompletedProcesses()
+	a.Len(completed, 3)
+	for _, stats := range completed {
+		a.Equal(process.Succeeded, stats.Status)
+		a.Contains([]PID{"p-11", "p-12", "p-13"}, stats.Process.PID())
+	}
+	a.Len(nsB.Monitor().CompletedProcesses(), 2)
+	a.Equal(process.Succeeded, processStats(t, nsA.Monitor(), "p-11").Status)
+	a.Equal(process.Failed, processStats(t, nsB.Monitor(), "p-11").Status)
+	a.Error(processError(t, nsB.Monitor(), "p-11"))
+
+	// The underlying pool sees the prefixed ids and names.
+	stats := processStats(t, wp.Monitor(), "ns-a/p-11")
+	a.Equal(process.Succeeded, stats.Status)
+	a.Equal("ns-a/p-1", stats.Process.Name())
+	a.Len(wp.Monitor().CompletedProcesses(), 5)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Namespace monitor should scope the metrics, errors and ResetStats to its
+// namespace
+func TestWorkerPool_NamespaceStats(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2), WithErrorDeduplication())
+	a.NoError(wp.Start(context.Background()))
+
+	nsA := wp.Namespace("ns-a")
+	nsB := wp.Namespace("ns-b")
+	a.NoError(nsA.Register(createProcess(3, 1, 0, processFuncWithoutLog)...))
+	a.NoError(nsB.Register(createProcess(2, 1, 0, processFuncWithError)...))
+	wp.Wait()
+
+	metrics := nsA.Monitor().Metrics()
+	a.Equal(int64(3), metrics.TotalSucceeded)
+	a.Zero(metrics.TotalFailed)
+	a.Equal(int64(3), metrics.ProcessesRun)
+	a.Zero(nsA.Monitor().TotalErrors())
+	a.Empty(nsA.Monitor().ErrorCatalog())
+	metrics = nsB.Monitor().Metrics()
+	a.Zero(metrics.TotalSucceeded)
+	a.Equal(int64(2), metrics.TotalFailed)
+	a.Equal(2, nsB.Monitor().TotalErrors())
+	a.Len(nsB.Monitor().ErrorCatalog(), 2)
+	a.Equal(2, wp.Monitor().TotalErrors())
+
+	a.NoError(nsA.Monitor().ResetStats())
+	a.Empty(nsA.Monitor().CompletedProcesses())
+	a.Zero(nsA.Monitor().Metrics().TotalSucceeded)
+	a.Len(nsB.Monitor().CompletedProcesses(), 2)
+	a.Equal(2, nsB.Monitor().TotalErrors())
+	a.Equal(int64(3), wp.Monitor().Metrics().TotalSucceeded)
+
+	a.NoError(wp.Close())
+	a.Error(nsB.Monitor().ResetStats())
+}
+
+// Namespace reorder should not move the processes of other namespaces
+func TestWorkerPool_NamespaceReorder(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	nsA := wp.Namespace("ns-a")
+	nsB := wp.Namespace("ns-b")
+	nsA.Register(newTestProcess("a", 1, 0, processFuncWithoutLog))
+	nsB.Register(newTestProcess("b", 1, 0, processFuncWithoutLog))
+	nsA.Register(newTestProcess("a", 2, 0, processFuncWithoutLog))
+
+	moved, err := nsA.Reorder(func(a, b Process) bool {
+		return a.PID() > b.PID()
+	})
+	a.NoError(err)
+	a.Equal(2, moved)
+
+	ch := make(chan ProcessResult, 1)
+	a.NoError(nsA.NotifyOn("p-1", ch))
+	err = wp.Start(context.Background())
+	a.NoError(err)
+	wp.Wait()
+
+	r := <-ch
+	a.Equal(PID("p-1"), r.PID)
+	a.True(processStats(t, wp.Monitor(), "ns-a/p-2").StartedAt.Before(processStats(t, wp.Monitor(), "ns-b/p-1").StartedAt))
+	a.True(processStats(t, wp.Monitor(), "ns-b/p-1").StartedAt.Before(r.StartedAt))
+
+	err = wp.Close()
+	a.NoError(err)
+}
@@ -0,0 +1,44 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import "time"
+
+// Observer receives the lifecycle events of the processes. The hooks are
+// called synchronously from the worker goroutine, so they should return
+// quickly.
+type Observer interface {
+	// OnDequeue is called when a worker picks the process from the queue,
+	// before the process starts. queueWait is the time the process spent
+	// between its registration and being picked.
+	OnDequeue(workerName WorkerName, p Process, queueWait time.Duration)
+
+	// OnStart is called right before the process starts.
+	OnStart(workerName WorkerName, p Process)
+
+	// OnComplete is called after the process reached a final state.
+	OnComplete(workerName WorkerName, result ProcessResult)
+}
+
+// observeDequeue calls the OnDequeue hook of the observers.
+func (w *workerPool) observeDequeue(wn WorkerName, p Process, queueWait time.Duration) {
+	for _, o := range w.config.Observers {
+		o.OnDequeue(wn, p, queueWait)
+	}
+}
+
+// observeStart calls the OnStart hook of the observers.
+func (w *workerPool) observeStart(wn WorkerName, p Process) {
+	for _, o := range w.config.Observers {
+		o.OnStart(wn, p)
+	}
+}
+
+// observeComplete calls the OnComplete hook of the observers.
+func (w *workerPool) observeComplete(wn WorkerName, result ProcessResult) {
+	for _, o := range w.config.Observers {
+		o.OnComplete(wn, result)
+	}
+}
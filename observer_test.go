@@ -0,0 +1,79 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// recordingObserver records the lifecycle events of the processes.
+type recordingObserver struct {
+	mutex   sync.Mutex
+	waits   map[PID]time.Duration
+	started map[PID]WorkerName
+	results map[PID]ProcessResult
+}
+
+func newRecordingObserver() *recordingObserver {
+	return &recordingObserver{
+		waits:   make(map[PID]time.Duration),
+		started: make(map[PID]WorkerName),
+		results: make(map[PID]ProcessResult),
+	}
+}
+
+func (o *recordingObserver) OnDequeue(workerName WorkerName, p Process, queueWait time.Duration) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.waits[p.PID()] = queueWait
+}
+
+func (o *recordingObserver) OnStart(workerName WorkerName, p Process) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.started[p.PID()] = workerName
+}
+
+func (o *recordingObserver) OnComplete(workerName WorkerName, result ProcessResult) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.results[result.PID] = result
+}
+
+// Queue wait should be the throughput time minus the execution time
+func TestWithObserver(t *testing.T) {
+	a := assert.New(t)
+	o := newRecordingObserver()
+	wp := NewPool(WithWorkerCount(2), WithObserver(o))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	registeredAt := time.Now()
+	wp.Register(createProcess(10, 1, 50*time.Millisecond, processFuncWithoutLog)...)
+	wp.Wait()
+
+	var waits, throughput, execution time.Duration
+	a.Len(o.waits, 10)
+	for pid, wait := range o.waits {
+		a.GreaterOrEqual(wait, time.Duration(0))
+		a.Equal(processStats(t, wp.Monitor(), pid).WorkerName, o.started[pid])
+		r := o.results[pid]
+		a.Equal(process.Succeeded, r.Status)
+		waits += wait
+		throughput += r.FinishedAt.Sub(registeredAt)
+		execution += r.FinishedAt.Sub(r.StartedAt)
+	}
+	a.InDelta(throughput-execution, waits, float64(10*time.Millisecond))
+
+	err = wp.Close()
+	a.NoError(err)
+}
@@ -0,0 +1,648 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	// maxSanitizedNameLength is the maximum length of a name returned by
+	// DefaultNameSanitizer.
+	maxSanitizedNameLength = 64
+)
+
+// ErrInvalidPoolConfig is returned by PoolConfig.Validate when a setting is
+// out of range.
+var ErrInvalidPoolConfig = errors.New("invalid pool configuration")
+
+// nameReplacer replaces the characters that are not safe in metric labels.
+var nameReplacer = strings.NewReplacer("/", "_", " ", "_", ".", "_")
+
+type (
+	// PoolConfig holds the optional settings of a pool. It is filled by the
+	// PoolOption functions that are passed to NewPool.
+	PoolConfig struct {
+		// WorkerCount is the number of workers that the pool starts with.
+		// Zero means one worker per CPU.
+		WorkerCount int
+
+		// Name identifies the pool in its log messages.
+		Name string
+
+		// IdleWorkerTimeout is the duration after which an idle worker
+		// sleeps until the pool has work again. Zero means the workers
+		// never sleep.
+		IdleWorkerTimeout time.Duration
+
+		// MinWorkers is the number of workers that never sleep with an
+		// idle worker timeout.
+		MinWorkers int
+
+		// HealthReportInterval is the period between two health reports.
+		HealthReportInterval time.Duration
+
+		// HealthReporter receives a pool stats snapshot every
+		// HealthReportInterval while the pool is running.
+		HealthReporter func(PoolStats)
+
+		// NameSanitizer transforms process names before they are used as
+		// metric labels, keys, or log fields.
+		NameSanitizer func(name string) string
+
+		// StartJitter is the window that registered processes are randomly
+		// spread over before they are dispatched to the workers.
+		StartJitter time.Duration
+
+		// RateLimit is the maximum number of processes that are dispatched per
+		// second. Zero means no limit.
+		RateLimit float64
+
+		// RateLimitBurst is the number of processes that can be dispatched at
+		// once before the rate limit applies.
+		RateLimitBurst int
+
+		// RateLimitBackpressure makes Register wait for the rate limiter
+		// instead of the workers.
+		RateLimitBackpressure bool
+
+		// TokenStore persists the rate limiter tokens across pool restarts.
+		TokenStore TokenStore
+
+		// CheckpointStore keeps the checkpoints of the processes.
+		CheckpointStore CheckpointStore
+
+		// CPUTracking enables capturing a CPU profile for each process.
+		CPUTracking bool
+
+		// ProfileDir is the directory that the profiling mode writes the
+		// CPU and heap profiles to. An empty directory disables it.
+		ProfileDir string
+
+		// ProfileInterval is the period of the profiles of the profiling
+		// mode.
+		ProfileInterval time.Duration
+
+		// AllowedProcessNames is the list of process names that can be
+		// registered. A nil list allows every name.
+		AllowedProcessNames []string
+
+		// DeniedProcessNames is the list of process names that cannot be
+		// registered.
+		DeniedProcessNames []string
+
+		// ResultTransformer maps each process result before it is stored in
+		// the process stats.
+		ResultTransformer func(ProcessResult) ProcessResult
+
+		// ScalingPolicy decides the number of workers of the running pool.
+		ScalingPolicy ScalingPolicy
+
+		// ScalingInterval is the period between two scaling policy
+		// evaluations.
+		ScalingInterval time.Duration
+
+		// Observers receive the lifecycle events of the processes.
+		Observers []Observer
+
+		// Loggers receive the log messages of the pool.
+		Loggers []Logger
+
+		// MetricsCollectors receive the metrics of the finished processes.
+		MetricsCollectors []MetricsCollector
+
+		// ContextEnrichers add values to the context of each process, in
+		// order, before it starts.
+		ContextEnrichers []ContextEnricher
+
+		// ExecutionTracers wrap each attempt of the processes, in order.
+		ExecutionTracers []ExecutionTracer
+
+		// ProcessTimeout is the maximum duration of each process. Zero means
+		// no timeout.
+		ProcessTimeout time.Duration
+
+		// TimeoutJitter is the upper bound of the random duration that is
+		// added to the timeout of each process.
+		TimeoutJitter time.Duration
+
+		// DependencyTimeout is the maximum duration that a Pending process
+		// waits for its dependencies. Zero means no timeout.
+		DependencyTimeout time.Duration
+
+		// MaxQueueWeight is the maximum total weight of the waiting
+		// processes. Zero means no limit.
+		MaxQueueWeight int
+
+		// ProcessIsolation makes each process own the goroutines that it
+		// starts with Go.
+		ProcessIsolation bool
+
+		// ProcessMemoryLimit is the Go runtime memory limit in bytes while a
+		// process is running. Zero means the limit is not changed.
+		ProcessMemoryLimit int64
+
+		// MaxTotalProcesses is the maximum number of processes that the
+		// monitor holds. Zero means no limit.
+		MaxTotalProcesses int
+
+		// ErrorDeduplication makes the processes that fail with the same
+		// error message share one error in the monitor.
+		ErrorDeduplication bool
+
+		// MaxErrors is the number of failed processes after which the pool
+		// stops dequeuing processes. Zero means no limit.
+		MaxErrors int
+
+		// PIDIsolation makes Reset move the processes of the finished
+		// lifecycle to their ArchivedPID.
+		PIDIsolation bool
+
+		// Deduplication makes Register skip the processes whose id is used
+		// by an active process, instead of returning ErrProcessActive.
+		Deduplication bool
+
+		// HistogramWindow is the number of samples that each process
+		// histogram keeps. Zero means all the samples are kept.
+		HistogramWindow int
+
+		// QueueCap is the maximum number of waiting processes. Zero means no
+		// limit.
+		QueueCap int
+
+		// Overflow is what Register does when the queue is full.
+		Overflow OverflowStrategy
+
+		// SLOTargets are the targets that SLOReport checks.
+		SLOTargets SLOTargets
+
+		// CircuitBreakerThreshold is the number of consecutive failed
+		// attempts of a process name that open its circuit. Zero means no
+		// circuit breaker.
+		CircuitBreakerThreshold int
+
+		// CircuitBreakerResetAfter is the duration after which an open
+		// circuit lets a probe process run.
+		CircuitBreakerResetAfter time.Duration
+	}
+
+	// PoolOption is a function that changes the pool configuration.
+	PoolOption func(*PoolConfig)
+)
+
+// NewPoolConfig returns the configuration that NewPool makes from the
+// options, so it can be validated or inspected before the pool is created.
+func NewPoolConfig(opts ...PoolOption) PoolConfig {
+	config := PoolConfig{
+		NameSanitizer:   DefaultNameSanitizer,
+		CheckpointStore: NewMemoryCheckpointStore(),
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.WorkerCount == 0 {
+		config.WorkerCount = runtime.NumCPU()
+	}
+
+	return config
+}
+
+// Validate returns ErrInvalidPoolConfig if a count, a limit, or a duration of
+// the configuration is negative.
+func (c PoolConfig) Validate() error {
+	ints := []struct {
+		name  string
+		value int64
+	}{
+		{"worker count", int64(c.WorkerCount)},
+		{"queue cap", int64(c.QueueCap)},
+		{"max errors", int64(c.MaxErrors)},
+		{"max total processes", int64(c.MaxTotalProcesses)},
+		{"max queue weight", int64(c.MaxQueueWeight)},
+		{"rate limit burst", int64(c.RateLimitBurst)},
+		{"process memory limit", c.ProcessMemoryLimit},
+		{"idle worker timeout", int64(c.IdleWorkerTimeout)},
+		{"min workers", int64(c.MinWorkers)},
+		{"histogram window", int64(c.HistogramWindow)},
+		{"health report interval", int64(c.HealthReportInterval)},
+		{"start jitter", int64(c.StartJitter)},
+		{"process timeout", int64(c.ProcessTimeout)},
+		{"timeout jitter", int64(c.TimeoutJitter)},
+		{"dependency timeout", int64(c.DependencyTimeout)},
+		{"circuit breaker threshold", int64(c.CircuitBreakerThreshold)},
+		{"circuit breaker reset", int64(c.CircuitBreakerResetAfter)},
+	}
+	for _, i := range ints {
+		if i.value < 0 {
+			return fmt.Errorf("%w: %s is negative", ErrInvalidPoolConfig, i.name)
+		}
+	}
+
+	if c.RateLimit < 0 {
+		return fmt.Errorf("%w: rate limit is negative", ErrInvalidPoolConfig)
+	}
+
+	return nil
+}
+
+// WithWorkerCount sets the number of workers that the pool starts with. The
+// pool starts one worker per CPU if this option is not set.
+func WithWorkerCount(n int) PoolOption {
+	return func(c *PoolConfig) {
+		c.WorkerCount = n
+	}
+}
+
+// WithName names the pool. The name is added to each log message of the
+// pool as the pool field.
+func WithName(name string) PoolOption {
+	return func(c *PoolConfig) {
+		c.Name = name
+	}
+}
+
+// WithLogger adds a logger that receives the log messages of the pool. It can
+// be passed multiple times to add several loggers. Without any logger, the
+// messages are discarded. Use NewStdLogger to write them with a standard
+// library logger.
+func WithLogger(l Logger) PoolOption {
+	return func(c *PoolConfig) {
+		c.Loggers = append(c.Loggers, l)
+	}
+}
+
+// WithIdleWorkerTimeout makes a worker sleep after it has been idle for
+// timeout, down to the minimum that is set by WithMinWorkers. A sleeping
+// worker stops its goroutine. When processes are published to the queue,
+// as many sleeping workers as needed to run them are woken up under their
+// former names, up to the number of workers of the pool, so a pool that is
+// idle for a long time holds no goroutines. The sleeping workers are not
+// listed by the monitor and do not count in the pool capacity.
+func WithIdleWorkerTimeout(timeout time.Duration) PoolOption {
+	return func(c *PoolConfig) {
+		c.IdleWorkerTimeout = timeout
+	}
+}
+
+// WithMinWorkers sets the number of workers that keep running when the other
+// workers sleep after WithIdleWorkerTimeout. It has no effect without an
+// idle worker timeout.
+func WithMinWorkers(n int) PoolOption {
+	return func(c *PoolConfig) {
+		c.MinWorkers = n
+	}
+}
+
+// WithHealthReporter makes the pool call reporter with a fresh Pool.Stats()
+// snapshot every interval while the pool is running. It is useful for
+// periodic health logs without subscribing to individual process events.
+func WithHealthReporter(interval time.Duration, reporter func(PoolStats)) PoolOption {
+	return func(c *PoolConfig) {
+		c.HealthReportInterval = interval
+		c.HealthReporter = reporter
+	}
+}
+
+// WithNameSanitizer sets the function that transforms process names before
+// the pool uses them as metric labels, keys, or log fields. The pool uses
+// DefaultNameSanitizer if this option is not set.
+func WithNameSanitizer(fn func(name string) string) PoolOption {
+	return func(c *PoolConfig) {
+		c.NameSanitizer = fn
+	}
+}
+
+// WithStartJitter delays the dispatch of each registered process by a random
+// duration in [0, window). It spreads a burst of processes that have been
+// registered at the same time, to avoid a thundering herd.
+func WithStartJitter(window time.Duration) PoolOption {
+	return func(c *PoolConfig) {
+		c.StartJitter = window
+	}
+}
+
+// WithRateLimit limits the number of processes that the workers start per
+// second. Up to burst processes can be started at once before the limit
+// applies. The limit can be reduced at runtime by Pool.Throttle.
+func WithRateLimit(perSecond float64, burst int) PoolOption {
+	return func(c *PoolConfig) {
+		c.RateLimit = perSecond
+		c.RateLimitBurst = burst
+	}
+}
+
+// WithRateLimitBackpressure moves the rate limit from the workers to
+// Register. Each registered process takes a token, and Register blocks until
+// the rate limiter has capacity or the pool is closed. It has no effect
+// without WithRateLimit.
+func WithRateLimitBackpressure() PoolOption {
+	return func(c *PoolConfig) {
+		c.RateLimitBackpressure = true
+	}
+}
+
+// WithPersistentRateLimit restores the rate limiter tokens from store when
+// the pool is made, and saves the available tokens to store when the pool is
+// closed. A restarted pool continues with the tokens that were left instead
+// of a full bucket, so a restart does not allow a burst of throttled
+// processes. The store errors are logged with the loggers of the pool. It has
+// no effect without WithRateLimit.
+func WithPersistentRateLimit(store TokenStore) PoolOption {
+	return func(c *PoolConfig) {
+		c.TokenStore = store
+	}
+}
+
+// WithCPUTracking captures a CPU profile while each process is running and
+// stores it in ProcessStats.CPUProfile. The Go runtime runs only one CPU
+// profile at a time, so when processes run concurrently only one of them is
+// profiled and the profile includes the CPU time of the other goroutines.
+func WithCPUTracking() PoolOption {
+	return func(c *PoolConfig) {
+		c.CPUTracking = true
+	}
+}
+
+// WithCheckpointStore sets the store of the states that the processes save
+// with Checkpoint. The pool uses a MemoryCheckpointStore if this option is
+// not set.
+func WithCheckpointStore(store CheckpointStore) PoolOption {
+	return func(c *PoolConfig) {
+		c.CheckpointStore = store
+	}
+}
+
+// WithProfilingMode writes a CPU and a heap profile of the whole program to
+// profileDir every profileInterval while the pool is running. The files are
+// named after the time the profile has been captured, {timestamp}.cpu and
+// {timestamp}.heap. The Go runtime runs only one CPU profile at a time, so
+// the CPU profiles of WithCPUTracking are empty while the profiling mode is
+// enabled.
+func WithProfilingMode(profileDir string, profileInterval time.Duration) PoolOption {
+	return func(c *PoolConfig) {
+		c.ProfileDir = profileDir
+		c.ProfileInterval = profileInterval
+	}
+}
+
+// WithAllowedProcessNames makes Register reject the processes whose name is
+// not in names.
+func WithAllowedProcessNames(names ...string) PoolOption {
+	return func(c *PoolConfig) {
+		c.AllowedProcessNames = append(make([]string, 0, len(names)), names...)
+	}
+}
+
+// WithDeniedProcessNames makes Register reject the processes whose name is in
+// names.
+func WithDeniedProcessNames(names ...string) PoolOption {
+	return func(c *PoolConfig) {
+		c.DeniedProcessNames = append(c.DeniedProcessNames, names...)
+	}
+}
+
+// WithResultTransformer applies fn to the result of each finished process
+// before its output and error are stored in ProcessStats. It can be used to
+// compress, hash, or truncate large outputs to reduce the monitor memory.
+func WithResultTransformer(fn func(ProcessResult) ProcessResult) PoolOption {
+	return func(c *PoolConfig) {
+		c.ResultTransformer = fn
+	}
+}
+
+// WithScalingPolicy makes the pool evaluate the policy every interval and
+// resize itself to the recommended number of workers. The bundled policies
+// are TargetQueueDepth and TargetUtilization.
+func WithScalingPolicy(policy ScalingPolicy, interval time.Duration) PoolOption {
+	return func(c *PoolConfig) {
+		c.ScalingPolicy = policy
+		c.ScalingInterval = interval
+	}
+}
+
+// WithObserver adds an observer that receives the lifecycle events of every
+// process. It can be passed multiple times to add several observers.
+func WithObserver(o Observer) PoolOption {
+	return func(c *PoolConfig) {
+		c.Observers = append(c.Observers, o)
+	}
+}
+
+// WithContextEnrichers adds enrichers that add values to the context of each
+// process before it starts. The enrichers are applied in order, and each one
+// receives the context that is returned by the previous one. The option can
+// be passed more than once.
+func WithContextEnrichers(enrichers ...ContextEnricher) PoolOption {
+	return func(c *PoolConfig) {
+		c.ContextEnrichers = append(c.ContextEnrichers, enrichers...)
+	}
+}
+
+// WithExecutionTracer adds a tracer that wraps each attempt of the processes.
+// It can be passed multiple times to add several tracers.
+func WithExecutionTracer(t ExecutionTracer) PoolOption {
+	return func(c *PoolConfig) {
+		c.ExecutionTracers = append(c.ExecutionTracers, t)
+	}
+}
+
+// WithProcessTimeout cancels the context of each process once it has been
+// running for timeout. A process that returns an error because of the
+// timeout is marked as Failed.
+func WithProcessTimeout(timeout time.Duration) PoolOption {
+	return func(c *PoolConfig) {
+		c.ProcessTimeout = timeout
+	}
+}
+
+// WithDependencyTimeout fails a process registered with WithDependsOn with
+// ErrDependencyTimeout if its dependencies have not all succeeded within
+// timeout of its registration.
+func WithDependencyTimeout(timeout time.Duration) PoolOption {
+	return func(c *PoolConfig) {
+		c.DependencyTimeout = timeout
+	}
+}
+
+// WithTimeoutJitter adds a random duration in [0, jitter] to the timeout of
+// each process, so a batch of processes with the same timeout does not expire
+// at the same time. It has no effect without WithProcessTimeout.
+func WithTimeoutJitter(jitter time.Duration) PoolOption {
+	return func(c *PoolConfig) {
+		c.TimeoutJitter = jitter
+	}
+}
+
+// WithMaxQueueWeight makes Register reject the processes with
+// ErrQueueWeightExceeded when the total weight of the waiting processes would
+// exceed total. The weight of a process is given by WeightedProcess, and it
+// is 1 for the other processes.
+func WithMaxQueueWeight(total int) PoolOption {
+	return func(c *PoolConfig) {
+		c.MaxQueueWeight = total
+	}
+}
+
+// WithMaxTotalProcesses makes Register return ErrTotalProcessLimitReached
+// once n processes have been registered. The processes that are removed by
+// Monitor.Purge free their place, so a long-running pool that purges its
+// monitor periodically keeps accepting processes while its memory stays
+// bounded.
+func WithMaxTotalProcesses(n int) PoolOption {
+	return func(c *PoolConfig) {
+		c.MaxTotalProcesses = n
+	}
+}
+
+// WithErrorDeduplication stores one error per unique error message in the
+// monitor. Processes that fail with the same message share the first error
+// and reference it by ProcessStats.ErrorIndex, and Monitor.ErrorCatalog
+// returns the unique errors. It keeps the monitor memory small when many
+// processes fail for the same reason.
+func WithErrorDeduplication() PoolOption {
+	return func(c *PoolConfig) {
+		c.ErrorDeduplication = true
+	}
+}
+
+// WithProcessIsolation runs each process with its own goroutine group. The
+// goroutines that a process starts with Go are cancelled when Start returns,
+// and the worker waits up to 500ms for them to exit before the process is
+// finished. The goroutines that are still running are logged.
+func WithProcessIsolation() PoolOption {
+	return func(c *PoolConfig) {
+		c.ProcessIsolation = true
+	}
+}
+
+// WithPerProcessMemoryLimit sets the Go runtime soft memory limit to bytes
+// with debug.SetMemoryLimit while each process is running, and restores the
+// previous limit afterwards. The garbage collector runs more often when the
+// heap gets close to the limit. The memory limit is global, so it applies to
+// the whole program while a process is running. The processes that need the
+// same limit run concurrently, and a process of a pool with another limit
+// waits on its worker until none of them is running.
+func WithPerProcessMemoryLimit(bytes int64) PoolOption {
+	return func(c *PoolConfig) {
+		c.ProcessMemoryLimit = bytes
+	}
+}
+
+// WithMaxErrors makes the pool stop dequeuing processes after n processes
+// have failed. The pool status becomes Errored, the running processes run to
+// completion, and Register returns ErrMaxErrorsReached. Close the pool to
+// release the workers.
+func WithMaxErrors(n int) PoolOption {
+	return func(c *PoolConfig) {
+		c.MaxErrors = n
+	}
+}
+
+// WithPIDIsolation keeps the processes of each pool lifecycle apart. When the
+// pool is reset, the processes of the lifecycle that ends are moved to the id
+// that ArchivedPID returns, so a process of the next lifecycle can reuse
+// their id without overwriting their stats. Register rejects the process ids
+// that start with the reserved prefix of the archived ids with
+// ErrReservedPID.
+func WithPIDIsolation() PoolOption {
+	return func(c *PoolConfig) {
+		c.PIDIsolation = true
+	}
+}
+
+// WithDeduplication makes Register, RegisterBatch, and WorkerHandle.Submit
+// skip a process whose id is used by a registered process that has not
+// reached a final state, instead of returning ErrProcessActive. The skipped
+// process is not queued, and it is recorded with the Skipped status in the
+// History of the active process, so the same work item that is triggered
+// twice runs once.
+func WithDeduplication() PoolOption {
+	return func(c *PoolConfig) {
+		c.Deduplication = true
+	}
+}
+
+// WithHistogramWindow makes the histogram of each process keep only the
+// running time of its last n attempts, so the histograms of long-running
+// pools stay small and follow the recent latency.
+func WithHistogramWindow(n int) PoolOption {
+	return func(c *PoolConfig) {
+		c.HistogramWindow = n
+	}
+}
+
+// WithQueueCap limits the number of waiting processes, which is the
+// QueueDepth of the monitor, to n. When the queue is full, Register blocks
+// until a worker takes a process, unless another overflow strategy is set.
+// The retried processes are always queued again, so they may exceed n.
+func WithQueueCap(n int) PoolOption {
+	return func(c *PoolConfig) {
+		c.QueueCap = n
+	}
+}
+
+// WithOverflowBlock makes Register block until the full queue has room. It
+// is the default overflow strategy.
+func WithOverflowBlock() PoolOption {
+	return func(c *PoolConfig) {
+		c.Overflow = OverflowBlock
+	}
+}
+
+// WithOverflowDrop makes Register drop the processes and return ErrQueueFull
+// when the queue is full.
+func WithOverflowDrop() PoolOption {
+	return func(c *PoolConfig) {
+		c.Overflow = OverflowDrop
+	}
+}
+
+// WithOverflowEvict makes Register evict the waiting processes with the
+// lowest priority to make room when the queue is full, if their priority is
+// lower than the priority of the new processes. The evicted processes are
+// Cancelled with ErrQueueFull. Otherwise, the new processes are dropped and
+// Register returns ErrQueueFull.
+func WithOverflowEvict() PoolOption {
+	return func(c *PoolConfig) {
+		c.Overflow = OverflowEvict
+	}
+}
+
+// WithSLOTargets sets the service level objectives that SLOReport checks.
+func WithSLOTargets(targets SLOTargets) PoolOption {
+	return func(c *PoolConfig) {
+		c.SLOTargets = targets
+	}
+}
+
+// WithCircuitBreaker opens the circuit of a process name after threshold
+// consecutive failed attempts of the processes with that name. While the
+// circuit is open, the waiting processes of the name and the new ones fail
+// with ErrCircuitOpen without running. After resetAfter, a single process of
+// the name runs as a probe: the circuit closes if it succeeds, and stays
+// open for another resetAfter if it fails. Monitor.CircuitStatus returns the
+// state of the circuit of a name.
+func WithCircuitBreaker(threshold int, resetAfter time.Duration) PoolOption {
+	return func(c *PoolConfig) {
+		c.CircuitBreakerThreshold = threshold
+		c.CircuitBreakerResetAfter = resetAfter
+	}
+}
+
+// DefaultNameSanitizer replaces '/', ' ', and '.' with '_' and truncates the
+// name to 64 characters.
+func DefaultNameSanitizer(name string) string {
+	name = nameReplacer.Replace(name)
+	if r := []rune(name); len(r) > maxSanitizedNameLength {
+		name = string(r[:maxSanitizedNameLength])
+	}
+
+	return name
+}
@@ -0,0 +1,102 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Default sanitizer should make process names safe for metric labels
+func TestDefaultNameSanitizer(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("my_service_operation_v2_0", DefaultNameSanitizer("my service/operation v2.0"))
+	a.Equal(strings.Repeat("a", 64), DefaultNameSanitizer(strings.Repeat("a", 100)))
+}
+
+// Custom sanitizer should replace the default one
+func TestWithNameSanitizer(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1)).(*workerPool)
+	p := newTestProcess("my service/operation v2.0", 1, 0, processFunc)
+	a.Equal("my_service_operation_v2_0", wp.processName(p))
+
+	wp = NewPool(WithWorkerCount(1), WithNameSanitizer(strings.ToUpper)).(*workerPool)
+	a.Equal("MY SERVICE/OPERATION V2.0", wp.processName(p))
+}
+
+// recordingLogger keeps the messages that it receives.
+type recordingLogger struct {
+	mutex    sync.Mutex
+	messages []string
+	fields   [][]Field
+}
+
+func (l *recordingLogger) record(msg string, fields []Field) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, msg)
+	l.fields = append(l.fields, fields)
+}
+
+func (l *recordingLogger) Debug(msg string, fields ...Field) { l.record(msg, fields) }
+func (l *recordingLogger) Info(msg string, fields ...Field)  { l.record(msg, fields) }
+func (l *recordingLogger) Warn(msg string, fields ...Field)  { l.record(msg, fields) }
+func (l *recordingLogger) Error(msg string, fields ...Field) { l.record(msg, fields) }
+
+// Pool config should start one worker per CPU unless the worker count is set
+func TestNewPoolConfig(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(runtime.NumCPU(), NewPoolConfig().WorkerCount)
+
+	config := NewPoolConfig(WithWorkerCount(3), WithName("orders"), WithQueueCap(10))
+	a.Equal(3, config.WorkerCount)
+	a.Equal("orders", config.Name)
+	a.Equal(10, config.QueueCap)
+	a.NoError(config.Validate())
+
+	wp := NewPool(WithWorkerCount(3))
+	a.NoError(wp.Start(context.Background()))
+	a.Len(wp.Monitor().WorkerList(), 3)
+	a.NoError(wp.Close())
+}
+
+// Validate should reject negative settings
+func TestPoolConfig_Validate(t *testing.T) {
+	a := assert.New(t)
+	err := NewPoolConfig(WithWorkerCount(-1)).Validate()
+	a.ErrorIs(err, ErrInvalidPoolConfig)
+	a.Contains(err.Error(), "worker count")
+
+	a.ErrorIs(NewPoolConfig(WithIdleWorkerTimeout(-time.Second)).Validate(), ErrInvalidPoolConfig)
+	a.ErrorIs(NewPoolConfig(WithRateLimit(-1, 1)).Validate(), ErrInvalidPoolConfig)
+	a.ErrorIs(NewPoolConfig(WithQueueCap(-1)).Validate(), ErrInvalidPoolConfig)
+	a.ErrorIs(NewPoolConfig(WithDependencyTimeout(-time.Second)).Validate(), ErrInvalidPoolConfig)
+}
+
+// Deprecated constructor should keep setting the number of workers
+func TestNewPoolWithSize(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPoolWithSize(2, WithName("legacy")).(*workerPool)
+	a.Equal(2, wp.size)
+	a.Equal("legacy", wp.config.Name)
+}
+
+// Logger should receive the messages of the pool with the pool name
+func TestWithLogger(t *testing.T) {
+	a := assert.New(t)
+	logger := new(recordingLogger)
+	wp := NewPool(WithWorkerCount(1), WithName("orders"), WithLogger(logger)).(*workerPool)
+	wp.log(levelInfo, "hello", Field{"pid", "p-1"})
+
+	a.Equal([]string{"hello"}, logger.messages)
+	a.Equal([]Field{{"pool", "orders"}, {"pid", "p-1"}}, logger.fields[0])
+}
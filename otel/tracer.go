@@ -0,0 +1,79 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+// Package otel traces the processes of gowl pools with OpenTelemetry. It is a
+// separate package, so the core package does not depend on OpenTelemetry.
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hamed-yousefi/gowl"
+)
+
+const (
+	// pidKey is the attribute that holds the process id.
+	pidKey = attribute.Key("gowl.process.pid")
+
+	// nameKey is the attribute that holds the process name.
+	nameKey = attribute.Key("gowl.process.name")
+
+	// workerKey is the attribute that holds the worker name.
+	workerKey = attribute.Key("gowl.worker.name")
+
+	// attemptKey is the attribute that holds the attempt number.
+	attemptKey = attribute.Key("gowl.process.attempt")
+)
+
+// executionTracer is a gowl.ExecutionTracer that starts a span for each
+// attempt of a process.
+type executionTracer struct {
+	tracer trace.Tracer
+}
+
+var _ gowl.ExecutionTracer = executionTracer{}
+
+// WithTracer returns a pool option that starts a child span with the tracer
+// for each attempt of a process. The span is named after the process and
+// records its error. Its parent is the span of the context that is attached
+// to the process by WithSpanContext, or else the span of the context that the
+// pool passes to the process, such as a span added by a gowl.ContextEnricher.
+func WithTracer(tracer trace.Tracer) gowl.PoolOption {
+	return gowl.WithExecutionTracer(executionTracer{tracer: tracer})
+}
+
+// WithSpanContext wraps the process to attach ctx, usually the context of
+// the caller that registers it, so the spans of the process are children of
+// the span of ctx.
+func WithSpanContext(p gowl.Process, ctx context.Context) gowl.Process {
+	return gowl.WithParentContext(p, ctx)
+}
+
+// StartExecution starts the span of the attempt.
+func (t executionTracer) StartExecution(ctx context.Context, e gowl.Execution) (context.Context, func(err error)) {
+	if e.Parent != nil {
+		if parent := trace.SpanFromContext(e.Parent); parent.SpanContext().IsValid() {
+			ctx = trace.ContextWithSpan(ctx, parent)
+		}
+	}
+
+	ctx, span := t.tracer.Start(ctx, e.Name, trace.WithAttributes(
+		pidKey.String(e.PID.String()),
+		nameKey.String(e.Name),
+		workerKey.String(string(e.WorkerName)),
+		attemptKey.Int(e.Attempt),
+	))
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,95 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hamed-yousefi/gowl"
+)
+
+// Each attempt should have its own span under the span of the caller
+func TestWithTracer(t *testing.T) {
+	a := assert.New(t)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("test")
+
+	failure := errors.New("failure")
+	wp := gowl.NewPool(gowl.WithWorkerCount(1), WithTracer(tracer))
+	a.NoError(wp.Start(context.Background()))
+	ctx, parent := tracer.Start(context.Background(), "register")
+	a.NoError(wp.Register(
+		WithSpanContext(gowl.ProcessFunc("p-1", "report", func(ctx context.Context) error {
+			return nil
+		}), ctx),
+		gowl.WithRetry(gowl.ProcessFunc("p-2", "flaky", func(ctx context.Context) error {
+			return failure
+		}), 2, gowl.FixedBackoff{}),
+	))
+	parent.End()
+	_ = wp.Wait()
+	a.NoError(wp.Close())
+
+	spans := recorder.Ended()
+	a.Len(spans, 4)
+	byName := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, s := range spans {
+		byName[s.Name()] = append(byName[s.Name()], s)
+	}
+
+	report := byName["report"][0]
+	a.Equal(parent.SpanContext().SpanID(), report.Parent().SpanID())
+	a.Equal(parent.SpanContext().TraceID(), report.SpanContext().TraceID())
+	a.Equal(codes.Unset, report.Status().Code)
+	a.Contains(report.Attributes(), attribute.String("gowl.process.pid", "p-1"))
+	a.Contains(report.Attributes(), attribute.String("gowl.process.name", "report"))
+	a.Contains(report.Attributes(), attribute.String("gowl.worker.name", "W0"))
+	a.Contains(report.Attributes(), attribute.Int("gowl.process.attempt", 1))
+
+	flaky := byName["flaky"]
+	a.Len(flaky, 2)
+	for i, s := range flaky {
+		a.False(s.Parent().IsValid())
+		a.Equal(codes.Error, s.Status().Code)
+		a.Equal("failure", s.Status().Description)
+		a.Len(s.Events(), 1)
+		a.Contains(s.Attributes(), attribute.Int("gowl.process.attempt", i+1))
+	}
+}
+
+// The span of the process context should be the parent of the spans
+func TestWithTracer_ProcessContext(t *testing.T) {
+	a := assert.New(t)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("test")
+
+	_, parent := tracer.Start(context.Background(), "pool")
+	defer parent.End()
+	enricher := gowl.ContextEnricherFunc(func(c context.Context, p gowl.Process) context.Context {
+		return trace.ContextWithSpan(c, parent)
+	})
+	wp := gowl.NewPool(gowl.WithWorkerCount(1), gowl.WithContextEnrichers(enricher), WithTracer(tracer))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(gowl.AnonymousProcess(func(ctx context.Context) error {
+		return nil
+	})))
+	_ = wp.Wait()
+	a.NoError(wp.Close())
+
+	spans := recorder.Ended()
+	a.Len(spans, 1)
+	a.Equal(parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
@@ -0,0 +1,104 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+const (
+	// OverflowBlock makes Register block until the queue has room.
+	OverflowBlock OverflowStrategy = iota
+	// OverflowDrop makes Register drop the processes and return
+	// ErrQueueFull.
+	OverflowDrop
+	// OverflowEvict makes Register evict the waiting processes with a lower
+	// priority to make room, and drop the processes otherwise.
+	OverflowEvict
+)
+
+// ErrQueueFull is returned by Register when the queue has reached its
+// capacity, and it is the error of the processes that have been evicted from
+// the queue.
+var ErrQueueFull = errors.New("queue is full")
+
+// OverflowStrategy is what Register does when the queue is full.
+type OverflowStrategy int
+
+// reserveQueue counts the processes as waiting if they fit in the queue
+// capacity. When the queue is full, it blocks, drops the processes, or
+// evicts waiting processes, depending on the overflow strategy.
+func (w *workerPool) reserveQueue(args []Process) error {
+	count := int64(len(args))
+	capacity := int64(w.config.QueueCap)
+	if capacity <= 0 {
+		atomic.AddInt64(&w.counters.waiting, count)
+		return nil
+	}
+
+	for {
+		changed := w.changes.wait()
+		total, ok := reserve(&w.counters.waiting, count, capacity)
+		if ok {
+			return nil
+		}
+
+		if w.config.Overflow == OverflowBlock && count <= capacity {
+			select {
+			case <-changed:
+				continue
+			case <-w.closeSignal():
+				return w.poolError("register the process", ErrPoolClosed, pool.Running)
+			}
+		}
+
+		if w.config.Overflow == OverflowEvict && w.evictBelow(minPriority(args)) {
+			continue
+		}
+
+		atomic.AddInt64(&w.counters.dropped, count)
+		w.publishStats()
+		w.log(levelWarn, "processes have been dropped, queue is full", Field{"processes", count})
+
+		return fmt.Errorf("%w: %d > %d", ErrQueueFull, total, capacity)
+	}
+}
+
+// evictBelow cancels the waiting process with the lowest priority if its
+// priority is lower than priority. It returns false if there is no such
+// process.
+func (w *workerPool) evictBelow(priority int) bool {
+	p, ok := w.queue.removeLowest(priority)
+	if !ok {
+		return false
+	}
+
+	atomic.AddInt64(&w.counters.dropped, 1)
+	w.log(levelWarn, "process has been evicted, queue is full", Field{"name", w.processName(p)}, Field{"pid", p.PID()})
+	stats := w.processes.get(p.PID())
+	stats.Status = process.Cancelled
+	stats.err = ErrQueueFull
+	w.controlPanel.get(p.PID()).cancel()
+	w.abandon(p, stats)
+
+	return true
+}
+
+// minPriority returns the lowest priority of the processes.
+func minPriority(args []Process) int {
+	lowest := 0
+	for i, p := range args {
+		if priority := processPriority(p); i == 0 || priority < lowest {
+			lowest = priority
+		}
+	}
+
+	return lowest
+}
@@ -0,0 +1,93 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// A full queue should drop the new processes
+func TestWithQueueCap_Drop(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithQueueCap(2), WithOverflowDrop())
+	a.NoError(wp.Register(createProcess(2, 1, 0, processFuncWithoutLog)...))
+	a.ErrorIs(wp.Register(newTestProcess("dropped", 1, 0, processFuncWithoutLog)), ErrQueueFull)
+	a.ErrorIs(wp.Register(createProcess(2, 2, 0, processFuncWithoutLog)...), ErrQueueFull)
+	_, ok := wp.Monitor().ProcessStats("p-1")
+	a.False(ok)
+
+	metrics := wp.Monitor().Metrics()
+	a.Equal(int64(2), metrics.QueueDepth)
+	a.Equal(int64(3), metrics.TotalDropped)
+
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Wait())
+	a.NoError(wp.Register(newTestProcess("accepted", 1, 0, processFuncWithoutLog)))
+	a.NoError(wp.Wait())
+	a.Equal(int64(3), wp.Stats().TotalSucceeded)
+	a.NoError(wp.Close())
+}
+
+// A full queue should block Register until a worker takes a process
+func TestWithQueueCap_Block(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithQueueCap(1))
+	a.NoError(wp.Register(newTestProcess("first", 1, 0, processFuncWithoutLog)))
+
+	registered := make(chan error, 1)
+	go func() {
+		registered <- wp.Register(newTestProcess("second", 2, 0, processFuncWithoutLog))
+	}()
+
+	select {
+	case <-registered:
+		a.FailNow("Register is not blocked by the full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	a.NoError(wp.Start(context.Background()))
+	select {
+	case err := <-registered:
+		a.NoError(err)
+	case <-time.After(time.Second):
+		a.FailNow("Register is not unblocked")
+	}
+	a.NoError(wp.Wait())
+	a.Equal(int64(2), wp.Stats().TotalSucceeded)
+	a.Equal(int64(0), wp.Stats().TotalDropped)
+	a.NoError(wp.Close())
+}
+
+// A full queue should evict the waiting process with the lowest priority
+func TestWithQueueCap_Evict(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithQueueCap(2), WithOverflowEvict())
+	a.NoError(wp.Register(
+		WithPriority(newTestProcess("low", 1, 0, processFuncWithoutLog), 1),
+		WithPriority(newTestProcess("high", 2, 0, processFuncWithoutLog), 5),
+	))
+
+	a.NoError(wp.Register(WithPriority(newTestProcess("medium", 3, 0, processFuncWithoutLog), 3)))
+	evicted := processStats(t, wp.Monitor(), "p-1")
+	a.Equal(process.Cancelled, evicted.Status)
+	a.ErrorIs(processError(t, wp.Monitor(), "p-1"), ErrQueueFull)
+
+	a.ErrorIs(wp.Register(WithPriority(newTestProcess("lowest", 4, 0, processFuncWithoutLog), 3)), ErrQueueFull)
+	_, ok := wp.Monitor().ProcessStats("p-4")
+	a.False(ok)
+	a.Equal(int64(2), wp.Stats().TotalDropped)
+	a.Equal(int64(2), wp.Stats().QueueDepth)
+
+	a.NoError(wp.Start(context.Background()))
+	a.Error(wp.Wait())
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-2").Status)
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-3").Status)
+	a.NoError(wp.Close())
+}
@@ -0,0 +1,64 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// Pause stops the pool from dispatching processes to the workers until
+// Resume is called, for example during a deployment or an outage of a
+// downstream system. The running processes run to completion, and Register
+// keeps adding processes to the queue, so they are dispatched on Resume. The
+// pool status is Paused in the meantime. It returns an error if the pool is
+// not running.
+func (w *workerPool) Pause() error {
+	w.statusMutex.Lock()
+	defer w.statusMutex.Unlock()
+
+	if w.status != pool.Running {
+		return &PoolError{Op: "pause the pool", Err: ErrPoolNotRunning, CurrentStatus: w.status,
+			ExpectedStatus: pool.Running}
+	}
+
+	w.transition(pool.Paused)
+	w.queue.setPaused(true)
+	w.emit(PoolPaused, "", "")
+
+	return nil
+}
+
+// Resume lets the paused pool dispatch processes again. It returns an error
+// if the pool is not paused.
+func (w *workerPool) Resume() error {
+	w.statusMutex.Lock()
+	defer w.statusMutex.Unlock()
+
+	if w.status != pool.Paused {
+		return &PoolError{Op: "resume the pool", Err: ErrInvalidPoolStatus, CurrentStatus: w.status,
+			ExpectedStatus: pool.Paused}
+	}
+
+	w.transition(pool.Running)
+	w.queue.setPaused(false)
+	w.emit(PoolResumed, "", "")
+
+	return nil
+}
+
+// evict removes the killed process from the queue of the paused pool and
+// finishes it, since no worker takes it before Resume.
+func (w *workerPool) evict(pid PID) {
+	p, ok := w.queue.remove(pid)
+	if !ok {
+		return
+	}
+
+	stats := w.processes.get(pid)
+	stats.Status = process.Killed
+	w.log(levelInfo, "process has been killed", Field{"name", w.processName(p)}, Field{"pid", pid})
+	w.abandon(p, stats)
+}
@@ -0,0 +1,78 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// A paused pool should keep the registered processes until it is resumed
+func TestWorkerPool_PauseResume(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.Error(wp.Pause())
+	a.Error(wp.Resume())
+	a.NoError(wp.Start(context.Background()))
+	a.Error(wp.Resume())
+
+	a.NoError(wp.Register(newTestProcess("long", 1, 100*time.Millisecond, processFuncWithoutLog)))
+	_, err := wp.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.NoError(err)
+
+	a.NoError(wp.Pause())
+	a.Error(wp.Pause())
+	a.Equal(pool.Paused, wp.Monitor().PoolStatus())
+	a.NoError(wp.Register(createProcess(3, 1, 0, processFuncWithoutLog)...))
+
+	_, err = wp.WaitUntilStatus(context.Background(), "p-1", process.Succeeded)
+	a.NoError(err)
+	time.Sleep(20 * time.Millisecond)
+	for _, pid := range []PID{"p-11", "p-12", "p-13"} {
+		a.Equal(process.Waiting, processStats(t, wp.Monitor(), pid).Status)
+	}
+
+	a.NoError(wp.KillWait("p-12", 100*time.Millisecond))
+	a.Equal(process.Killed, processStats(t, wp.Monitor(), "p-12").Status)
+
+	a.NoError(wp.Resume())
+	a.Equal(pool.Running, wp.Monitor().PoolStatus())
+	a.NoError(wp.Wait())
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-11").Status)
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-13").Status)
+	a.NoError(wp.Close())
+}
+
+// A paused pool should close without running the waiting processes
+func TestWorkerPool_PauseClose(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Pause())
+	a.NoError(wp.Register(createProcess(2, 1, 0, processFuncWithoutLog)...))
+
+	a.NoError(wp.Close())
+	a.Equal(pool.Closed, wp.Monitor().PoolStatus())
+	a.True(processStats(t, wp.Monitor(), "p-11").StartedAt.IsZero())
+	a.Error(wp.Resume())
+}
+
+// CloseGraceful should resume a paused pool to drain its processes
+func TestWorkerPool_PauseCloseGraceful(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Pause())
+	a.NoError(wp.Register(createProcess(2, 1, 0, processFuncWithoutLog)...))
+
+	a.NoError(wp.CloseGraceful())
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-11").Status)
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-12").Status)
+}
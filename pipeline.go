@@ -0,0 +1,201 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// pipelineStageFormat is the format of the id and name of a stage of a
+	// pipeline, other than the last one, from the pipeline id or name and
+	// the stage number.
+	pipelineStageFormat = "%s/%d"
+)
+
+type (
+	// Stage is a step of a pipeline that turns its input into an output.
+	Stage[I, O any] func(ctx context.Context, in I) (O, error)
+
+	// Pipeline is a chain of stages where the output of each stage is the
+	// input of the next one. It implements Process, so it can be registered
+	// like any other process. Register and RegisterBatch schedule each stage
+	// as a separate process: the last stage gets the id and name of the
+	// pipeline, the stage n before it gets "<pid>/<n>" and "<name>/<n>", and
+	// each stage depends on the previous one, so a stage that fails fails
+	// the downstream stages with ErrDependencyFailed. Elsewhere, for example
+	// with ForWorker or when the pipeline is wrapped by a per-process option,
+	// the pipeline runs its stages one after another within its own Start.
+	Pipeline[I, O any] struct {
+		pid    PID
+		name   string
+		build  func() ([]*stageProcess, <-chan O)
+		output O
+	}
+
+	// stageProcess is a stage of a pipeline that runs as a process.
+	stageProcess struct {
+		pid    PID
+		name   string
+		run    func(ctx context.Context) (interface{}, error)
+		output interface{}
+	}
+
+	// pipelineProcess is a process that Register expands into the processes
+	// of its stages.
+	pipelineProcess interface {
+		Process
+		stages() []Process
+	}
+)
+
+// NewPipeline returns a pipeline with the given id and name whose first
+// stage runs with input.
+func NewPipeline[I, O any](pid PID, name string, input I, first Stage[I, O]) *Pipeline[I, O] {
+	return &Pipeline[I, O]{
+		pid:  pid,
+		name: name,
+		build: func() ([]*stageProcess, <-chan O) {
+			out := make(chan O, 1)
+			stage := &stageProcess{run: func(ctx context.Context) (interface{}, error) {
+				o, err := first(ctx, input)
+				if err != nil {
+					return nil, err
+				}
+				out <- o
+
+				return o, nil
+			}}
+
+			return []*stageProcess{stage}, out
+		},
+	}
+}
+
+// Chain returns a pipeline that runs next with the output of p. Go methods
+// cannot have type parameters, so a stage that changes the output type is
+// added with Chain, and Then adds the stages that keep it.
+func Chain[I, O, N any](p *Pipeline[I, O], next Stage[O, N]) *Pipeline[I, N] {
+	return &Pipeline[I, N]{
+		pid:  p.pid,
+		name: p.name,
+		build: func() ([]*stageProcess, <-chan N) {
+			stages, in := p.build()
+			out := make(chan N, 1)
+			stage := &stageProcess{run: func(ctx context.Context) (interface{}, error) {
+				var v O
+				select {
+				case v = <-in:
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+
+				o, err := next(ctx, v)
+				if err != nil {
+					return nil, err
+				}
+				out <- o
+
+				return o, nil
+			}}
+
+			return append(stages, stage), out
+		},
+	}
+}
+
+// Then returns a pipeline that runs next with the output of p.
+func (p *Pipeline[I, O]) Then(next Stage[O, O]) *Pipeline[I, O] {
+	return Chain(p, next)
+}
+
+// Start runs the stages one after another, and stops at the first stage
+// that fails.
+func (p *Pipeline[I, O]) Start(ctx context.Context) error {
+	stages, out := p.build()
+	for _, stage := range stages {
+		if err := stage.Start(ctx); err != nil {
+			return err
+		}
+	}
+	p.output = <-out
+
+	return nil
+}
+
+// Name returns the pipeline name.
+func (p *Pipeline[I, O]) Name() string {
+	return p.name
+}
+
+// PID returns the pipeline id.
+func (p *Pipeline[I, O]) PID() PID {
+	return p.pid
+}
+
+// ExtractResult returns the output of the last stage after Start.
+func (p *Pipeline[I, O]) ExtractResult() interface{} {
+	return p.output
+}
+
+// stages returns the processes of the stages of the pipeline.
+func (p *Pipeline[I, O]) stages() []Process {
+	stages, _ := p.build()
+	processes := make([]Process, 0, len(stages))
+	for i, stage := range stages {
+		stage.pid, stage.name = p.pid, p.name
+		if i < len(stages)-1 {
+			stage.pid = PID(fmt.Sprintf(pipelineStageFormat, p.pid, i+1))
+			stage.name = fmt.Sprintf(pipelineStageFormat, p.name, i+1)
+		}
+
+		var sp Process = stage
+		if i > 0 {
+			sp = WithDependsOn(stage, processes[i-1].PID())
+		}
+		processes = append(processes, sp)
+	}
+
+	return processes
+}
+
+// Start runs the stage.
+func (s *stageProcess) Start(ctx context.Context) error {
+	output, err := s.run(ctx)
+	s.output = output
+
+	return err
+}
+
+// Name returns the stage name.
+func (s *stageProcess) Name() string {
+	return s.name
+}
+
+// PID returns the stage id.
+func (s *stageProcess) PID() PID {
+	return s.pid
+}
+
+// ExtractResult returns the output of the stage.
+func (s *stageProcess) ExtractResult() interface{} {
+	return s.output
+}
+
+// expandPipelines returns args with the pipelines replaced by the processes
+// of their stages.
+func expandPipelines(args []Process) []Process {
+	expanded := make([]Process, 0, len(args))
+	for _, p := range args {
+		if pp, ok := p.(pipelineProcess); ok {
+			expanded = append(expanded, pp.stages()...)
+			continue
+		}
+		expanded = append(expanded, p)
+	}
+
+	return expanded
+}
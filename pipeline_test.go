@@ -0,0 +1,78 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// double is a pipeline stage that doubles its input
+func double(_ context.Context, in int) (int, error) {
+	return in * 2, nil
+}
+
+// A registered pipeline should run each stage as a process and pass the outputs along
+func TestPipeline(t *testing.T) {
+	a := assert.New(t)
+	p := NewPipeline("p-1", "etl", "3", func(_ context.Context, in string) (int, error) {
+		return strconv.Atoi(in)
+	}).Then(double)
+	pipeline := Chain(p, func(_ context.Context, in int) (string, error) {
+		return "result: " + strconv.Itoa(in), nil
+	})
+
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Start(context.Background()))
+	result, err := wp.SubmitWithResult(pipeline)
+	a.NoError(err)
+	a.Equal("result: 6", <-result)
+	a.NoError(wp.Wait())
+
+	m := wp.Monitor()
+	a.Equal(process.Succeeded, processStats(t, m, "p-1/1").Status)
+	a.Equal("etl/1", processStats(t, m, "p-1/1").Process.Name())
+	a.Equal(6, processStats(t, m, "p-1/2").Output)
+	a.Equal([]PID{"p-1/2"}, processStats(t, m, "p-1").DependsOn)
+	a.Equal("etl", processStats(t, m, "p-1").Process.Name())
+	a.NoError(wp.Close())
+}
+
+// A failing stage should fail the downstream stages
+func TestPipeline_Failure(t *testing.T) {
+	a := assert.New(t)
+	errStage := errors.New("stage has failed")
+	pipeline := NewPipeline("p-1", "etl", 1, double).Then(func(context.Context, int) (int, error) {
+		return 0, errStage
+	}).Then(double).Then(double)
+
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Register(pipeline))
+	a.NoError(wp.Start(context.Background()))
+	a.Error(wp.Wait())
+
+	m := wp.Monitor()
+	a.Equal(process.Succeeded, processStats(t, m, "p-1/1").Status)
+	a.ErrorIs(processError(t, m, "p-1/2"), errStage)
+	a.ErrorIs(processError(t, m, "p-1/3"), ErrDependencyFailed)
+	a.ErrorIs(processError(t, m, "p-1"), ErrDependencyFailed)
+	a.True(processStats(t, m, "p-1").StartedAt.IsZero())
+	a.NoError(wp.Close())
+}
+
+// A pipeline that is started directly should run its stages in order
+func TestPipeline_Start(t *testing.T) {
+	a := assert.New(t)
+	pipeline := NewPipeline("p-1", "etl", 1, double).Then(double).Then(double)
+	a.NoError(pipeline.Start(context.Background()))
+	a.Equal(8, pipeline.ExtractResult())
+	a.Equal(PID("p-1"), pipeline.PID())
+	a.Equal("etl", pipeline.Name())
+}
@@ -0,0 +1,121 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+type (
+	// Field is a key-value pair that is attached to a log message.
+	Field struct {
+		Key   string
+		Value interface{}
+	}
+
+	// Logger receives the log messages of the pool.
+	Logger interface {
+		// Debug logs a message that is only useful to debug the pool.
+		Debug(msg string, fields ...Field)
+		// Info logs a message about a normal event.
+		Info(msg string, fields ...Field)
+		// Warn logs a message about an unexpected event that the pool can
+		// recover from.
+		Warn(msg string, fields ...Field)
+		// Error logs a message about a failed operation.
+		Error(msg string, fields ...Field)
+	}
+
+	// MetricsCollector receives the metrics of the finished processes.
+	MetricsCollector interface {
+		// ObserveProcess records a finished process. The name is sanitized
+		// by the pool NameSanitizer, so it can be used as a metric label.
+		ObserveProcess(name string, status process.Status, queueWait, runTime time.Duration)
+	}
+
+	// ObservabilityPlugin is a single object that observes the processes,
+	// receives the log messages, and collects the metrics of a pool. Embed
+	// noop.NoopPlugin to implement only the hooks that you need.
+	ObservabilityPlugin interface {
+		Observer
+		Logger
+		MetricsCollector
+	}
+
+	// logLevel is the severity of a log message.
+	logLevel int
+)
+
+// WithObservabilityPlugin registers the plugin as an observer, a logger, and
+// a metrics collector of the pool. It can be passed multiple times to add
+// several plugins.
+func WithObservabilityPlugin(plugin ObservabilityPlugin) PoolOption {
+	return func(c *PoolConfig) {
+		c.Observers = append(c.Observers, plugin)
+		c.Loggers = append(c.Loggers, plugin)
+		c.MetricsCollectors = append(c.MetricsCollectors, plugin)
+	}
+}
+
+// log sends the message to the loggers of the pool. Without any logger, the
+// message is discarded. The pool name, if any, is the first field.
+func (w *workerPool) log(level logLevel, msg string, fields ...Field) {
+	if len(w.config.Loggers) == 0 {
+		return
+	}
+
+	if w.config.Name != "" {
+		fields = append([]Field{StringField("pool", w.config.Name)}, fields...)
+	}
+
+	for _, l := range w.config.Loggers {
+		switch level {
+		case levelDebug:
+			l.Debug(msg, fields...)
+		case levelInfo:
+			l.Info(msg, fields...)
+		case levelWarn:
+			l.Warn(msg, fields...)
+		default:
+			l.Error(msg, fields...)
+		}
+	}
+}
+
+// loggerKey is the context key of the pool loggers.
+type loggerKey struct{}
+
+// withLogger returns the context of the process with the loggers of the pool,
+// so the process wrappers can log with them.
+func (w *workerPool) withLogger(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loggerKey{}, w.log)
+}
+
+// logContext sends the message to the loggers of the pool that runs the
+// process of ctx. The message is discarded if ctx has no pool loggers.
+func logContext(ctx context.Context, level logLevel, msg string, fields ...Field) {
+	if log, ok := ctx.Value(loggerKey{}).(func(logLevel, string, ...Field)); ok {
+		log(level, msg, fields...)
+	}
+}
+
+// collect sends the metrics of the finished process to the metrics
+// collectors of the pool.
+func (w *workerPool) collect(stats ProcessStats) {
+	for _, c := range w.config.MetricsCollectors {
+		c.ObserveProcess(w.processName(stats.Process), stats.Status,
+			stats.QueueWaitDuration(), stats.ExecutionDuration())
+	}
+}
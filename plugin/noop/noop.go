@@ -0,0 +1,43 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+// Package noop provides an observability plugin whose hooks do nothing. It is
+// meant to be embedded by the plugins that only implement some of the hooks.
+package noop
+
+import (
+	"time"
+
+	"github.com/hamed-yousefi/gowl"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// NoopPlugin implements gowl.ObservabilityPlugin with hooks that do nothing.
+type NoopPlugin struct{}
+
+var _ gowl.ObservabilityPlugin = NoopPlugin{}
+
+// OnDequeue does nothing.
+func (NoopPlugin) OnDequeue(gowl.WorkerName, gowl.Process, time.Duration) {}
+
+// OnStart does nothing.
+func (NoopPlugin) OnStart(gowl.WorkerName, gowl.Process) {}
+
+// OnComplete does nothing.
+func (NoopPlugin) OnComplete(gowl.WorkerName, gowl.ProcessResult) {}
+
+// Debug does nothing.
+func (NoopPlugin) Debug(string, ...gowl.Field) {}
+
+// Info does nothing.
+func (NoopPlugin) Info(string, ...gowl.Field) {}
+
+// Warn does nothing.
+func (NoopPlugin) Warn(string, ...gowl.Field) {}
+
+// Error does nothing.
+func (NoopPlugin) Error(string, ...gowl.Field) {}
+
+// ObserveProcess does nothing.
+func (NoopPlugin) ObserveProcess(string, process.Status, time.Duration, time.Duration) {}
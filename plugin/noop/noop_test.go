@@ -0,0 +1,73 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package noop
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+type (
+	// testProcess is a process that ends right away.
+	testProcess struct {
+		id int
+	}
+
+	// metricsPlugin only overrides ObserveProcess.
+	metricsPlugin struct {
+		NoopPlugin
+		mutex    sync.Mutex
+		observed map[string]process.Status
+	}
+)
+
+func (p testProcess) Start(context.Context) error {
+	return nil
+}
+
+func (p testProcess) Name() string {
+	return "job-" + strconv.Itoa(p.id)
+}
+
+func (p testProcess) PID() gowl.PID {
+	return gowl.PID("p-" + strconv.Itoa(p.id))
+}
+
+func (m *metricsPlugin) ObserveProcess(name string, status process.Status, queueWait, runTime time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.observed[name] = status
+}
+
+// Plugin that embeds NoopPlugin should only get the calls of its own hooks
+func TestNoopPlugin(t *testing.T) {
+	a := assert.New(t)
+	plugin := &metricsPlugin{observed: make(map[string]process.Status)}
+	wp := gowl.NewPool(gowl.WithWorkerCount(2), gowl.WithObservabilityPlugin(plugin))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	for i := 1; i <= 5; i++ {
+		a.NoError(wp.Register(testProcess{id: i}))
+	}
+	wp.Kill("p-1")
+	wp.Wait()
+
+	a.Len(plugin.observed, 5)
+	for i := 2; i <= 5; i++ {
+		a.Equal(process.Succeeded, plugin.observed["job-"+strconv.Itoa(i)])
+	}
+
+	err = wp.Close()
+	a.NoError(err)
+}
@@ -8,10 +8,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/hamed-yousefi/gowl/status/circuit"
 	"github.com/hamed-yousefi/gowl/status/pool"
 	"github.com/hamed-yousefi/gowl/status/process"
 	"github.com/hamed-yousefi/gowl/status/worker"
@@ -22,6 +27,71 @@ const (
 	defaultWorkerName = "W%d"
 )
 
+var (
+	// ErrPoolLocked is returned by the methods that change the pool
+	// configuration after the pool has been locked.
+	ErrPoolLocked = errors.New("pool configuration is locked")
+
+	// ErrForbiddenProcessName is returned by Register when a process name is
+	// not allowed by the pool.
+	ErrForbiddenProcessName = errors.New("forbidden process name")
+
+	// ErrProcessNotFound is returned when a process id is not registered to
+	// the pool.
+	ErrProcessNotFound = errors.New("process not found")
+
+	// ErrWorkerNotFound is returned when a worker name does not belong to
+	// the pool.
+	ErrWorkerNotFound = errors.New("worker not found")
+
+	// ErrPoolClosed is returned by the methods that wait for the processes
+	// when the pool is closed before the processes are finished, and by the
+	// methods that need a pool that is not closed or closing.
+	ErrPoolClosed = errors.New("pool is closed")
+
+	// ErrPoolNotRunning is returned by the methods that need a running pool.
+	ErrPoolNotRunning = errors.New("pool is not running")
+
+	// ErrPoolAlreadyRunning is returned by Start when the pool is already
+	// running.
+	ErrPoolAlreadyRunning = errors.New("pool is already running")
+
+	// ErrInvalidPoolStatus is returned by the methods that need the pool to
+	// be in another status, such as Resume when the pool is not paused.
+	ErrInvalidPoolStatus = errors.New("invalid pool status")
+
+	// ErrDuplicatePID is returned by Register when the same process id is
+	// registered twice at once.
+	ErrDuplicatePID = errors.New("duplicate process id")
+
+	// ErrInvalidProcessStatus is returned by the methods that need the
+	// process to be in another status, such as MigrateProcess when the
+	// process is not waiting.
+	ErrInvalidProcessStatus = errors.New("invalid process status")
+
+	// ErrInvalidArgument is returned when an argument of a method is out of
+	// its range, such as a negative number of workers.
+	ErrInvalidArgument = errors.New("invalid argument")
+
+	// ErrPoolFrozen is returned by Freeze when the pool is already frozen.
+	ErrPoolFrozen = errors.New("pool is already frozen")
+
+	// ErrPoolNotFrozen is returned by Thaw when the pool is not frozen.
+	ErrPoolNotFrozen = errors.New("pool is not frozen")
+
+	// ErrRateLimitNotSet is returned by Throttle when the pool has no rate
+	// limit.
+	ErrRateLimitNotSet = errors.New("rate limit is not set")
+
+	// ErrUnsupportedPool is returned by MigrateProcess when the source pool
+	// is not a pool made by NewPool.
+	ErrUnsupportedPool = errors.New("unsupported pool")
+
+	// ErrKillTimeout is returned by KillWait when the process does not return
+	// within the timeout. The process is still being killed.
+	ErrKillTimeout = errors.New("kill timeout exceeded")
+)
+
 type (
 	// WorkerName is a custom type of string that represents worker's name.
 	WorkerName string
@@ -40,18 +110,130 @@ type (
 		PID() PID
 	}
 
+	// processWrapper is implemented by the processes that wrap another
+	// process.
+	processWrapper interface {
+		unwrap() Process
+	}
+
+	// ValidatingProcess is a Process that validates its input before running.
+	// Workers call ValidateInput before Start, and if it returns an error the
+	// process fails immediately without being started.
+	ValidatingProcess interface {
+		Process
+		// ValidateInput returns an error if the process input is invalid.
+		ValidateInput() error
+	}
+
 	// Pool is a mechanism to dispatch processes between a group of workers.
 	Pool interface {
-		// Start runs the pool.
-		Start() error
+		// Start runs the pool until ctx is cancelled or the pool is closed.
+		Start(ctx context.Context) error
 		// Register adds the process to the pool queue.
-		Register(p ...Process)
+		Register(p ...Process) error
 		// Close stops a running pool.
 		Close() error
+		// CloseContext stops a running pool and cancels the processes that
+		// are still running when ctx is done.
+		CloseContext(ctx context.Context) error
+		// CloseGraceful waits for all registered processes to finish and
+		// then closes the pool.
+		CloseGraceful() error
 		// Kill cancels a process before it starts.
-		Kill(pid PID)
+		Kill(pid PID) error
+		// KillWithReason kills a process and records the reason as its
+		// error.
+		KillWithReason(pid PID, reason error) error
+		// Freeze stops the workers from taking processes from the queue.
+		Freeze() error
+		// Thaw lets the workers take processes from the queue again.
+		Thaw() error
+		// RegisterSequential registers a group of processes that run one at
+		// a time in the given order.
+		RegisterSequential(args ...Process) error
+		// Pause stops the pool from dispatching processes to the workers.
+		Pause() error
+		// Resume lets the paused pool dispatch processes again.
+		Resume() error
+		// RegisterBatch adds the processes to the queue at once.
+		RegisterBatch(args ...Process) error
+		// BroadcastRegister registers a copy of the process for each
+		// worker.
+		BroadcastRegister(p Process) error
+		// KillWait cancels the process and waits up to timeout for it to
+		// return.
+		KillWait(pid PID, timeout time.Duration) error
 		// Monitor returns pool monitor.
 		Monitor() Monitor
+		// Stats returns a snapshot of the pool counters.
+		Stats() PoolStats
+		// Resize changes the number of workers to n.
+		Resize(n int) error
+		// Scale adds delta workers to the pool, or removes them if delta is
+		// negative.
+		Scale(delta int) error
+		// EnsureWorkers makes sure the pool has at least n workers.
+		EnsureWorkers(n int) error
+		// Throttle multiplies the pool rate limit by factor.
+		Throttle(factor float64) error
+		// Utilization returns the fraction of busy workers.
+		Utilization() float64
+		// Capacity returns the number of idle workers and the total number
+		// of workers.
+		Capacity() (current, max int)
+		// Lock prevents any further configuration change of a running pool.
+		Lock() error
+		// Reset makes a closed pool ready to start again.
+		Reset() error
+		// KillGroup kills every process of a group.
+		KillGroup(name string) error
+		// WaitGroup blocks until all the processes of a group are finished
+		// or ctx is done.
+		WaitGroup(ctx context.Context, name string) error
+		// SLOReport returns the service level indicators of the pool over
+		// the window and whether they meet the targets.
+		SLOReport(window time.Duration) SLOReport
+		// Subscribe sends the pool and process events to ch.
+		Subscribe(ch chan<- Event)
+		// Unsubscribe stops sending the events to ch.
+		Unsubscribe(ch chan<- Event)
+		// Version returns the configuration version of the pool.
+		Version() int64
+		// SubmitWithResult registers the process and returns a channel that
+		// receives the process result.
+		SubmitWithResult(p Process) (<-chan interface{}, error)
+		// RegisterBarrier registers a barrier process that waits for a group
+		// of processes.
+		RegisterBarrier(barrierPID PID, group []PID) error
+		// AwaitIdle blocks until the queue is empty and all workers are idle.
+		AwaitIdle(ctx context.Context) error
+		// Wait blocks until all registered processes are finished and
+		// returns the errors of the failed processes.
+		Wait() error
+		// WaitContext blocks until all registered processes are finished or
+		// the context is done.
+		WaitContext(ctx context.Context) error
+		// NotifyOn sends the process result to ch once the process finished.
+		NotifyOn(pid PID, ch chan<- ProcessResult) error
+		// Reorder sorts the waiting processes.
+		Reorder(less func(a, b Process) bool) (moved int, err error)
+		// CompletionStream returns a channel of the process results in
+		// completion order.
+		CompletionStream() <-chan ProcessResult
+		// RegisterSync registers the process and waits until a worker picks
+		// it up.
+		RegisterSync(ctx context.Context, p Process) error
+		// WaitUntilStatus blocks until the process reaches one of the
+		// statuses.
+		WaitUntilStatus(ctx context.Context, pid PID, statuses ...process.Status) (process.Status, error)
+		// ForWorker returns a handle to submit processes to a specific worker.
+		ForWorker(name WorkerName) (WorkerHandle, error)
+		// Namespace returns a view of the pool that isolates the processes
+		// of the namespace.
+		Namespace(ns string) Pool
+		// SubPool returns a view of the pool that runs its processes on at
+		// most maxWorkers of the pool workers and can be closed on its own.
+		SubPool(name string, maxWorkers int) Pool
 	}
 
 	// Monitor is a mechanism for observation processes and pool stats.
@@ -59,13 +241,59 @@ type (
 		// PoolStatus returns pool status
 		PoolStatus() pool.Status
 		// Error returns process's error by process id.
-		Error(PID) error
+		Error(PID) (error, bool)
 		// WorkerList returns the list of worker names of the pool.
 		WorkerList() []WorkerName
 		// WorkerStatus returns worker status. It accepts worker name as input.
 		WorkerStatus(name WorkerName) worker.Status
+		// WorkerStats returns the current process and the totals of a
+		// worker, or false if the pool has no such worker.
+		WorkerStats(name WorkerName) (WorkerStats, bool)
+		// Histogram returns the running time histogram of the attempts of
+		// the process, or false if no attempt has finished.
+		Histogram(pid PID) (*DurationHistogram, bool)
 		// ProcessStats returns process stats. It accepts process id as input.
-		ProcessStats(pid PID) ProcessStats
+		ProcessStats(pid PID) (ProcessStats, bool)
+		// Delta returns the processes that changed since the given time.
+		Delta(since time.Time) MonitorDelta
+		// CompletedProcesses returns the stats of the finished processes.
+		CompletedProcesses() []ProcessStats
+		// Purge removes the stats of the processes that finished before the
+		// given time.
+		Purge(olderThan time.Time) int
+		// ResetStats zeroes the pool counters and removes the stats of the
+		// finished processes.
+		ResetStats() error
+		// Metrics returns a snapshot of the pool metrics.
+		Metrics() MetricsSnapshot
+		// TotalErrors returns the number of failed processes.
+		TotalErrors() int
+		// ActiveWorkerCount returns the number of busy workers.
+		ActiveWorkerCount() int
+		// IdleWorkerCount returns the number of idle workers.
+		IdleWorkerCount() int
+		// StartRate returns the number of processes started per second.
+		StartRate() float64
+		// WithFilter returns a view that only exposes the processes whose
+		// name matches pattern.
+		WithFilter(pattern *regexp.Regexp) Monitor
+		// ErrorCatalog returns the unique process errors.
+		ErrorCatalog() map[int]error
+		// DrainAudit returns the processes that were not finished when the
+		// pool started to close.
+		DrainAudit() DrainAudit
+		// GroupStats returns the number of the processes of a group in
+		// each state.
+		GroupStats(name string) GroupStats
+		// GroupStatus returns the rolled-up state of the processes of a
+		// group, or false if the group has no process.
+		GroupStatus(name string) (process.Status, bool)
+		// CircuitStatus returns the state of the circuit breaker of a
+		// process name.
+		CircuitStatus(name string) circuit.Status
+		// BroadcastStats returns the number of the copies of a broadcast
+		// process in each state.
+		BroadcastStats(pid PID) GroupStats
 	}
 
 	// ProcessStats represents process statistics.
@@ -79,178 +307,773 @@ type (
 		// Status represents the current state of the process.
 		Status process.Status
 
-		// StartedAt represents the start date time of the process.
+		// EnqueuedAt is the time the process has been registered. It is
+		// moved forward when the process enters the queue again, once its
+		// dependencies have succeeded or for its next retry.
+		EnqueuedAt time.Time
+
+		// StartedAt represents the start date time of the process. It is
+		// zero until a worker picks up the process.
 		StartedAt time.Time
 
-		// FinishedAt represents the end date time of the process.
+		// FinishedAt represents the end date time of the process. It is
+		// zero until the process reaches a final state.
 		FinishedAt time.Time
 
-		err error
+		// CPUProfile is the pprof encoded CPU profile that has been captured
+		// while the process was running. It is only set if the pool has CPU
+		// tracking enabled and the profiler was not in use by another process.
+		CPUProfile []byte
+
+		// Output is the value returned by ExtractResult if the process
+		// implements ResultExtractor.
+		Output interface{}
+
+		// ErrorIndex is the key of the process error in
+		// Monitor.ErrorCatalog. Zero means the error is not in the catalog.
+		ErrorIndex int
+
+		// Priority is the execution priority of the process that is set by
+		// WithPriority.
+		Priority int
+
+		// DependsOn is the list of the processes that must succeed before
+		// the process is queued, set by WithDependsOn.
+		DependsOn []PID
+
+		// BlockedBy is the list of the dependencies that have not succeeded
+		// yet.
+		BlockedBy []PID
+
+		// Attempt is the number of the current or last run of the process,
+		// starting at 1. It is greater than 1 only for the processes that are
+		// retried with WithRetry.
+		Attempt int
+
+		// Cycle is the lifecycle of the pool that the process has been
+		// registered in, starting at 1 and incremented by Reset.
+		Cycle int
+
+		// Group is the group of the process that is set by WithGroup.
+		Group string
+
+		// History holds the stats of the previous runs of the process id,
+		// oldest first, if the process is wrapped by WithPreserveHistory,
+		// and the registrations of the id that have been Skipped by
+		// WithDeduplication.
+		History []ProcessStats
+
+		err       error
+		updatedAt time.Time
 	}
 
 	// workerPool is an implementation of Pool and Monitor interfaces.
 	workerPool struct {
-		status       pool.Status
-		size         int
-		queue        chan Process
-		wg           *sync.WaitGroup
-		processes    *processStatusMap
-		workers      []WorkerName
-		workersStats *workerStatsMap
-		controlPanel *controlPanelMap
-		mutex        *sync.Mutex
-		isClosed     bool
+		status         pool.Status
+		statusMutex    *sync.RWMutex
+		size           int
+		queue          *processQueue
+		wg             *sync.WaitGroup
+		processes      *processStatusMap
+		workers        []WorkerName
+		workersStats   *workerStatsMap
+		workerCounters *workerCounters
+		workersMutex   *sync.RWMutex
+		controls       map[WorkerName]*workerControl
+		nextWorker     int
+		sleeping       map[WorkerName]chan struct{}
+		pinned         map[WorkerName]chan struct{}
+		controlPanel   *controlPanelMap
+		mutex          *sync.Mutex
+		registerMutex  *sync.Mutex
+		doneMutex      *sync.RWMutex
+		config         PoolConfig
+		counters       *poolCounters
+		startedAt      time.Time
+		done           chan struct{}
+		limiter        *rateLimiter
+		locked         int32
+		version        int64
+		cycle          int64
+		allowed        map[string]struct{}
+		denied         map[string]struct{}
+		changes        *broadcaster
+		starts         *rateMeter
+		errors         *errorCatalog
+		completions    *completionStreams
+		audit          *DrainAudit
+		subscribers    *subscribers
+		history        []statusChange
+		snapshot       atomic.Value
+		histograms     *histogramMap
+		circuits       *circuitBreaker
+		subPools       *subPoolLimits
+		snapshotLock   *sync.Mutex
 	}
 )
 
-// NewPool makes a new instance of Pool. I accept an integer value as input
-// that represents pool size.
-func NewPool(size int) Pool {
-	return &workerPool{
-		status:       pool.Created,
-		size:         size,
-		queue:        make(chan Process, size),
-		workers:      []WorkerName{},
-		processes:    new(processStatusMap),
-		workersStats: new(workerStatsMap),
-		controlPanel: new(controlPanelMap),
-		mutex:        new(sync.Mutex),
-		wg:           new(sync.WaitGroup),
+// QueueWaitDuration returns the time the process has waited in the queue
+// before a worker picked it up. It is zero if the process has not started.
+func (s ProcessStats) QueueWaitDuration() time.Duration {
+	if s.EnqueuedAt.IsZero() || s.StartedAt.IsZero() {
+		return 0
+	}
+
+	return s.StartedAt.Sub(s.EnqueuedAt)
+}
+
+// ExecutionDuration returns the time the process has run. It is zero if the
+// process has not finished.
+func (s ProcessStats) ExecutionDuration() time.Duration {
+	if s.StartedAt.IsZero() || s.FinishedAt.IsZero() {
+		return 0
+	}
+
+	return s.FinishedAt.Sub(s.StartedAt)
+}
+
+// NewPool makes a new instance of Pool. The pool behavior, such as the number
+// of workers, can be customized by passing a list of PoolOption.
+func NewPool(opts ...PoolOption) Pool {
+	config := NewPoolConfig(opts...)
+	wp := &workerPool{
+		status:         pool.Created,
+		statusMutex:    new(sync.RWMutex),
+		size:           config.WorkerCount,
+		queue:          newProcessQueue(),
+		workers:        []WorkerName{},
+		processes:      new(processStatusMap),
+		workersStats:   new(workerStatsMap),
+		workerCounters: newWorkerCounters(),
+		workersMutex:   new(sync.RWMutex),
+		controls:       make(map[WorkerName]*workerControl),
+		sleeping:       make(map[WorkerName]chan struct{}),
+		pinned:         make(map[WorkerName]chan struct{}),
+		controlPanel:   new(controlPanelMap),
+		mutex:          new(sync.Mutex),
+		registerMutex:  new(sync.Mutex),
+		doneMutex:      new(sync.RWMutex),
+		wg:             new(sync.WaitGroup),
+		counters:       new(poolCounters),
+		version:        1,
+		cycle:          1,
+		done:           make(chan struct{}),
+		changes:        newBroadcaster(),
+		starts:         newRateMeter(startRateWindow),
+		completions:    new(completionStreams),
+		subscribers:    new(subscribers),
+		history:        []statusChange{{status: pool.Created, at: time.Now()}},
+		snapshotLock:   new(sync.Mutex),
+		histograms:     new(histogramMap),
+		circuits:       newCircuitBreaker(config.CircuitBreakerThreshold, config.CircuitBreakerResetAfter),
+		subPools:       newSubPoolLimits(),
+		config:         config,
+	}
+	wp.publishStats()
+
+	if wp.config.RateLimit > 0 {
+		wp.limiter = newRateLimiter(wp.config.RateLimit, wp.config.RateLimitBurst)
+		if wp.config.TokenStore != nil {
+			tokens, err := wp.config.TokenStore.Load()
+			switch {
+			case err != nil:
+				// The pool can still run with a full bucket.
+				wp.log(levelWarn, "unable to load the rate limit tokens", Field{"error", err})
+			case tokens >= 0:
+				wp.limiter.setTokens(tokens)
+			}
+		}
+	}
+
+	if wp.config.ErrorDeduplication {
+		wp.errors = newErrorCatalog()
+	}
+
+	if wp.config.AllowedProcessNames != nil {
+		wp.allowed = toSet(wp.config.AllowedProcessNames)
 	}
+	wp.denied = toSet(wp.config.DeniedProcessNames)
+
+	return wp
+}
+
+// NewPoolWithSize makes a new instance of Pool with size workers.
+//
+// Deprecated: Use NewPool with WithWorkerCount instead.
+func NewPoolWithSize(size int, opts ...PoolOption) Pool {
+	return NewPool(append([]PoolOption{WithWorkerCount(size)}, opts...)...)
 }
 
 // Start runs the pool. It returns error if pool is already in running state.
 // It changes the pool state to Running and calls workerPool.run() function to
-// run the pool.
-func (w *workerPool) Start() error {
-	if w.status == pool.Running {
-		return errors.New("unable to start the pool, status: " + w.status.String())
+// run the pool. The pool is closed when ctx is cancelled, the same way Close
+// does: the running processes finish and the waiting ones are Cancelled.
+// That ties the pool lifetime to the application, for example to a context
+// from signal.NotifyContext.
+func (w *workerPool) Start(ctx context.Context) error {
+	if status := w.PoolStatus(); status.IsOpen() {
+		return w.poolError("start the pool", ErrPoolAlreadyRunning, pool.Created)
 	}
 
-	w.status = pool.Running
+	w.setStatus(pool.Running)
+	w.startedAt = time.Now()
+	w.starts.reset(w.startedAt)
 	w.run()
+	w.emit(PoolStarted, "", "")
+
+	if w.config.HealthReporter != nil && w.config.HealthReportInterval > 0 {
+		go w.reportHealth(w.config.HealthReportInterval, w.config.HealthReporter)
+	}
+
+	if w.config.ScalingPolicy != nil && w.config.ScalingInterval > 0 {
+		go w.autoscale(w.config.ScalingPolicy, w.config.ScalingInterval)
+	}
+
+	// Close waits for the profiler to write its last profiles.
+	if w.config.ProfileDir != "" && w.config.ProfileInterval > 0 {
+		w.wg.Add(1)
+		go w.profile(w.config.ProfileDir, w.config.ProfileInterval)
+	}
+
+	// A context that is never cancelled, such as context.Background, needs
+	// no watcher.
+	if ctx.Done() != nil {
+		go w.closeOnCancel(ctx, w.closeSignal())
+	}
 
 	return nil
 }
 
+// closeSignal returns the channel that is closed when the pool is closed.
+// Reset replaces the channel for the next lifecycle, so a goroutine takes it
+// once and keeps the one of its lifecycle. CloseContext and Reset access the
+// channel directly, because they hold the pool mutex.
+func (w *workerPool) closeSignal() <-chan struct{} {
+	w.doneMutex.RLock()
+	defer w.doneMutex.RUnlock()
+
+	return w.done
+}
+
+// closeOnCancel closes the pool when ctx is cancelled, unless the pool is
+// closed first, which closes done.
+func (w *workerPool) closeOnCancel(ctx context.Context, done <-chan struct{}) {
+	select {
+	case <-ctx.Done():
+		w.log(levelInfo, "pool context has been cancelled, closing the pool", ErrorField(ctx.Err()))
+		if err := w.Close(); err != nil {
+			w.log(levelDebug, "pool has already been closed", ErrorField(err))
+		}
+	case <-done:
+	}
+}
+
 // run is the function that creates worker and starts the pool.
 func (w *workerPool) run() {
-	// Create workers
-	for i := 0; i < w.size; i++ {
-		// For each worker add one to the waitGroup.
-		w.wg.Add(1)
-		wName := WorkerName(fmt.Sprintf(defaultWorkerName, i))
-		w.workers = append(w.workers, wName)
-
-		// Create worker.
-		go func(wn WorkerName) {
-			defer w.wg.Done()
-
-			// Consume process from the queue.
-			for p := range w.queue {
-				w.workersStats.put(wn, worker.Busy)
-				pStats := w.processes.get(p.PID())
-				pStats.Status = process.Running
-				pStats.StartedAt = time.Now()
-				pStats.WorkerName = wn
-				w.processes.put(p.PID(), pStats)
-				wgp := new(sync.WaitGroup)
-				wgp.Add(1)
-
-				go func() {
-					stats := w.processes.get(p.PID())
-					defer func() {
-						w.processes.put(p.PID(), stats)
-						wgp.Done()
-					}()
-					pContext := w.controlPanel.get(p.PID())
-					select {
-					case <-pContext.ctx.Done():
-						log.Printf("processFunc with id %s has been killed.\n", p.PID().String())
-						stats.Status = process.Killed
-						return
-					default:
-						if err := p.Start(pContext.ctx); err != nil { //nolint:typecheck
-							stats.err = err
-							stats.Status = process.Failed
-							if errors.Is(pContext.ctx.Err(), context.Canceled) {
-								stats.Status = process.Killed
-							}
-						} else {
-							stats.Status = process.Succeeded
-						}
-						pContext.cancel()
-					}
-				}()
-
-				wgp.Wait()
-				pStats = w.processes.get(p.PID())
-				pStats.FinishedAt = time.Now()
-				w.processes.put(p.PID(), pStats)
-				w.workersStats.put(wn, worker.Waiting)
-			}
-		}(wName)
-	}
+	w.workersMutex.Lock()
+	defer w.workersMutex.Unlock()
+
+	w.addWorkers(w.size)
+	w.notify()
 }
 
 // Register adds the process to the pool queue. It accept a list of processes
-// and adds them to the queue. It publishes the process to queue in a separate
-// goroutine. It means that Register function provides multi-publisher that
-// each of them works asynchronously.
-func (w *workerPool) Register(args ...Process) {
-	// Create control panel for each process and make process stat for each of them.
+// and adds them to the back of the queue in order. A Pipeline is registered
+// as the processes of its stages. The id of a finished process can be
+// registered again to run it afresh. Register can be called from multiple
+// goroutines. It returns ErrForbiddenProcessName without registering any
+// process if a process name is not allowed, ErrProcessActive if a process id
+// is used by a process that has not finished, unless WithDeduplication skips
+// the process, ErrTotalProcessLimitReached if the pool holds too many
+// processes, and ErrQueueWeightExceeded if the processes do not fit in the
+// queue weight.
+func (w *workerPool) Register(args ...Process) error {
+	args = expandPipelines(args)
 	for _, p := range args {
-		ctx, cancel := context.WithCancel(context.Background())
-		w.controlPanel.put(p.PID(), &processContext{
-			ctx:    ctx,
-			cancel: cancel,
-		})
-		w.processes.put(p.PID(), ProcessStats{
-			Process: p,
-			Status:  process.Waiting,
-		})
+		if err := w.checkName(p); err != nil {
+			return err
+		}
+	}
+
+	var prepared []Process
+	err := w.claimPIDs(args, func(args []Process) error {
+		if err := w.checkDependencies(args); err != nil {
+			return err
+		}
+
+		if err := w.checkWorkers(args); err != nil {
+			return err
+		}
+
+		// With rate limit backpressure, each process takes a token before it
+		// is registered, so Register blocks until the rate limiter has
+		// capacity.
+		if w.limiter != nil && w.config.RateLimitBackpressure {
+			for _, p := range args {
+				if !w.limiter.wait(nil, w.closeSignal()) {
+					return w.pidError("register the process", ErrPoolClosed, p.PID())
+				}
+				if err := w.admit(p); err != nil {
+					return err
+				}
+				w.prepare(p)
+				w.publish(p)
+			}
+			return nil
+		}
+
+		if err := w.admit(args...); err != nil {
+			return err
+		}
+
+		// Create control panel for each process and make process stat for each of them.
+		for _, p := range args {
+			w.prepare(p)
+		}
+		prepared = args
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	args = prepared
+
+	// Spread the processes over the jitter window, each one is published by
+	// its own goroutine after a random delay.
+	if w.config.StartJitter > 0 {
+		for _, p := range args {
+			go w.publishWithJitter(p, w.config.StartJitter)
+		}
+		return nil
 	}
 
 	// Publish processes to the queue.
-	go func(args ...Process) {
-		for i := range args {
-			w.mutex.Lock()
-			if w.isClosed {
-				break
+	for _, p := range args {
+		if !w.publish(p) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// checkName returns ErrForbiddenProcessName if the process name is denied or
+// it is not in the allowlist.
+func (w *workerPool) checkName(p Process) error {
+	if w.allowed != nil {
+		if _, ok := w.allowed[p.Name()]; !ok {
+			return fmt.Errorf("%w: %s", ErrForbiddenProcessName, p.Name())
+		}
+	}
+
+	if _, ok := w.denied[p.Name()]; ok {
+		return fmt.Errorf("%w: %s", ErrForbiddenProcessName, p.Name())
+	}
+
+	return nil
+}
+
+// prepare creates the control panel and the stats of the process.
+func (w *workerPool) prepare(p Process) {
+	now := time.Now()
+	stats := ProcessStats{
+		Process:    p,
+		Status:     process.Waiting,
+		Priority:   processPriority(p),
+		Cycle:      int(atomic.LoadInt64(&w.cycle)),
+		Group:      processGroup(p),
+		EnqueuedAt: now,
+		updatedAt:  now,
+	}
+	// The previous run is replaced before its control panel, which holds
+	// the processes that have been skipped during the run.
+	w.replace(p, &stats)
+	ctx, cancel := context.WithCancel(context.Background())
+	w.controlPanel.put(p.PID(), &processContext{
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	})
+	if deps := dependencies(p); len(deps) > 0 {
+		stats.Status = process.Pending
+		stats.DependsOn = deps
+		stats.BlockedBy = append([]PID(nil), deps...)
+	}
+	w.processes.put(p.PID(), stats)
+	w.counters.register()
+	w.notify()
+}
+
+// publish adds the process to the queue, or to the inbox of its worker if it
+// is pinned by WithWorker, or waits for its dependencies first if the process
+// is Pending. It returns false if the pool is closed.
+func (w *workerPool) publish(p Process) bool {
+	if w.processes.get(p.PID()).Status == process.Pending {
+		go w.awaitDependencies(p)
+		return !w.queue.isClosed()
+	}
+
+	if name, ok := pinnedWorker(p); ok {
+		return w.dispatch(p, name)
+	}
+
+	if !w.queue.push(p) {
+		return false
+	}
+	w.wake()
+
+	return true
+}
+
+// publishWithJitter waits for a random duration in [0, window) and then
+// publishes the process. It gives up if the pool is closed meanwhile, and
+// cancels the process if its sub-pool is closed.
+func (w *workerPool) publishWithJitter(p Process, window time.Duration) {
+	done := w.closeSignal()
+	closing := w.subPools.closing()
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(window)))) //nolint:gosec
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			w.publish(p)
+			return
+		case <-closing:
+			if w.subPools.check([]Process{p}) != nil {
+				w.cancel(p)
+				return
 			}
-			w.queue <- args[i]
-			w.mutex.Unlock()
+			closing = w.subPools.closing()
+		case <-done:
+			return
 		}
-	}(args...)
+	}
 }
 
 // Close stops a running pool. It returns an error if the pool is not running.
 // Close waits for all workers to finish their current job and then closes the
-// pool.
+// pool. The processes that are still waiting in the queue are not executed,
+// they are Cancelled with ErrPoolClosed. Use CloseGraceful to run them before
+// the pool is closed.
 func (w *workerPool) Close() error {
-	if w.status != pool.Running {
-		return errors.New("pool is not running, status " + w.status.String())
+	return w.CloseContext(context.Background())
+}
+
+// CloseContext closes the pool like Close, but waits for the running
+// processes only until ctx is done. The processes that are still running
+// then are cancelled and finished as Cancelled without waiting for them to
+// return, and CloseContext returns the context error with their ids.
+func (w *workerPool) CloseContext(ctx context.Context) error {
+	if status := w.PoolStatus(); !status.IsOpen() {
+		return w.poolError("close the pool", ErrPoolNotRunning, pool.Running)
 	}
 
 	w.mutex.Lock()
-	w.isClosed = true
-	close(w.queue)
+	// The pool can be closed concurrently by its context and by the caller,
+	// only one of them closes it.
+	select {
+	case <-w.done:
+		w.mutex.Unlock()
+		return w.poolError("close the pool", ErrPoolClosed, pool.Running)
+	default:
+	}
+	w.beginDrainAudit()
+	w.queue.close()
+	close(w.done)
 	w.mutex.Unlock()
 
-	w.wg.Wait()
-	w.status = pool.Closed
+	forced := w.awaitWorkers(ctx)
+	w.mutex.Lock()
+	w.endDrainAudit()
+	w.mutex.Unlock()
+	w.cancelWaiting()
+	w.completions.close()
+	if w.limiter != nil && w.config.TokenStore != nil {
+		if err := w.config.TokenStore.Save(w.limiter.available()); err != nil {
+			w.log(levelWarn, "unable to save the rate limit tokens", Field{"error", err})
+		}
+	}
+	w.setStatus(pool.Closed)
+	w.emit(PoolClosed, "", "")
+
+	if len(forced) > 0 {
+		ids := make([]string, 0, len(forced))
+		for _, pid := range forced {
+			ids = append(ids, string(pid))
+		}
+		return fmt.Errorf("%w: running processes have been cancelled: %s", ctx.Err(), strings.Join(ids, ", "))
+	}
 
 	return nil
 }
 
+// awaitWorkers waits for the workers to stop. If ctx is done first, it
+// cancels the running processes, finishes them as Cancelled and releases
+// their workers from the pool, so it does not wait for the processes that
+// ignore the cancellation. It returns the ids of those processes.
+func (w *workerPool) awaitWorkers(ctx context.Context) []PID {
+	exited := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(exited)
+	}()
+
+	select {
+	case <-exited:
+		return nil
+	case <-ctx.Done():
+	}
+
+	running := make([]PID, 0)
+	w.processes.each(func(pid PID, stats ProcessStats) {
+		if stats.Status == process.Running {
+			running = append(running, pid)
+		}
+	})
+	sortPIDs(running)
+
+	forced := make([]PID, 0, len(running))
+	for _, pid := range running {
+		pc := w.controlPanel.get(pid)
+		if !pc.detach() {
+			continue
+		}
+		pc.cancel()
+
+		stats := w.processes.get(pid)
+		stats.Status = process.Cancelled
+		stats.err = fmt.Errorf("%w: %v", ErrPoolClosed, ctx.Err())
+		stats.FinishedAt = time.Now()
+		stats.updatedAt = stats.FinishedAt
+		w.workersStats.delete(stats.WorkerName)
+		w.workerCounters.delete(stats.WorkerName)
+		w.finish(stats.Process, stats)
+		w.log(levelWarn, "running process has been cancelled by close", Field{"pid", pid},
+			Field{"worker", stats.WorkerName})
+		w.wg.Done()
+		forced = append(forced, pid)
+	}
+	<-exited
+
+	return forced
+}
+
+// cancelWaiting finishes the processes that are left in the closed queue as
+// Cancelled.
+func (w *workerPool) cancelWaiting() {
+	for _, p := range w.queue.drain() {
+		w.cancel(p)
+	}
+}
+
+// cancel finishes the process that leaves the pool without being run because
+// the pool has been closed. A process that has been killed meanwhile stays
+// Killed.
+func (w *workerPool) cancel(p Process) {
+	pc := w.controlPanel.get(p.PID())
+	stats := w.processes.get(p.PID())
+	if pc.ctx.Err() != nil {
+		stats.Status = process.Killed
+		w.abandon(p, stats)
+		return
+	}
+
+	pc.cancel()
+	stats.Status = process.Cancelled
+	if stats.err == nil {
+		stats.err = ErrPoolClosed
+	}
+	w.abandon(p, stats)
+}
+
+// CloseGraceful waits until every registered process reaches a final state
+// and then closes the pool. It returns a *MultiError that aggregates the
+// errors of the processes that finished during the drain, or nil if all of
+// them succeeded. An errored pool is closed once its running processes are
+// finished, and a paused pool is resumed to drain. It returns an error if the
+// pool is not running.
+func (w *workerPool) CloseGraceful() error {
+	status := w.PoolStatus()
+	if !status.IsOpen() {
+		return w.poolError("close the pool", ErrPoolNotRunning, pool.Running)
+	}
+
+	// The waiting processes of a paused pool need the workers to drain.
+	if status == pool.Paused {
+		if err := w.Resume(); err != nil {
+			return err
+		}
+	}
+
+	w.mutex.Lock()
+	w.beginDrainAudit()
+	w.mutex.Unlock()
+
+	// Remember the processes that are not finished when the drain starts.
+	pending := make([]PID, 0)
+	w.processes.each(func(pid PID, stats ProcessStats) {
+		if !stats.Status.IsTerminal() {
+			pending = append(pending, pid)
+		}
+	})
+
+	// The waiting processes of an errored pool never run, so they are not
+	// drained.
+	if err := w.AwaitIdle(context.Background()); err != nil && !errors.Is(err, ErrMaxErrorsReached) {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	drained := make([]ProcessStats, 0, len(pending))
+	for _, pid := range pending {
+		drained = append(drained, w.processes.get(pid))
+	}
+
+	return processErrors(drained)
+}
+
+// processErrors returns a *MultiError that aggregates the errors of the
+// failed processes in the order they finished, or nil if none of them failed.
+func processErrors(list []ProcessStats) error {
+	failed := make([]ProcessStats, 0)
+	for _, stats := range list {
+		if stats.Status.IsError() && stats.err != nil {
+			failed = append(failed, stats)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	sort.Slice(failed, func(i, j int) bool {
+		return failed[i].FinishedAt.Before(failed[j].FinishedAt)
+	})
+	errs := make([]error, 0, len(failed))
+	for _, stats := range failed {
+		errs = append(errs, stats.err)
+	}
+
+	return &MultiError{Errors: errs}
+}
+
+// Lock prevents any further configuration change of the running pool. After
+// Lock, the methods that change the configuration, such as Resize, Scale,
+// EnsureWorkers, and Throttle, return ErrPoolLocked. It returns an error if
+// the pool is not running.
+func (w *workerPool) Lock() error {
+	if status := w.PoolStatus(); status != pool.Running {
+		return w.poolError("lock the pool", ErrPoolNotRunning, pool.Running)
+	}
+
+	atomic.StoreInt32(&w.locked, 1)
+
+	return nil
+}
+
+// Version returns the configuration version of the pool. It is 1 for a new
+// pool and it is incremented by each configuration change, such as Resize,
+// Scale, EnsureWorkers, and Throttle, so a pool whose configuration is shared
+// can detect a stale copy.
+func (w *workerPool) Version() int64 {
+	return atomic.LoadInt64(&w.version)
+}
+
+// configurable returns an error if the pool configuration cannot be changed.
+func (w *workerPool) configurable() error {
+	if status := w.PoolStatus(); status != pool.Running {
+		return w.poolError("configure the pool", ErrPoolNotRunning, pool.Running)
+	}
+
+	if atomic.LoadInt32(&w.locked) == 1 {
+		return w.opError("configure the pool", ErrPoolLocked)
+	}
+
+	return nil
+}
+
+// reportHealth passes a stats snapshot to the reporter every interval until
+// the pool is closed.
+func (w *workerPool) reportHealth(interval time.Duration, reporter func(PoolStats)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	done := w.closeSignal()
+	for {
+		select {
+		case <-ticker.C:
+			reporter(w.Stats())
+		case <-done:
+			return
+		}
+	}
+}
+
 // WorkerList returns the list of worker names of the pool.
 func (w *workerPool) WorkerList() []WorkerName {
-	return w.workers
+	w.workersMutex.RLock()
+	defer w.workersMutex.RUnlock()
+
+	workers := make([]WorkerName, len(w.workers))
+	copy(workers, w.workers)
+
+	return workers
+}
+
+// Kill cancel a process before it starts. A waiting process of a paused pool
+// is removed from the queue and finished as Killed right away. The id of a
+// process that has been registered with BroadcastRegister kills all its
+// copies. It returns ErrProcessNotFound if the process is not registered or
+// its stats have been purged.
+func (w *workerPool) Kill(pid PID) error {
+	return w.KillWithReason(pid, nil)
+}
+
+// KillWithReason kills the process like Kill and records reason as the error
+// of the process, so Monitor.Error returns it instead of the error that the
+// killed process has returned. The reason is ignored if the process has
+// already been killed or has finished.
+func (w *workerPool) KillWithReason(pid PID, reason error) error {
+	pc := w.controlPanel.get(pid)
+	if copies := w.broadcast(pid, nil); len(copies) > 0 && pc == nil {
+		for _, stats := range copies {
+			_ = w.KillWithReason(stats.Process.PID(), reason)
+		}
+		return nil
+	}
+
+	if pc == nil {
+		return w.pidError("kill the process", ErrProcessNotFound, pid)
+	}
+
+	pc.kill(reason)
+	if w.PoolStatus() == pool.Paused {
+		w.evict(pid)
+	}
+
+	return nil
 }
 
-// Kill cancel a process before it starts.
-func (w *workerPool) Kill(pid PID) {
-	w.controlPanel.get(pid).cancel()
+// KillWait cancels the process like Kill and waits up to timeout for its
+// Start method to return, so the caller knows that the cleanup of the process
+// is finished. It returns ErrKillTimeout if the process is still running
+// after timeout, and ErrProcessNotFound if the process is not registered.
+func (w *workerPool) KillWait(pid PID, timeout time.Duration) error {
+	pc := w.controlPanel.get(pid)
+	if pc == nil {
+		return w.pidError("kill the process", ErrProcessNotFound, pid)
+	}
+
+	_ = w.Kill(pid)
+	select {
+	case <-pc.done:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("%w: %s", ErrKillTimeout, pid)
+	}
 }
 
 // Monitor returns pool monitor.
@@ -258,6 +1081,81 @@ func (w *workerPool) Monitor() Monitor {
 	return w
 }
 
+// Stats returns a snapshot of the pool counters. The snapshot is taken at
+// the end of each state change of the pool, so Stats does not take any lock
+// and can be polled at a high rate without contending with the workers.
+func (w *workerPool) Stats() PoolStats {
+	snapshot, _ := w.snapshot.Load().(*statsSnapshot)
+	if snapshot == nil {
+		return PoolStats{}
+	}
+
+	stats := snapshot.stats
+	if !snapshot.startedAt.IsZero() {
+		stats.Uptime = time.Since(snapshot.startedAt)
+	}
+
+	return stats
+}
+
+// validate checks the process input if the process implements
+// ValidatingProcess.
+func validate(p Process) error {
+	var err error
+	findLayer(p, func(l Process) bool {
+		vp, ok := l.(ValidatingProcess)
+		if ok {
+			err = vp.ValidateInput()
+		}
+		return ok
+	})
+
+	return err
+}
+
+// findLayer calls fn for the process and then for each process that it
+// wraps, until fn returns true. It returns false if fn never returns true.
+// It lets the process wrappers keep the optional interfaces of the processes
+// that they wrap, such as ValidatingProcess and ResultExtractor.
+func findLayer(p Process, fn func(Process) bool) bool {
+	for p != nil {
+		if fn(p) {
+			return true
+		}
+		pw, ok := p.(processWrapper)
+		if !ok {
+			return false
+		}
+		p = pw.unwrap()
+	}
+
+	return false
+}
+
+// processName returns the sanitized name of the process.
+func (w *workerPool) processName(p Process) string {
+	return w.sanitize(p.Name())
+}
+
+// sanitize transforms the process name with the NameSanitizer of the pool.
+func (w *workerPool) sanitize(name string) string {
+	if w.config.NameSanitizer == nil {
+		return name
+	}
+
+	return w.config.NameSanitizer(name)
+}
+
+// toSet converts a list of names to a set.
+func toSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+
+	return set
+}
+
 // String returns the string value of process id.
 func (p PID) String() string {
 	return string(p)
@@ -265,12 +1163,32 @@ func (p PID) String() string {
 
 // PoolStatus returns pool status
 func (w *workerPool) PoolStatus() pool.Status {
+	w.statusMutex.RLock()
+	defer w.statusMutex.RUnlock()
+
 	return w.status
 }
 
-// Error returns process's error by process id.
-func (w *workerPool) Error(pid PID) error {
-	return w.processes.get(pid).err
+// setStatus changes the pool status.
+func (w *workerPool) setStatus(status pool.Status) {
+	w.statusMutex.Lock()
+	defer w.statusMutex.Unlock()
+
+	w.transition(status)
+}
+
+// transition changes the pool status and records the change in the status
+// history. The caller must hold the status mutex.
+func (w *workerPool) transition(status pool.Status) {
+	w.status = status
+	w.history = append(w.history, statusChange{status: status, at: time.Now()})
+}
+
+// Error returns process's error by process id. Like a map lookup, it returns
+// false if the process is not registered.
+func (w *workerPool) Error(pid PID) (error, bool) { //nolint:stylecheck
+	stats, ok := w.ProcessStats(pid)
+	return stats.err, ok
 }
 
 // WorkerStatus returns worker status. It accepts worker name as input.
@@ -278,7 +1196,14 @@ func (w *workerPool) WorkerStatus(name WorkerName) worker.Status {
 	return w.workersStats.get(name)
 }
 
-// ProcessStats returns process stats. It accepts process id as input.
-func (w *workerPool) ProcessStats(pid PID) ProcessStats {
-	return w.processes.get(pid)
+// ProcessStats returns process stats. It accepts process id as input. Like a
+// map lookup, it returns false if the process is not registered, so an
+// unknown process is not mistaken for a Waiting one.
+func (w *workerPool) ProcessStats(pid PID) (ProcessStats, bool) {
+	stats := w.processes.get(pid)
+	if pc := w.controlPanel.get(pid); pc != nil && stats.Process != nil {
+		stats.History = pc.withSkipped(stats.History)
+	}
+
+	return stats, stats.Process != nil
 }
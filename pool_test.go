@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -49,16 +50,25 @@ func newTestProcess(name string, id int, duration time.Duration, f pTestFunc) Pr
 	}
 }
 
+type validatingProcess struct {
+	Process
+	err error
+}
+
+func (v validatingProcess) ValidateInput() error {
+	return v.err
+}
+
 var errCancelled = errors.New("task was cancelled")
 
 // Close pool before adding all processes to the queue
 func TestNewPool(t *testing.T) {
 	a := assert.New(t)
-	wp := NewPool(2)
+	wp := NewPool(WithWorkerCount(2))
 
 	a.Equal(pool.Created, wp.Monitor().PoolStatus())
 	wp.Register(createProcess(10, 1, 300*time.Millisecond, processFunc)...)
-	err := wp.Start()
+	err := wp.Start(context.Background())
 	a.NoError(err)
 	a.Equal(pool.Running, wp.Monitor().PoolStatus())
 	time.Sleep(500 * time.Millisecond)
@@ -70,9 +80,9 @@ func TestNewPool(t *testing.T) {
 // Four different goroutine will publish processes to the queue
 func TestNewPoolMultiPublisher(t *testing.T) {
 	a := assert.New(t)
-	wp := NewPool(2)
+	wp := NewPool(WithWorkerCount(2))
 	a.Equal(pool.Created, wp.Monitor().PoolStatus())
-	err := wp.Start()
+	err := wp.Start(context.Background())
 	a.NoError(err)
 	a.Equal(pool.Running, wp.Monitor().PoolStatus())
 	wp.Register(createProcess(10, 1, 300*time.Millisecond, processFunc)...)
@@ -80,7 +90,8 @@ func TestNewPoolMultiPublisher(t *testing.T) {
 	wp.Register(createProcess(10, 3, 100*time.Millisecond, processFunc)...)
 	wp.Register(createProcess(10, 4, 500*time.Millisecond, processFunc)...)
 
-	time.Sleep(10 * time.Second)
+	wp.Wait()
+	a.Equal(int64(40), wp.Stats().TotalSucceeded)
 	err = wp.Close()
 	a.NoError(err)
 	a.Equal(pool.Closed, wp.Monitor().PoolStatus())
@@ -89,26 +100,26 @@ func TestNewPoolMultiPublisher(t *testing.T) {
 // Kill a processFunc before it starts
 func TestWorkerPool_Kill(t *testing.T) {
 	a := assert.New(t)
-	wp := NewPool(5)
+	wp := NewPool(WithWorkerCount(5))
 	a.Equal(pool.Created, wp.Monitor().PoolStatus())
-	err := wp.Start()
+	err := wp.Start(context.Background())
 	a.NoError(err)
 	a.Equal(pool.Running, wp.Monitor().PoolStatus())
 	wp.Register(createProcess(10, 1, 3*time.Second, processFunc)...)
-	wp.Kill("p-18")
-	time.Sleep(7 * time.Second)
+	a.NoError(wp.Kill("p-18"))
+	wp.Wait()
 	err = wp.Close()
 	a.NoError(err)
 	a.Equal(pool.Closed, wp.Monitor().PoolStatus())
-	a.Equal(process.Killed, wp.Monitor().ProcessStats("p-18").Status)
+	a.Equal(process.Killed, processStats(t, wp.Monitor(), "p-18").Status)
 }
 
 // Kill a processFunc after it started
 func TestWorkerPoolStarted_Kill(t *testing.T) {
 	a := assert.New(t)
-	wp := NewPool(3)
+	wp := NewPool(WithWorkerCount(3))
 	a.Equal(pool.Created, wp.Monitor().PoolStatus())
-	err := wp.Start()
+	err := wp.Start(context.Background())
 	a.NoError(err)
 	a.Equal(pool.Running, wp.Monitor().PoolStatus())
 	wp.Register(createProcess(3, 1, 3*time.Second, processFunc)...)
@@ -117,17 +128,128 @@ func TestWorkerPoolStarted_Kill(t *testing.T) {
 	err = wp.Close()
 	a.NoError(err)
 	a.Equal(pool.Closed, wp.Monitor().PoolStatus())
-	a.Equal(process.Killed, wp.Monitor().ProcessStats("p-12").Status)
-	a.Error(wp.Monitor().Error("p-12"))
-	a.Equal("task was cancelled", wp.Monitor().Error("p-12").Error())
+	a.Equal(process.Killed, processStats(t, wp.Monitor(), "p-12").Status)
+	a.Error(processError(t, wp.Monitor(), "p-12"))
+	a.Equal("task was cancelled", processError(t, wp.Monitor(), "p-12").Error())
+}
+
+// The monitor should tell an unknown process from a waiting one
+func TestWorkerPool_ProcessStatsNotFound(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Register(newTestProcess("waiting", 1, 0, processFuncWithoutLog)))
+
+	stats, ok := wp.Monitor().ProcessStats("p-1")
+	a.True(ok)
+	a.Equal(process.Waiting, stats.Status)
+	err, ok := wp.Monitor().Error("p-1")
+	a.True(ok)
+	a.NoError(err)
+
+	stats, ok = wp.Monitor().ProcessStats("unknown")
+	a.False(ok)
+	a.Equal(ProcessStats{}, stats)
+	_, ok = wp.Monitor().Error("unknown")
+	a.False(ok)
+}
+
+// Kill a process with a reason that overrides the process error
+func TestWorkerPool_KillWithReason(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(createProcess(2, 1, time.Minute, processFuncWithoutLog)...))
+	_, err := wp.WaitUntilStatus(context.Background(), "p-11", process.Running)
+	a.NoError(err)
+
+	deployment := errors.New("killed: new deployment")
+	wp.KillWithReason("p-11", deployment)
+	wp.KillWithReason("p-11", errors.New("killed: twice"))
+	rateLimit := errors.New("killed: rate-limit exceeded")
+	wp.KillWithReason("p-12", rateLimit)
+	a.Error(wp.Wait())
+
+	m := wp.Monitor()
+	a.Equal(process.Killed, processStats(t, m, "p-11").Status)
+	a.Equal(deployment, processError(t, m, "p-11"))
+	a.Equal(process.Killed, processStats(t, m, "p-12").Status)
+	a.Equal(rateLimit, processError(t, m, "p-12"))
+	a.NoError(wp.Close())
+}
+
+// Kill should return ErrProcessNotFound for an unknown or a purged process
+func TestWorkerPool_KillNotFound(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+
+	err := wp.Kill("nope")
+	a.ErrorIs(err, ErrProcessNotFound)
+	var poolErr *PoolError
+	a.ErrorAs(err, &poolErr)
+	a.Equal(PID("nope"), poolErr.PID)
+	a.ErrorIs(wp.KillWithReason("nope", errors.New("reason")), ErrProcessNotFound)
+
+	a.NoError(wp.Register(newTestProcess("job", 1, 0, processFuncWithoutLog)))
+	a.NoError(wp.Wait())
+	a.Equal(1, wp.Monitor().Purge(time.Now()))
+	a.ErrorIs(wp.Kill("p-1"), ErrProcessNotFound)
+
+	a.NoError(wp.Close())
+}
+
+// Close should cancel the waiting processes and keep Killed for Kill
+func TestWorkerPool_CloseCancelled(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(createProcess(4, 1, 100*time.Millisecond, processFuncWithoutLog)...))
+	_, err := wp.WaitUntilStatus(context.Background(), "p-11", process.Running)
+	a.NoError(err)
+	wp.Kill("p-12")
+
+	a.NoError(wp.Close())
+	m := wp.Monitor()
+	a.Equal(process.Succeeded, processStats(t, m, "p-11").Status)
+	a.Equal(process.Killed, processStats(t, m, "p-12").Status)
+	for _, pid := range []PID{"p-13", "p-14"} {
+		a.Equal(process.Cancelled, processStats(t, m, pid).Status)
+		a.ErrorIs(processError(t, m, pid), ErrPoolClosed)
+	}
+	a.Equal(int64(1), wp.Stats().TotalKilled)
+	a.Equal(int64(2), wp.Stats().TotalCancelled)
+}
+
+// Each configuration change should increment the pool version
+func TestWorkerPool_Version(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2), WithRateLimit(100, 1))
+	a.NoError(wp.Start(context.Background()))
+	a.Equal(int64(1), wp.Version())
+
+	a.NoError(wp.Resize(3))
+	a.Equal(int64(2), wp.Version())
+	a.NoError(wp.Scale(1))
+	a.Equal(int64(3), wp.Version())
+	a.NoError(wp.EnsureWorkers(2))
+	a.Equal(int64(3), wp.Version())
+	a.NoError(wp.Throttle(0.5))
+	a.Equal(int64(4), wp.Version())
+	a.ErrorIs(wp.Resize(-1), ErrInvalidArgument)
+	a.Equal(int64(4), wp.Version())
+
+	a.NoError(wp.Lock())
+	a.Error(wp.Scale(1))
+	a.Equal(int64(4), wp.Version())
+	a.NoError(wp.Close())
 }
 
 // Process returns error and monitor should cache it
 func TestMonitor_Error(t *testing.T) {
 	a := assert.New(t)
-	wp := NewPool(5)
+	wp := NewPool(WithWorkerCount(5))
 	a.Equal(pool.Created, wp.Monitor().PoolStatus())
-	err := wp.Start()
+	err := wp.Start(context.Background())
 	a.NoError(err)
 	a.Equal(pool.Running, wp.Monitor().PoolStatus())
 	wp.Register(createProcess(1, 1, 1*time.Second, processFuncWithError)...)
@@ -135,20 +257,20 @@ func TestMonitor_Error(t *testing.T) {
 	err = wp.Close()
 	a.NoError(err)
 	a.Equal(pool.Closed, wp.Monitor().PoolStatus())
-	a.Equal(process.Failed, wp.Monitor().ProcessStats("p-11").Status)
-	a.Error(wp.Monitor().Error("p-11"))
-	a.Equal("unable to start processFunc with id: p-11", wp.Monitor().Error("p-11").Error())
+	a.Equal(process.Failed, processStats(t, wp.Monitor(), "p-11").Status)
+	a.Error(processError(t, wp.Monitor(), "p-11"))
+	a.Equal("unable to start processFunc with id: p-11", processError(t, wp.Monitor(), "p-11").Error())
 }
 
 // Close a created pool should return error
 func TestWorkerPool_Close(t *testing.T) {
 	a := assert.New(t)
-	wp := NewPool(3)
+	wp := NewPool(WithWorkerCount(3))
 	a.Equal(pool.Created, wp.Monitor().PoolStatus())
 	err := wp.Close()
 	a.Error(err)
-	a.Equal("pool is not running, status "+wp.Monitor().PoolStatus().String(), err.Error())
-	err = wp.Start()
+	a.ErrorIs(err, ErrPoolNotRunning)
+	err = wp.Start(context.Background())
 	a.NoError(err)
 	a.Equal(pool.Running, wp.Monitor().PoolStatus())
 	wp.Register(createProcess(1, 1, 100*time.Millisecond, processFunc)...)
@@ -158,22 +280,147 @@ func TestWorkerPool_Close(t *testing.T) {
 	a.Equal(pool.Closed, wp.Monitor().PoolStatus())
 }
 
+// CloseGraceful should wait for the queue and aggregate the process errors
+func TestWorkerPool_CloseGraceful(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	err := wp.CloseGraceful()
+	a.Error(err)
+	err = wp.Start(context.Background())
+	a.NoError(err)
+	err = wp.Register(createProcess(3, 1, 0, processFuncWithError)...)
+	a.NoError(err)
+	err = wp.Register(createProcess(2, 2, 100*time.Millisecond, processFunc)...)
+	a.NoError(err)
+
+	err = wp.CloseGraceful()
+	a.Equal(pool.Closed, wp.Monitor().PoolStatus())
+	a.Equal(int64(2), wp.Stats().TotalSucceeded)
+	var multiErr *MultiError
+	a.True(errors.As(err, &multiErr))
+	a.Len(multiErr.Errors, 3)
+}
+
+// The timestamps should be zero until their event occurs
+func TestProcessStats_Timestamps(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Register(newTestProcess("timed", 1, 20*time.Millisecond, processFuncWithoutLog)))
+
+	stats := processStats(t, wp.Monitor(), "p-1")
+	a.False(stats.EnqueuedAt.IsZero())
+	a.True(stats.StartedAt.IsZero())
+	a.True(stats.FinishedAt.IsZero())
+	a.Zero(stats.QueueWaitDuration())
+	a.Zero(stats.ExecutionDuration())
+
+	time.Sleep(10 * time.Millisecond)
+	a.NoError(wp.Start(context.Background()))
+	_, err := wp.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.NoError(err)
+	stats = processStats(t, wp.Monitor(), "p-1")
+	a.False(stats.StartedAt.IsZero())
+	a.True(stats.FinishedAt.IsZero())
+	a.GreaterOrEqual(stats.QueueWaitDuration(), 10*time.Millisecond)
+	a.Zero(stats.ExecutionDuration())
+
+	a.NoError(wp.Wait())
+	stats = processStats(t, wp.Monitor(), "p-1")
+	a.GreaterOrEqual(stats.ExecutionDuration(), 20*time.Millisecond)
+	a.Equal(stats.FinishedAt.Sub(stats.StartedAt), stats.ExecutionDuration())
+	a.NoError(wp.Close())
+}
+
+// A process that waits for its retry should not look finished
+func TestProcessStats_TimestampsRetry(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(WithRetry(newTestProcess("flaky", 1, 0, processFuncWithError), 2,
+		FixedBackoff{Delay: time.Second})))
+
+	_, err := wp.WaitUntilStatus(context.Background(), "p-1", process.Retrying)
+	a.NoError(err)
+	stats := processStats(t, wp.Monitor(), "p-1")
+	a.False(stats.StartedAt.IsZero())
+	a.True(stats.FinishedAt.IsZero())
+
+	a.NoError(wp.Close())
+	stats = processStats(t, wp.Monitor(), "p-1")
+	a.Equal(process.Failed, stats.Status)
+	a.False(stats.FinishedAt.IsZero())
+}
+
+// CloseContext should cancel the processes that ignore the cancellation
+// once the drain deadline is over
+func TestWorkerPool_CloseContext(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Start(context.Background()))
+	hung := func(ctx context.Context, pid PID, d time.Duration) error {
+		time.Sleep(d)
+		return nil
+	}
+	a.NoError(wp.Register(
+		newTestProcess("hung", 1, time.Second, hung),
+		newTestProcess("quick", 2, 10*time.Millisecond, processFuncWithoutLog),
+		newTestProcess("waiting", 3, 0, processFuncWithoutLog),
+	))
+	_, err := wp.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.NoError(err)
+	_, err = wp.WaitUntilStatus(context.Background(), "p-2", process.Running)
+	a.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = wp.CloseContext(ctx)
+	a.Less(time.Since(start), 500*time.Millisecond)
+	a.ErrorIs(err, context.DeadlineExceeded)
+	a.Contains(err.Error(), "p-1")
+	a.NotContains(err.Error(), "p-2")
+
+	m := wp.Monitor()
+	a.Equal(pool.Closed, m.PoolStatus())
+	a.Equal(process.Cancelled, processStats(t, m, "p-1").Status)
+	a.ErrorIs(processError(t, m, "p-1"), ErrPoolClosed)
+	a.Equal(process.Succeeded, processStats(t, m, "p-2").Status)
+	a.Equal(process.Cancelled, processStats(t, m, "p-3").Status)
+}
+
+// CloseContext should close the pool like Close if the workers finish in time
+func TestWorkerPool_CloseContextInTime(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.Error(wp.CloseContext(context.Background()))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(newTestProcess("quick", 1, 10*time.Millisecond, processFuncWithoutLog)))
+	_, err := wp.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	a.NoError(wp.CloseContext(ctx))
+	a.Equal(pool.Closed, wp.Monitor().PoolStatus())
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-1").Status)
+}
+
 // Get worker list and check their status
 func TestWorkerPool_WorkerList(t *testing.T) {
 	a := assert.New(t)
-	wp := NewPool(3)
+	wp := NewPool(WithWorkerCount(3))
 	a.Equal(pool.Created, wp.Monitor().PoolStatus())
 	err := wp.Close()
 	a.Error(err)
-	a.Equal("pool is not running, status "+wp.Monitor().PoolStatus().String(), err.Error())
-	err = wp.Start()
+	a.ErrorIs(err, ErrPoolNotRunning)
+	err = wp.Start(context.Background())
 	a.NoError(err)
 	a.Equal(pool.Running, wp.Monitor().PoolStatus())
 	wp.Register(createProcess(5, 1, 700*time.Millisecond, processFunc)...)
 	time.Sleep(1 * time.Second)
-	err = wp.Start()
+	err = wp.Start(context.Background())
 	a.Error(err)
-	a.Equal("unable to start the pool, status: "+pool.Running.String(), err.Error())
+	a.ErrorIs(err, ErrPoolAlreadyRunning)
 	wList := wp.Monitor().WorkerList()
 	for _, wn := range wList {
 		fmt.Println(wp.Monitor().WorkerStatus(wn))
@@ -183,6 +430,176 @@ func TestWorkerPool_WorkerList(t *testing.T) {
 	a.Equal(pool.Closed, wp.Monitor().PoolStatus())
 }
 
+// Health reporter should receive a stats snapshot every interval
+func TestWithHealthReporter(t *testing.T) {
+	a := assert.New(t)
+	mu := new(sync.Mutex)
+	reports := make([]PoolStats, 0)
+	wp := NewPool(WithWorkerCount(2), WithHealthReporter(100*time.Millisecond, func(stats PoolStats) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, stats)
+	}))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(createProcess(4, 1, 50*time.Millisecond, processFunc)...)
+	time.Sleep(350 * time.Millisecond)
+	err = wp.Close()
+	a.NoError(err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	a.GreaterOrEqual(len(reports), 3)
+	last := reports[len(reports)-1]
+	a.Equal(int64(4), last.TotalRegistered)
+	a.Equal(int64(4), last.TotalSucceeded)
+	a.Equal(2, last.IdleWorkers)
+	a.Equal(1.0, last.SuccessRate())
+}
+
+// Process with invalid input should fail before it starts
+func TestValidatingProcess(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	started := make(chan struct{}, 1)
+	vp := validatingProcess{
+		Process: newTestProcess("p-1", 11, 0, func(ctx context.Context, pid PID, d time.Duration) error {
+			started <- struct{}{}
+			return nil
+		}),
+		err: errors.New("schema: field 'name' is required"),
+	}
+	wp.Register(vp)
+	time.Sleep(100 * time.Millisecond)
+	err = wp.Close()
+	a.NoError(err)
+	a.Equal(process.Failed, processStats(t, wp.Monitor(), "p-11").Status)
+	a.EqualError(processError(t, wp.Monitor(), "p-11"), "schema: field 'name' is required")
+	a.Len(started, 0)
+}
+
+// Start jitter should spread simultaneous processes over the window
+func TestWithStartJitter(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(50), WithStartJitter(time.Second))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(createProcess(50, 1, 0, processFunc)...)
+	time.Sleep(1200 * time.Millisecond)
+	err = wp.Close()
+	a.NoError(err)
+
+	var first, last time.Time
+	for i := 11; i <= 60; i++ {
+		stats := processStats(t, wp.Monitor(), PID("p-"+strconv.Itoa(i)))
+		a.Equal(process.Succeeded, stats.Status)
+		if first.IsZero() || stats.StartedAt.Before(first) {
+			first = stats.StartedAt
+		}
+		if stats.StartedAt.After(last) {
+			last = stats.StartedAt
+		}
+	}
+	a.Greater(last.Sub(first), 500*time.Millisecond)
+}
+
+// Register should block until the rate limiter has capacity
+func TestWithRateLimitBackpressure(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2), WithRateLimit(10, 1), WithRateLimitBackpressure())
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	start := time.Now()
+	for _, p := range createProcess(10, 1, 0, processFunc) {
+		wp.Register(p)
+	}
+	elapsed := time.Since(start)
+	a.InDelta(900*time.Millisecond, elapsed, float64(200*time.Millisecond))
+	a.Equal(int64(10), wp.Stats().TotalRegistered)
+
+	time.Sleep(100 * time.Millisecond)
+	err = wp.Close()
+	a.NoError(err)
+	a.Equal(int64(10), wp.Stats().TotalSucceeded)
+}
+
+// Register should reject the processes whose name is not allowed
+func TestWithAllowedProcessNames(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithAllowedProcessNames("safe-job"))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	err = wp.Register(newTestProcess("safe-job", 1, 0, processFunc), newTestProcess("dangerous-job", 2, 0, processFunc))
+	a.ErrorIs(err, ErrForbiddenProcessName)
+	err = wp.Register(newTestProcess("safe-job", 1, 0, processFunc))
+	a.NoError(err)
+	time.Sleep(100 * time.Millisecond)
+
+	err = wp.Close()
+	a.NoError(err)
+	a.Equal(int64(1), wp.Stats().TotalRegistered)
+}
+
+// Register should reject the processes whose name is denied
+func TestWithDeniedProcessNames(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithDeniedProcessNames("dangerous-job"))
+	err := wp.Register(newTestProcess("dangerous-job", 1, 0, processFunc))
+	a.ErrorIs(err, ErrForbiddenProcessName)
+	err = wp.Register(newTestProcess("safe-job", 2, 0, processFunc))
+	a.NoError(err)
+}
+
+// KillWait should wait for the cleanup of the killed process
+func TestWorkerPool_KillWait(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	cleanup := func(ctx context.Context, pid PID, d time.Duration) error {
+		<-ctx.Done()
+		time.Sleep(d)
+		return errCancelled
+	}
+	wp.Register(newTestProcess("cleanup", 1, 200*time.Millisecond, cleanup),
+		newTestProcess("cleanup", 2, 200*time.Millisecond, cleanup))
+	time.Sleep(50 * time.Millisecond)
+
+	a.NoError(wp.KillWait("p-1", 300*time.Millisecond))
+	a.Equal(process.Killed, processStats(t, wp.Monitor(), "p-1").Status)
+	a.ErrorIs(wp.KillWait("p-2", 100*time.Millisecond), ErrKillTimeout)
+	a.Equal(process.Running, processStats(t, wp.Monitor(), "p-2").Status)
+	a.ErrorIs(wp.KillWait("p-3", time.Second), ErrProcessNotFound)
+
+	wp.Wait()
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// processStats returns the stats of the process and fails the test if the
+// process is not registered.
+func processStats(t *testing.T, m Monitor, pid PID) ProcessStats {
+	t.Helper()
+	stats, ok := m.ProcessStats(pid)
+	assert.True(t, ok, "process %s is not registered", pid)
+
+	return stats
+}
+
+// processError returns the error of the process and fails the test if the
+// process is not registered.
+func processError(t *testing.T, m Monitor, pid PID) error {
+	t.Helper()
+	err, ok := m.Error(pid)
+	assert.True(t, ok, "process %s is not registered", pid)
+
+	return err
+}
+
 func createProcess(n int, g int, d time.Duration, f pTestFunc) []Process {
 	pList := make([]Process, 0)
 	for i := 1; i <= n; i++ {
@@ -201,6 +618,93 @@ func processFunc(ctx context.Context, pid PID, d time.Duration) error {
 	return nil
 }
 
+func processFuncWithoutLog(ctx context.Context, pid PID, d time.Duration) error {
+	select {
+	case <-time.After(d):
+	case <-ctx.Done():
+		return errCancelled
+	}
+	return nil
+}
+
 func processFuncWithError(ctx context.Context, pid PID, d time.Duration) error {
 	return errors.New("unable to start processFunc with id: " + pid.String())
 }
+
+// Stats should follow the state changes of the pool without locking
+func TestWorkerPool_Stats(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.Equal(PoolStats{}, wp.Stats())
+
+	a.NoError(wp.Start(context.Background()))
+	stats := wp.Stats()
+	a.Equal(2, stats.IdleWorkers)
+	a.Equal(0, stats.ActiveWorkers)
+
+	stop := make(chan struct{})
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				wp.Stats()
+			}
+		}
+	}()
+
+	a.NoError(wp.Register(createProcess(4, 1, 100*time.Millisecond, processFuncWithoutLog)...))
+	a.Equal(int64(4), wp.Stats().TotalRegistered)
+	a.Eventually(func() bool {
+		return wp.Stats().ActiveWorkers == 2
+	}, time.Second, 5*time.Millisecond)
+
+	a.Eventually(func() bool {
+		return wp.Stats().TotalSucceeded == 4
+	}, time.Second, 5*time.Millisecond)
+	close(stop)
+	<-polled
+
+	stats = wp.Stats()
+	a.Equal(int64(0), stats.QueueDepth)
+	a.Equal(2, stats.IdleWorkers)
+	a.Positive(stats.Uptime)
+	a.NoError(wp.Close())
+}
+
+// Cancelling the start context should close the pool after its running processes
+func TestWorkerPool_StartContext(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	a.NoError(wp.Start(ctx))
+	a.NoError(wp.Register(
+		newTestProcess("running", 1, 100*time.Millisecond, processFuncWithoutLog),
+		newTestProcess("waiting", 2, 100*time.Millisecond, processFuncWithoutLog),
+	))
+	a.Eventually(func() bool {
+		return processStats(t, wp.Monitor(), "p-1").Status == process.Running
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	a.Eventually(func() bool {
+		return wp.Monitor().PoolStatus() == pool.Closed
+	}, time.Second, 5*time.Millisecond)
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-1").Status)
+	a.Equal(process.Cancelled, processStats(t, wp.Monitor(), "p-2").Status)
+	a.Error(wp.Close())
+}
+
+// Closing the pool should stop watching the start context
+func TestWorkerPool_StartContextAfterClose(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	a.NoError(wp.Start(ctx))
+	a.NoError(wp.Close())
+	cancel()
+	a.Equal(pool.Closed, wp.Monitor().PoolStatus())
+}
@@ -0,0 +1,41 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+// priorityProcess wraps a process to give it an execution priority.
+type priorityProcess struct {
+	Process
+	priority int
+}
+
+// WithPriority wraps the process to give it an execution priority. The
+// processes with a higher priority are dispatched to the free workers before
+// the ones with a lower priority, and the processes with the same priority
+// keep their registration order. The default priority is 0, and a negative
+// priority runs after the processes without priority. Reorder can still
+// change the order of the waiting processes.
+func WithPriority(p Process, priority int) Process {
+	return priorityProcess{Process: p, priority: priority}
+}
+
+// unwrap returns the wrapped process.
+func (p priorityProcess) unwrap() Process {
+	return p.Process
+}
+
+// processPriority returns the priority of the process that is set by
+// WithPriority, or 0.
+func processPriority(p Process) int {
+	priority := 0
+	findLayer(p, func(l Process) bool {
+		pp, ok := l.(priorityProcess)
+		if ok {
+			priority = pp.priority
+		}
+		return ok
+	})
+
+	return priority
+}
@@ -0,0 +1,79 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Higher priority processes should run first, in FIFO order within a priority
+func TestWithPriority(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	mutex := new(sync.Mutex)
+	order := make([]PID, 0)
+	record := func(ctx context.Context, pid PID, d time.Duration) error {
+		mutex.Lock()
+		order = append(order, pid)
+		mutex.Unlock()
+		time.Sleep(d)
+		return nil
+	}
+
+	// The first process keeps the only worker busy while the others queue.
+	a.NoError(wp.RegisterSync(context.Background(), newTestProcess("blocker", 1, 50*time.Millisecond, record)))
+	for _, p := range []Process{
+		newTestProcess("background", 2, 0, record),
+		WithPriority(newTestProcess("probe", 3, 0, record), 10),
+		WithPriority(newTestProcess("request", 4, 0, record), 5),
+		WithPriority(newTestProcess("probe", 5, 0, record), 10),
+		WithPriority(newTestProcess("cleanup", 6, 0, record), -1),
+		newTestProcess("background", 7, 0, record),
+	} {
+		a.NoError(wp.Register(p))
+	}
+	a.Equal(10, processStats(t, wp.Monitor(), "p-3").Priority)
+	a.Zero(processStats(t, wp.Monitor(), "p-2").Priority)
+
+	a.NoError(wp.Wait())
+	a.Equal([]PID{"p-1", "p-3", "p-5", "p-4", "p-2", "p-7", "p-6"}, order)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Concurrent registrations should keep the queue ordered by priority
+func TestProcessQueue_PushPriority(t *testing.T) {
+	a := assert.New(t)
+	q := newProcessQueue()
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			q.push(WithPriority(newTestProcess("p", i, 0, processFuncWithoutLog), i%5))
+		}(i)
+	}
+	wg.Wait()
+
+	last := 5
+	for {
+		p, ok := q.pop()
+		if !ok {
+			break
+		}
+		a.LessOrEqual(processPriority(p), last)
+		last = processPriority(p)
+	}
+}
@@ -0,0 +1,64 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+// anonymousName is the name of the processes made by AnonymousProcess.
+const anonymousName = "anonymous"
+
+// funcProcess is a Process that runs a plain function.
+type funcProcess struct {
+	pid  PID
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// ProcessFunc returns a Process with the given id and name that runs fn, so
+// a simple task can be registered without defining a new type.
+func ProcessFunc(pid PID, name string, fn func(ctx context.Context) error) Process {
+	return funcProcess{
+		pid:  pid,
+		name: name,
+		fn:   fn,
+	}
+}
+
+// AnonymousProcess returns a Process that runs fn with a random UUID as its
+// id.
+func AnonymousProcess(fn func(ctx context.Context) error) Process {
+	return ProcessFunc(newUUID(), anonymousName, fn)
+}
+
+// Start runs the function.
+func (f funcProcess) Start(ctx context.Context) error {
+	return f.fn(ctx)
+}
+
+// Name returns the process name.
+func (f funcProcess) Name() string {
+	return f.name
+}
+
+// PID returns the process id.
+func (f funcProcess) PID() PID {
+	return f.pid
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() PID {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("unable to generate a process id: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return PID(fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]))
+}
@@ -0,0 +1,59 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// A plain function should run as a registered process
+func TestProcessFunc(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Start(context.Background()))
+
+	ran := make(chan struct{})
+	failure := errors.New("failure")
+	a.NoError(wp.Register(
+		ProcessFunc("p-1", "ok", func(ctx context.Context) error {
+			close(ran)
+			return nil
+		}),
+		ProcessFunc("p-2", "failing", func(ctx context.Context) error {
+			return failure
+		}),
+	))
+	_ = wp.Wait()
+
+	<-ran
+	m := wp.Monitor()
+	stats := processStats(t, m, "p-1")
+	a.Equal(process.Succeeded, stats.Status)
+	a.Equal("ok", stats.Process.Name())
+	a.Equal(process.Failed, processStats(t, m, "p-2").Status)
+	a.ErrorIs(processError(t, m, "p-2"), failure)
+	a.NoError(wp.Close())
+}
+
+// Anonymous processes should get distinct UUIDs
+func TestAnonymousProcess(t *testing.T) {
+	a := assert.New(t)
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	fn := func(ctx context.Context) error { return nil }
+
+	p1 := AnonymousProcess(fn)
+	p2 := AnonymousProcess(fn)
+	a.Regexp(uuid, p1.PID().String())
+	a.NotEqual(p1.PID(), p2.PID())
+	a.Equal(anonymousName, p1.Name())
+	a.NoError(p1.Start(context.Background()))
+}
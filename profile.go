@@ -0,0 +1,100 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"time"
+)
+
+// startCPUProfile starts the CPU profiler and returns a function that stops
+// it and returns the pprof encoded profile. The Go runtime supports only one
+// CPU profile at a time, so if the profiler is already in use, the returned
+// function returns nil.
+func startCPUProfile() func() []byte {
+	buf := new(bytes.Buffer)
+	if err := pprof.StartCPUProfile(buf); err != nil {
+		return func() []byte {
+			return nil
+		}
+	}
+
+	return func() []byte {
+		pprof.StopCPUProfile()
+		return buf.Bytes()
+	}
+}
+
+// profile writes a CPU and a heap profile to dir every interval until the
+// pool is closed.
+func (w *workerPool) profile(dir string, interval time.Duration) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	done := w.closeSignal()
+	stop := w.startCPUProfileFile(dir)
+	for {
+		select {
+		case <-ticker.C:
+			stop()
+			w.writeHeapProfile(dir)
+			stop = w.startCPUProfileFile(dir)
+		case <-done:
+			stop()
+			w.writeHeapProfile(dir)
+			return
+		}
+	}
+}
+
+// profilePath returns the path of a profile that is captured now.
+func profilePath(dir, ext string) string {
+	return filepath.Join(dir, time.Now().UTC().Format("20060102T150405.000000000")+ext)
+}
+
+// startCPUProfileFile starts the CPU profiler to write to a new file in dir
+// and returns a function that stops it and closes the file.
+func (w *workerPool) startCPUProfileFile(dir string) func() {
+	path := profilePath(dir, ".cpu")
+	f, err := os.Create(path)
+	if err != nil {
+		w.log(levelError, "unable to create the CPU profile", Field{"path", path}, Field{"error", err})
+		return func() {}
+	}
+
+	if err := pprof.StartCPUProfile(f); err != nil {
+		w.log(levelWarn, "unable to start the CPU profile", Field{"path", path}, Field{"error", err})
+		f.Close()       //nolint:errcheck
+		os.Remove(path) //nolint:errcheck
+		return func() {}
+	}
+
+	return func() {
+		pprof.StopCPUProfile()
+		if err := f.Close(); err != nil {
+			w.log(levelError, "unable to write the CPU profile", Field{"path", path}, Field{"error", err})
+		}
+	}
+}
+
+// writeHeapProfile writes a heap profile to a new file in dir.
+func (w *workerPool) writeHeapProfile(dir string) {
+	path := profilePath(dir, ".heap")
+	f, err := os.Create(path)
+	if err != nil {
+		w.log(levelError, "unable to create the heap profile", Field{"path", path}, Field{"error", err})
+		return
+	}
+	defer f.Close() //nolint:errcheck
+
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		w.log(levelError, "unable to write the heap profile", Field{"path", path}, Field{"error", err})
+	}
+}
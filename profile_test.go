@@ -0,0 +1,71 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// CPU tracking should store a CPU profile for a CPU bound process
+func TestWithCPUTracking(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithCPUTracking())
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(newTestProcess("busy-loop", 1, 300*time.Millisecond, busyLoop))
+	time.Sleep(500 * time.Millisecond)
+	err = wp.Close()
+	a.NoError(err)
+
+	stats := processStats(t, wp.Monitor(), "p-1")
+	a.Equal(process.Succeeded, stats.Status)
+	prof, err := profile.ParseData(stats.CPUProfile)
+	if a.NoError(err) {
+		a.NotEmpty(prof.Sample)
+	}
+}
+
+// The profiling mode should write CPU and heap profiles to the directory
+func TestWithProfilingMode(t *testing.T) {
+	a := assert.New(t)
+	dir := t.TempDir()
+	wp := NewPool(WithWorkerCount(4), WithProfilingMode(dir, 50*time.Millisecond))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(createProcess(20, 1, 20*time.Millisecond, busyLoop)...)
+	a.NoError(wp.Wait())
+	err = wp.Close()
+	a.NoError(err)
+
+	cpu, err := filepath.Glob(filepath.Join(dir, "*.cpu"))
+	a.NoError(err)
+	a.NotEmpty(cpu)
+	heap, err := filepath.Glob(filepath.Join(dir, "*.heap"))
+	a.NoError(err)
+	a.NotEmpty(heap)
+	for _, path := range append(cpu, heap...) {
+		info, err := os.Stat(path)
+		a.NoError(err)
+		a.NotZero(info.Size(), path)
+	}
+}
+
+func busyLoop(ctx context.Context, pid PID, d time.Duration) error {
+	deadline := time.Now().Add(d)
+	n := 0
+	for time.Now().Before(deadline) {
+		n++
+	}
+	return nil
+}
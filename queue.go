@@ -0,0 +1,340 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+)
+
+// processQueue is the ordered queue of the waiting processes. Workers take
+// processes from the front. Unlike a channel, the queue can be inspected and
+// reordered while the processes are waiting.
+type processQueue struct {
+	mutex   sync.Mutex
+	items   []Process
+	closed  bool
+	frozen  bool
+	halted  bool
+	paused  bool
+	changes *broadcaster
+}
+
+// newProcessQueue makes a new instance of processQueue.
+func newProcessQueue() *processQueue {
+	return &processQueue{
+		items:   make([]Process, 0),
+		changes: newBroadcaster(),
+	}
+}
+
+// push adds the process behind the processes with the same or a higher
+// priority, which is the back of the queue for processes without priority.
+// It returns false if the queue is closed.
+func (q *processQueue) push(p Process) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return false
+	}
+	q.insert(p)
+	q.changes.broadcast()
+
+	return true
+}
+
+// pushAll adds the processes the same way push does, all at once, so no other
+// process is added between them. It returns false if the queue is closed.
+func (q *processQueue) pushAll(list []Process) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return false
+	}
+	for _, p := range list {
+		q.insert(p)
+	}
+	q.changes.broadcast()
+
+	return true
+}
+
+// insert adds the process behind the processes with the same or a higher
+// priority. The caller must hold the mutex.
+func (q *processQueue) insert(p Process) {
+	priority := processPriority(p)
+	i := len(q.items)
+	for i > 0 && processPriority(q.items[i-1]) < priority {
+		i--
+	}
+	q.items = append(q.items, nil)
+	copy(q.items[i+1:], q.items[i:])
+	q.items[i] = p
+}
+
+// pop removes and returns the process at the front of the queue. It returns
+// false if the queue is empty, frozen, halted, paused, or closed.
+func (q *processQueue) pop() (Process, bool) {
+	return q.popFirst(func(Process) bool { return true })
+}
+
+// popFirst removes and returns the first process of the queue that admit
+// accepts. It returns false if no process is accepted, or if the queue is
+// frozen, halted, paused, or closed.
+func (q *processQueue) popFirst(admit func(Process) bool) (Process, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed || q.frozen || q.halted || q.paused {
+		return nil, false
+	}
+	for i, p := range q.items {
+		if !admit(p) {
+			continue
+		}
+		if i == 0 {
+			q.items[0] = nil
+			q.items = q.items[1:]
+		} else {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+		}
+		return p, true
+	}
+
+	return nil, false
+}
+
+// extract removes and returns the processes of the queue that match.
+func (q *processQueue) extract(match func(Process) bool) []Process {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	extracted := make([]Process, 0)
+	kept := make([]Process, 0, len(q.items))
+	for _, p := range q.items {
+		if match(p) {
+			extracted = append(extracted, p)
+		} else {
+			kept = append(kept, p)
+		}
+	}
+	q.items = kept
+
+	return extracted
+}
+
+// signal wakes up the workers that wait for a change of the queue, without
+// changing it.
+func (q *processQueue) signal() {
+	q.changes.broadcast()
+}
+
+// pushFront adds the process to the front of the queue. It returns false if
+// the queue is closed.
+func (q *processQueue) pushFront(p Process) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return false
+	}
+	q.items = append([]Process{p}, q.items...)
+	q.changes.broadcast()
+
+	return true
+}
+
+// remove removes the process with the given pid from the queue. It returns
+// false if the process is not in the queue.
+func (q *processQueue) remove(pid PID) (Process, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, p := range q.items {
+		if p.PID() == pid {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return p, true
+		}
+	}
+
+	return nil, false
+}
+
+// removeLowest removes and returns the last process with the lowest priority
+// of the queue, if its priority is lower than priority.
+func (q *processQueue) removeLowest(priority int) (Process, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	lowest := -1
+	for i, p := range q.items {
+		if lowest < 0 || processPriority(p) <= processPriority(q.items[lowest]) {
+			lowest = i
+		}
+	}
+	if lowest < 0 || processPriority(q.items[lowest]) >= priority {
+		return nil, false
+	}
+
+	p := q.items[lowest]
+	q.items = append(q.items[:lowest], q.items[lowest+1:]...)
+
+	return p, true
+}
+
+// wait returns a channel that is closed on the next change of the queue.
+func (q *processQueue) wait() <-chan struct{} {
+	return q.changes.wait()
+}
+
+// isEmpty reports whether the queue has no process.
+func (q *processQueue) isEmpty() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return len(q.items) == 0
+}
+
+// isClosed reports whether the queue is closed.
+func (q *processQueue) isClosed() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.closed
+}
+
+// freeze stops pop from returning processes until thaw is called. It returns
+// false if the queue is already frozen.
+func (q *processQueue) freeze() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.frozen {
+		return false
+	}
+	q.frozen = true
+
+	return true
+}
+
+// thaw lets pop return processes again. It returns false if the queue is not
+// frozen.
+func (q *processQueue) thaw() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !q.frozen {
+		return false
+	}
+	q.frozen = false
+	q.changes.broadcast()
+
+	return true
+}
+
+// halt stops pop from returning processes for good. Unlike close, the
+// processes can still be added to the queue.
+func (q *processQueue) halt() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.halted = true
+	q.changes.broadcast()
+}
+
+// setPaused stops pop from returning processes, or lets it return them
+// again.
+func (q *processQueue) setPaused(paused bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.paused = paused
+	q.changes.broadcast()
+}
+
+// drain removes and returns all the processes of the queue.
+func (q *processQueue) drain() []Process {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	items := q.items
+	q.items = make([]Process, 0)
+
+	return items
+}
+
+// reopen makes the closed queue ready to be consumed again.
+func (q *processQueue) reopen() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.closed = false
+	q.frozen = false
+	q.halted = false
+	q.paused = false
+}
+
+// close closes the queue. The processes that are still in the queue are not
+// going to be consumed.
+func (q *processQueue) close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.closed = true
+	q.changes.broadcast()
+}
+
+// sort sorts the processes of the queue that match by less and returns the
+// number of processes whose position changed. The processes that do not
+// match keep their position.
+func (q *processQueue) sort(match func(Process) bool, less func(a, b Process) bool) int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	indexes := make([]int, 0, len(q.items))
+	subset := make([]Process, 0, len(q.items))
+	for i, p := range q.items {
+		if match(p) {
+			indexes = append(indexes, i)
+			subset = append(subset, p)
+		}
+	}
+
+	sort.SliceStable(subset, func(i, j int) bool {
+		return less(subset[i], subset[j])
+	})
+
+	moved := 0
+	for k, i := range indexes {
+		if q.items[i].PID() != subset[k].PID() {
+			moved++
+		}
+		q.items[i] = subset[k]
+	}
+
+	return moved
+}
+
+// Reorder sorts the waiting processes with less, which reports whether a
+// must run before b. The sort is stable and only applies to the processes
+// that are already in the queue, the processes registered afterwards are
+// added to the back as usual. It returns the number of processes whose
+// position changed, or an error if the pool is closed.
+func (w *workerPool) Reorder(less func(a, b Process) bool) (moved int, err error) {
+	return w.reorder(func(Process) bool { return true }, less)
+}
+
+// reorder sorts the waiting processes that match with less.
+func (w *workerPool) reorder(match func(Process) bool, less func(a, b Process) bool) (int, error) {
+	if status := w.PoolStatus(); status == pool.Closed {
+		return 0, w.poolError("reorder the queue", ErrPoolClosed, pool.Running)
+	}
+
+	return w.queue.sort(match, less), nil
+}
@@ -0,0 +1,68 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Reorder should change the execution order of the waiting processes
+func TestWorkerPool_Reorder(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+
+	var mutex sync.Mutex
+	order := make([]PID, 0)
+	record := func(ctx context.Context, pid PID, duration time.Duration) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		order = append(order, pid)
+		return nil
+	}
+	wp.Register(createProcess(5, 1, 0, record)...)
+
+	moved, err := wp.Reorder(func(a, b Process) bool {
+		return a.PID() > b.PID()
+	})
+	a.NoError(err)
+	a.Equal(4, moved)
+
+	err = wp.Start(context.Background())
+	a.NoError(err)
+	wp.Wait()
+	a.Equal([]PID{"p-15", "p-14", "p-13", "p-12", "p-11"}, order)
+
+	err = wp.Close()
+	a.NoError(err)
+	_, err = wp.Reorder(func(a, b Process) bool { return false })
+	a.Error(err)
+}
+
+// Closed queue should neither accept nor return processes
+func TestProcessQueue_Close(t *testing.T) {
+	a := assert.New(t)
+	q := newProcessQueue()
+	for i := 0; i < 3; i++ {
+		a.True(q.push(newTestProcess("queue", i, 0, processFunc)))
+	}
+
+	p, ok := q.pop()
+	a.True(ok)
+	a.Equal(PID("p-"+strconv.Itoa(0)), p.PID())
+
+	changed := q.wait()
+	q.close()
+	<-changed
+	a.True(q.isClosed())
+	a.False(q.push(newTestProcess("queue", 4, 0, processFunc)))
+	_, ok = q.pop()
+	a.False(ok)
+}
@@ -0,0 +1,89 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// startRateWindow is the time constant of the process start rate moving
+	// average.
+	startRateWindow = time.Minute
+)
+
+// rateMeter is an exponentially weighted moving average of events per second.
+// Each event weighs exp(-age/window), and the sum of the weights is divided by
+// the integral of the same decay since the meter started, so the rate is not
+// biased towards zero while the meter is younger than the window.
+type rateMeter struct {
+	mutex   sync.Mutex
+	window  time.Duration
+	started time.Time
+	last    time.Time
+	sum     float64
+}
+
+// newRateMeter makes a rate meter with the given time constant.
+func newRateMeter(window time.Duration) *rateMeter {
+	now := time.Now()
+	return &rateMeter{
+		window:  window,
+		started: now,
+		last:    now,
+	}
+}
+
+// reset drops all the events and starts measuring from now.
+func (m *rateMeter) reset(now time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.started = now
+	m.last = now
+	m.sum = 0
+}
+
+// decay ages the sum of weights to now. The caller must hold the mutex.
+func (m *rateMeter) decay(now time.Time) {
+	if elapsed := now.Sub(m.last); elapsed > 0 {
+		m.sum *= math.Exp(-elapsed.Seconds() / m.window.Seconds())
+		m.last = now
+	}
+}
+
+// mark records one event at now.
+func (m *rateMeter) mark(now time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.decay(now)
+	m.sum++
+}
+
+// rate returns the events per second at now.
+func (m *rateMeter) rate(now time.Time) float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.decay(now)
+	tau := m.window.Seconds()
+	norm := tau * (1 - math.Exp(-now.Sub(m.started).Seconds()/tau))
+	if norm <= 0 {
+		return 0
+	}
+
+	return m.sum / norm
+}
+
+// StartRate returns the number of processes started per second, as an
+// exponentially weighted moving average over the last minute. Unlike the
+// completion counters it drops as soon as the workers stop picking up
+// processes, so a deep queue with a low start rate points to a stall.
+func (w *workerPool) StartRate() float64 {
+	return w.starts.rate(time.Now())
+}
@@ -0,0 +1,52 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// StartRate should follow the rate at which processes are submitted
+func TestWorkerPool_StartRate(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(5))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	a.Zero(wp.Monitor().StartRate())
+
+	// Submit 20 processes per second for 5 seconds.
+	const rate = 20
+	ticker := time.NewTicker(time.Second / rate)
+	defer ticker.Stop()
+	for i, p := range createProcess(5*rate, 1, time.Millisecond, processFuncWithoutLog) {
+		if i > 0 {
+			<-ticker.C
+		}
+		a.NoError(wp.Register(p))
+	}
+
+	a.InEpsilon(float64(rate), wp.Monitor().StartRate(), 0.2)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// The meter should not be biased towards zero while it is young
+func TestRateMeter(t *testing.T) {
+	a := assert.New(t)
+	start := time.Now()
+	m := newRateMeter(time.Minute)
+	m.reset(start)
+	for i := 1; i <= 100; i++ {
+		m.mark(start.Add(time.Duration(i) * 100 * time.Millisecond))
+	}
+
+	a.InEpsilon(10.0, m.rate(start.Add(10*time.Second)), 0.05)
+	a.Less(m.rate(start.Add(70*time.Second)), 1.0)
+}
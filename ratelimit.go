@@ -0,0 +1,128 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket that limits the number of processes that are
+// dispatched per second. The effective rate is the base rate multiplied by
+// the throttle factor.
+type rateLimiter struct {
+	mutex  sync.Mutex
+	rate   float64
+	factor float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter makes a full token bucket that refills rate tokens per
+// second and holds at most burst tokens.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimiter{
+		rate:   rate,
+		factor: 1,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// refill adds the tokens that have been earned since the last refill. The
+// caller must hold the mutex.
+func (r *rateLimiter) refill(now time.Time) {
+	r.tokens += now.Sub(r.last).Seconds() * r.rate * r.factor
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.last = now
+}
+
+// reserve takes a token if there is one available. Otherwise, it returns the
+// duration after which the next token is available.
+func (r *rateLimiter) reserve() (time.Duration, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.refill(time.Now())
+	if r.tokens >= 1 {
+		r.tokens--
+		return 0, true
+	}
+
+	return time.Duration((1 - r.tokens) / (r.rate * r.factor) * float64(time.Second)), false
+}
+
+// wait blocks until a token is taken. It returns false if the quit or the
+// done channel is closed before that.
+func (r *rateLimiter) wait(quit, done <-chan struct{}) bool {
+	for {
+		delay, ok := r.reserve()
+		if ok {
+			return true
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-quit:
+			timer.Stop()
+			return false
+		case <-done:
+			timer.Stop()
+			return false
+		}
+	}
+}
+
+// put gives back a token that has been taken but not used, up to the burst.
+func (r *rateLimiter) put() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.refill(time.Now())
+	if r.tokens++; r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+}
+
+// available returns the number of tokens that are available now.
+func (r *rateLimiter) available() float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.refill(time.Now())
+
+	return r.tokens
+}
+
+// setTokens sets the number of available tokens, up to the burst.
+func (r *rateLimiter) setTokens(tokens float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if tokens > r.burst {
+		tokens = r.burst
+	}
+	r.tokens = tokens
+	r.last = time.Now()
+}
+
+// setFactor changes the throttle factor. The tokens that have been earned
+// with the previous factor are kept.
+func (r *rateLimiter) setFactor(factor float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.refill(time.Now())
+	r.factor = factor
+}
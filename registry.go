@@ -0,0 +1,61 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownProcessType is returned by CreateProcess when no factory is
+// registered with the process type name.
+var ErrUnknownProcessType = errors.New("unknown process type")
+
+type (
+	// ProcessFactory makes a process with the given id from its
+	// configuration.
+	ProcessFactory func(pid PID, config map[string]interface{}) (Process, error)
+
+	// processTypeRegistry holds the process factories by type name.
+	processTypeRegistry struct {
+		mutex     sync.RWMutex
+		factories map[string]ProcessFactory
+	}
+)
+
+var processTypes = &processTypeRegistry{factories: make(map[string]ProcessFactory)}
+
+// RegisterProcessType registers the factory of the process type name, so the
+// processes that are defined by configuration, such as YAML job definitions,
+// can be made with CreateProcess. Registering a name again replaces its
+// factory, which lets a hot-reload swap the implementation, and registering
+// a nil factory removes the type. It is safe to call from multiple
+// goroutines.
+func RegisterProcessType(name string, factory ProcessFactory) {
+	processTypes.mutex.Lock()
+	defer processTypes.mutex.Unlock()
+
+	if factory == nil {
+		delete(processTypes.factories, name)
+		return
+	}
+	processTypes.factories[name] = factory
+}
+
+// CreateProcess makes a process of the type name with the factory that is
+// registered by RegisterProcessType. It returns ErrUnknownProcessType if the
+// type is not registered, and the error of the factory otherwise.
+func CreateProcess(name string, pid PID, config map[string]interface{}) (Process, error) {
+	processTypes.mutex.RLock()
+	factory, ok := processTypes.factories[name]
+	processTypes.mutex.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProcessType, name)
+	}
+
+	return factory(pid, config)
+}
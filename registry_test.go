@@ -0,0 +1,53 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// A process made from its registered type should run in the pool
+func TestCreateProcess(t *testing.T) {
+	a := assert.New(t)
+	RegisterProcessType("sleep", func(pid PID, config map[string]interface{}) (Process, error) {
+		d, ok := config["duration"].(string)
+		if !ok {
+			return nil, errors.New("duration is missing")
+		}
+		duration, err := time.ParseDuration(d)
+		if err != nil {
+			return nil, err
+		}
+
+		return mockProcess{name: "sleep", pid: pid, sleepTime: duration, pFunc: processFuncWithoutLog}, nil
+	})
+	defer RegisterProcessType("sleep", nil)
+
+	_, err := CreateProcess("unknown", "p-1", nil)
+	a.ErrorIs(err, ErrUnknownProcessType)
+	_, err = CreateProcess("sleep", "p-1", map[string]interface{}{})
+	a.EqualError(err, "duration is missing")
+
+	p, err := CreateProcess("sleep", "p-1", map[string]interface{}{"duration": "10ms"})
+	a.NoError(err)
+	a.Equal(PID("p-1"), p.PID())
+
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(p))
+	a.NoError(wp.Wait())
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-1").Status)
+	a.NoError(wp.Close())
+
+	RegisterProcessType("sleep", nil)
+	_, err = CreateProcess("sleep", "p-2", map[string]interface{}{"duration": "10ms"})
+	a.ErrorIs(err, ErrUnknownProcessType)
+}
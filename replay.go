@@ -0,0 +1,24 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+// replayProcess wraps a process to be able to run it again later.
+type replayProcess struct {
+	Process
+}
+
+// unwrap returns the wrapped process.
+func (r replayProcess) unwrap() Process {
+	return r.Process
+}
+
+// CaptureReplay wraps the process and returns a factory that makes a replay
+// of it. The replay runs the same process value with the same input again,
+// so a failed process can be resubmitted later to reproduce the failure.
+func CaptureReplay(p Process) (Process, func() Process) {
+	return replayProcess{Process: p}, func() Process {
+		return replayProcess{Process: p}
+	}
+}
@@ -0,0 +1,39 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// Replay of a failed process should fail with the same error
+func TestCaptureReplay(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	p, replay := CaptureReplay(newTestProcess("p-1", 1, 0, processFuncWithError))
+	wp.Register(p)
+	time.Sleep(100 * time.Millisecond)
+	a.Equal(process.Failed, processStats(t, wp.Monitor(), "p-1").Status)
+	firstErr := processError(t, wp.Monitor(), "p-1")
+
+	r := replay()
+	a.Equal(p.PID(), r.PID())
+	wp.Register(r)
+	time.Sleep(100 * time.Millisecond)
+	a.Equal(process.Failed, processStats(t, wp.Monitor(), "p-1").Status)
+	a.Equal(firstErr, processError(t, wp.Monitor(), "p-1"))
+
+	err = wp.Close()
+	a.NoError(err)
+}
@@ -0,0 +1,95 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+)
+
+// archivePrefix starts the ids of the processes that Reset archives with
+// WithPIDIsolation. The pool rejects the processes whose id starts with it,
+// including the processes of a namespace whose name starts with it, so an
+// archived id cannot be reused.
+const archivePrefix = "#"
+
+// ErrReservedPID is returned by Register with WithPIDIsolation when a process
+// id starts with the prefix of the archived processes.
+var ErrReservedPID = errors.New("process id is reserved")
+
+// ArchivedPID returns the id that a process of the cycle has after the pool
+// has been reset with WithPIDIsolation, such as "#1/p-1".
+func ArchivedPID(cycle int, pid PID) PID {
+	return PID(archivePrefix+strconv.Itoa(cycle)+namespaceSeparator) + pid
+}
+
+// Reset makes a closed pool ready to start again, as a new lifecycle. The
+// pool status becomes Created, and the stats of the processes of the previous
+// lifecycles are kept. Without WithPIDIsolation, a process of the new
+// lifecycle that reuses an id overwrites the stats of the old one. The
+// workers of the new lifecycle have the same names as the workers of the
+// first one. It returns an error if the pool is not closed.
+func (w *workerPool) Reset() error {
+	w.statusMutex.Lock()
+	defer w.statusMutex.Unlock()
+
+	if w.status != pool.Closed {
+		return &PoolError{Op: "reset the pool", Err: ErrInvalidPoolStatus, CurrentStatus: w.status,
+			ExpectedStatus: pool.Closed}
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	cycle := atomic.LoadInt64(&w.cycle)
+	if w.config.PIDIsolation {
+		w.archive(int(cycle))
+	}
+
+	// The workers of the new lifecycle get the same names as the workers
+	// of the first one, so the processes that are pinned by WithWorker
+	// keep their worker.
+	w.workersMutex.Lock()
+	w.workers = nil
+	w.controls = make(map[WorkerName]*workerControl)
+	w.sleeping = make(map[WorkerName]chan struct{})
+	w.pinned = make(map[WorkerName]chan struct{})
+	w.nextWorker = 0
+	w.workersMutex.Unlock()
+
+	w.doneMutex.Lock()
+	w.done = make(chan struct{})
+	w.doneMutex.Unlock()
+	w.audit = nil
+	w.queue.reopen()
+	w.completions.reopen()
+	atomic.StoreInt64(&w.cycle, cycle+1)
+	w.transition(pool.Created)
+
+	return nil
+}
+
+// archive moves the processes of the cycle to their archived id.
+func (w *workerPool) archive(cycle int) {
+	pids := make([]PID, 0)
+	w.processes.each(func(pid PID, stats ProcessStats) {
+		if stats.Cycle == cycle {
+			pids = append(pids, pid)
+		}
+	})
+
+	for _, pid := range pids {
+		archived := ArchivedPID(cycle, pid)
+		w.processes.put(archived, w.processes.get(pid))
+		w.processes.delete(pid)
+		if pc := w.controlPanel.get(pid); pc != nil {
+			w.controlPanel.put(archived, pc)
+			w.controlPanel.delete(pid)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// A reset pool should run again and keep the processes of each lifecycle apart
+func TestWorkerPool_ResetPIDIsolation(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2), WithPIDIsolation())
+	a.Error(wp.Reset())
+	a.NoError(wp.Start(context.Background()))
+	a.Error(wp.Reset())
+
+	a.NoError(wp.Register(newTestProcess("first", 1, 0, processFuncWithError)))
+	a.Error(wp.Wait())
+	a.NoError(wp.Close())
+
+	a.NoError(wp.Reset())
+	a.Equal(pool.Created, wp.Monitor().PoolStatus())
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(newTestProcess("second", 1, 0, processFuncWithoutLog)))
+	a.NoError(wp.Wait())
+	a.NoError(wp.Close())
+
+	first := processStats(t, wp.Monitor(), ArchivedPID(1, "p-1"))
+	a.Equal(1, first.Cycle)
+	a.Equal("first", first.Process.Name())
+	a.Equal(process.Failed, first.Status)
+	a.Error(processError(t, wp.Monitor(), "#1/p-1"))
+
+	second := processStats(t, wp.Monitor(), "p-1")
+	a.Equal(2, second.Cycle)
+	a.Equal("second", second.Process.Name())
+	a.Equal(process.Succeeded, second.Status)
+	a.Len(wp.Monitor().CompletedProcesses(), 2)
+}
+
+// An archived id should not collide with the ids of a namespace
+func TestWorkerPool_ResetPIDIsolationNamespace(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithPIDIsolation())
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(newTestProcess("first", 1, 0, processFuncWithoutLog)))
+	a.NoError(wp.Wait())
+	a.NoError(wp.Close())
+	a.NoError(wp.Reset())
+
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Namespace("1").Register(newTestProcess("namespaced", 1, 0, processFuncWithoutLog)))
+	a.ErrorIs(wp.Namespace("#1").Register(newTestProcess("reserved", 1, 0, processFuncWithoutLog)), ErrReservedPID)
+	a.ErrorIs(wp.Register(mockProcess{name: "reserved", pid: "#1/p-1", pFunc: processFuncWithoutLog}), ErrReservedPID)
+	a.NoError(wp.Wait())
+	a.NoError(wp.Close())
+
+	a.Equal("first", processStats(t, wp.Monitor(), ArchivedPID(1, "p-1")).Process.Name())
+	a.Equal("1/namespaced", processStats(t, wp.Monitor(), "1/p-1").Process.Name())
+}
+
+// Without PID isolation, the next lifecycle should overwrite a reused id
+func TestWorkerPool_Reset(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(newTestProcess("first", 1, 0, processFuncWithoutLog)))
+	a.NoError(wp.Wait())
+	a.NoError(wp.Close())
+
+	a.NoError(wp.Reset())
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(newTestProcess("second", 1, 0, processFuncWithoutLog)))
+	a.NoError(wp.Wait())
+	a.Equal("second", processStats(t, wp.Monitor(), "p-1").Process.Name())
+	_, ok := wp.Monitor().ProcessStats(ArchivedPID(1, "p-1"))
+	a.False(ok)
+	a.NoError(wp.Close())
+}
+
+// Reset should not race with the callers that wait for the pool to close
+func TestWorkerPool_ResetConcurrent(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for ctx.Err() == nil {
+			_ = wp.AwaitIdle(ctx)
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		a.NoError(wp.Start(context.Background()))
+		a.NoError(wp.Close())
+		a.NoError(wp.Reset())
+	}
+	cancel()
+	<-stopped
+}
@@ -0,0 +1,141 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// ErrNotResultExtractor is returned by SubmitWithResult when the process does
+// not implement the ResultExtractor interface.
+var ErrNotResultExtractor = errors.New("process does not implement ResultExtractor")
+
+// ProcessResult is the outcome of a finished process.
+type ProcessResult struct {
+	// PID is the process id.
+	PID PID
+
+	// Status is the final state of the process.
+	Status process.Status
+
+	// Output is the value returned by ExtractResult if the process
+	// implements ResultExtractor.
+	Output interface{}
+
+	// Err is the process error.
+	Err error
+
+	// StartedAt represents the start date time of the process.
+	StartedAt time.Time
+
+	// FinishedAt represents the end date time of the process.
+	FinishedAt time.Time
+}
+
+// ResultExtractor is a Process that exposes its output after Start returns.
+// It lets callers get a typed result back from the pool without sharing
+// state with the process.
+type ResultExtractor interface {
+	Process
+	// ExtractResult returns the process output.
+	ExtractResult() interface{}
+}
+
+// SubmitWithResult registers the process to the pool and returns a channel
+// that receives the process output once the process succeeded. The
+// channel is closed after the process reaches a final state, so a process
+// that fails or gets killed closes the channel without sending any value.
+// It returns ErrNotResultExtractor if the process does not implement
+// ResultExtractor, or the error of Register if the pool rejects the process.
+func (w *workerPool) SubmitWithResult(p Process) (<-chan interface{}, error) {
+	if !findLayer(p, isResultExtractor) {
+		return nil, ErrNotResultExtractor
+	}
+
+	if err := w.Register(p); err != nil {
+		return nil, err
+	}
+
+	done := w.controlPanel.get(p.PID()).done
+	result := make(chan interface{}, 1)
+
+	go func() {
+		defer close(result)
+		<-done
+		if stats := w.processes.get(p.PID()); stats.Status == process.Succeeded {
+			result <- stats.Output
+		}
+	}()
+
+	return result, nil
+}
+
+// NotifyOn sends the result of the process with the given pid to ch once the
+// process reaches a final state, and then closes ch. If the process has
+// already finished, the result is sent right away. The send blocks until ch
+// is received from, so ch should be buffered if the caller may not be
+// listening. It returns ErrProcessNotFound if the process is not registered
+// to the pool.
+func (w *workerPool) NotifyOn(pid PID, ch chan<- ProcessResult) error {
+	var done <-chan struct{}
+	if pc := w.controlPanel.get(pid); pc != nil {
+		done = pc.done
+	} else if stats := w.processes.get(pid); stats.Process != nil && stats.Status.IsTerminal() {
+		// The process has been purged from the control panel, but its stats
+		// are still there.
+		closed := make(chan struct{})
+		close(closed)
+		done = closed
+	} else {
+		return w.pidError("notify the result of the process", ErrProcessNotFound, pid)
+	}
+
+	go func() {
+		defer close(ch)
+		<-done
+		ch <- w.processes.get(pid).result()
+	}()
+
+	return nil
+}
+
+// isResultExtractor reports whether the process implements ResultExtractor.
+func isResultExtractor(p Process) bool {
+	_, ok := p.(ResultExtractor)
+	return ok
+}
+
+// result makes the process result from its stats.
+func (s ProcessStats) result() ProcessResult {
+	return ProcessResult{
+		PID:        s.Process.PID(),
+		Status:     s.Status,
+		Output:     s.Output,
+		Err:        s.err,
+		StartedAt:  s.StartedAt,
+		FinishedAt: s.FinishedAt,
+	}
+}
+
+// storeResult extracts the process output and passes the result to the
+// result transformer, if there is any, before it is stored in the stats.
+func (w *workerPool) storeResult(stats *ProcessStats) {
+	findLayer(stats.Process, func(l Process) bool {
+		re, ok := l.(ResultExtractor)
+		if ok {
+			stats.Output = re.ExtractResult()
+		}
+		return ok
+	})
+
+	if w.config.ResultTransformer != nil {
+		r := w.config.ResultTransformer(stats.result())
+		stats.Output = r.Output
+		stats.err = r.Err
+	}
+}
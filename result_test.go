@@ -0,0 +1,150 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+type (
+	reportProcess struct {
+		pid    PID
+		report string
+	}
+
+	checksum struct {
+		Sum  int
+		Size int
+	}
+
+	checksumProcess struct {
+		pid    PID
+		input  []int
+		result checksum
+	}
+)
+
+func (c *checksumProcess) Start(ctx context.Context) error {
+	for _, n := range c.input {
+		c.result.Sum += n
+	}
+	c.result.Size = len(c.input)
+	return nil
+}
+
+func (c *checksumProcess) Name() string {
+	return "checksum"
+}
+
+func (c *checksumProcess) PID() PID {
+	return c.pid
+}
+
+func (c *checksumProcess) ExtractResult() interface{} {
+	return c.result
+}
+
+func (r *reportProcess) Start(ctx context.Context) error {
+	r.report = strings.Repeat("x", 1000)
+	return nil
+}
+
+func (r *reportProcess) Name() string {
+	return "report"
+}
+
+func (r *reportProcess) PID() PID {
+	return r.pid
+}
+
+func (r *reportProcess) ExtractResult() interface{} {
+	return r.report
+}
+
+// Submit a process and receive its typed result from the channel
+func TestWorkerPool_SubmitWithResult(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	result, err := wp.SubmitWithResult(&checksumProcess{pid: "p-1", input: []int{1, 2, 3}})
+	a.NoError(err)
+	select {
+	case r := <-result:
+		a.Equal(checksum{Sum: 6, Size: 3}, r)
+	case <-time.After(time.Second):
+		a.Fail("result was not received")
+	}
+
+	_, err = wp.SubmitWithResult(newTestProcess("p-2", 2, 0, processFunc))
+	a.ErrorIs(err, ErrNotResultExtractor)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Result transformer should change the output before it is stored
+func TestWithResultTransformer(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithResultTransformer(func(r ProcessResult) ProcessResult {
+		if s, ok := r.Output.(string); ok && len(s) > 10 {
+			r.Output = s[:10]
+		}
+		return r
+	}))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	result, err := wp.SubmitWithResult(&reportProcess{pid: "p-1"})
+	a.NoError(err)
+	a.Equal(strings.Repeat("x", 10), <-result)
+	a.Equal(strings.Repeat("x", 10), processStats(t, wp.Monitor(), "p-1").Output)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// NotifyOn should send the result once the process finished
+func TestWorkerPool_NotifyOn(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	a.ErrorIs(wp.NotifyOn("p-0", make(chan ProcessResult, 1)), ErrProcessNotFound)
+
+	wp.Register(newTestProcess("notify", 1, 200*time.Millisecond, processFuncWithoutLog))
+	ch := make(chan ProcessResult, 1)
+	a.NoError(wp.NotifyOn("p-1", ch))
+	time.Sleep(100 * time.Millisecond)
+	a.Empty(ch)
+
+	select {
+	case r := <-ch:
+		a.Equal(process.Succeeded, r.Status)
+		a.Equal(PID("p-1"), r.PID)
+		a.WithinDuration(time.Now(), r.FinishedAt, 50*time.Millisecond)
+	case <-time.After(time.Second):
+		a.Fail("process result is not received")
+	}
+	_, ok := <-ch
+	a.False(ok)
+
+	// A finished process is notified right away.
+	ch = make(chan ProcessResult)
+	a.NoError(wp.NotifyOn("p-1", ch))
+	a.Equal(process.Succeeded, (<-ch).Status)
+
+	err = wp.Close()
+	a.NoError(err)
+}
@@ -0,0 +1,158 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+type (
+	// BackoffStrategy returns the delay before a failed process runs again.
+	BackoffStrategy interface {
+		// Next returns the delay before the given attempt, starting at 2 for
+		// the first retry.
+		Next(attempt int) time.Duration
+	}
+
+	// FixedBackoff waits the same delay before each retry.
+	FixedBackoff struct {
+		// Delay is the delay before each retry.
+		Delay time.Duration
+	}
+
+	// ExponentialBackoff doubles the delay after each retry.
+	ExponentialBackoff struct {
+		// Initial is the delay before the first retry.
+		Initial time.Duration
+
+		// Max is the maximum delay. Zero means no maximum.
+		Max time.Duration
+	}
+
+	// retryProcess wraps a process to run it again when it fails.
+	retryProcess struct {
+		Process
+		maxAttempts int
+		backoff     BackoffStrategy
+	}
+)
+
+// Next returns the fixed delay.
+func (b FixedBackoff) Next(int) time.Duration {
+	return b.Delay
+}
+
+// Next returns the initial delay doubled for each previous retry, capped at
+// the maximum delay.
+func (b ExponentialBackoff) Next(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 2; i < attempt; i++ {
+		delay *= 2
+		if b.Max > 0 && delay >= b.Max {
+			return b.Max
+		}
+	}
+
+	if b.Max > 0 && delay > b.Max {
+		return b.Max
+	}
+
+	return delay
+}
+
+// WithRetry wraps the process to run it again after a backoff delay when it
+// fails, up to maxAttempts runs in total. While it waits for the next
+// attempt, the process status is Retrying and it still counts as waiting, so
+// Wait returns once the retries are settled. A process that exhausts its
+// attempts ends as Failed with the error of the last attempt. A process that
+// is killed during the backoff ends as Killed, and one whose pool is closed
+// during the backoff ends as Failed right away with the error of its last
+// attempt. A process whose input is invalid is not retried.
+func WithRetry(p Process, maxAttempts int, backoff BackoffStrategy) Process {
+	return retryProcess{Process: p, maxAttempts: maxAttempts, backoff: backoff}
+}
+
+// unwrap returns the wrapped process.
+func (r retryProcess) unwrap() Process {
+	return r.Process
+}
+
+// retryPolicy returns the retry settings of the process that are set by
+// WithRetry.
+func retryPolicy(p Process) (retryProcess, bool) {
+	var policy retryProcess
+	found := findLayer(p, func(l Process) bool {
+		rp, ok := l.(retryProcess)
+		if ok {
+			policy = rp
+		}
+		return ok
+	})
+
+	return policy, found
+}
+
+// retryable reports whether the failed process has attempts left.
+func retryable(p Process, stats ProcessStats) bool {
+	policy, ok := retryPolicy(p)
+	return ok && stats.Attempt < policy.maxAttempts
+}
+
+// retry schedules the next attempt of the process after its backoff delay,
+// if the process is Retrying. It returns false if the process is not retried.
+func (w *workerPool) retry(p Process, stats ProcessStats) bool {
+	if stats.Status != process.Retrying {
+		return false
+	}
+
+	policy, _ := retryPolicy(p)
+	ctx := w.controlPanel.get(p.PID()).ctx
+	done := w.closeSignal()
+	// The attempt has finished, but the process has not.
+	stats.FinishedAt = time.Time{}
+	w.processes.put(p.PID(), stats)
+	atomic.AddInt64(&w.counters.waiting, 1)
+	atomic.AddInt64(&w.counters.weight, processWeight(p))
+	w.log(levelInfo, "process has failed and will be retried", Field{"name", w.processName(p)},
+		Field{"pid", p.PID()}, Field{"attempt", stats.Attempt}, Field{"error", stats.err})
+
+	// The backoff is part of the workers lifetime, so Close waits for it.
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		timer := time.NewTimer(policy.backoff.Next(stats.Attempt + 1))
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			stats.Status = process.Waiting
+			stats.StartedAt = time.Time{}
+			stats.EnqueuedAt = time.Now()
+			stats.updatedAt = stats.EnqueuedAt
+			w.processes.put(p.PID(), stats)
+			if w.publish(p) {
+				w.notify()
+				return
+			}
+		case <-ctx.Done():
+			stats.Status = process.Killed
+			w.abandon(p, stats)
+			return
+		case <-done:
+		}
+
+		// The pool has been closed before the next attempt, so the process
+		// ends with the error of its last attempt.
+		stats.Status = process.Failed
+		w.controlPanel.get(p.PID()).cancel()
+		w.abandon(p, stats)
+	}()
+
+	return true
+}
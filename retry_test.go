@@ -0,0 +1,130 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+var errFlaky = errors.New("flaky failure")
+
+// flakyFunc returns a process function that fails the first n runs.
+func flakyFunc(n int32) (pTestFunc, *int32) {
+	runs := new(int32)
+	return func(ctx context.Context, pid PID, d time.Duration) error {
+		if atomic.AddInt32(runs, 1) <= n {
+			return errFlaky
+		}
+		return nil
+	}, runs
+}
+
+// A failed process should run again until it succeeds
+func TestWithRetry(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	succeeding, succeedingRuns := flakyFunc(2)
+	failing, failingRuns := flakyFunc(10)
+	wp.Register(
+		WithRetry(newTestProcess("retry", 1, 0, succeeding), 3, FixedBackoff{Delay: 20 * time.Millisecond}),
+		WithRetry(newTestProcess("retry", 2, 0, failing), 3, FixedBackoff{Delay: 20 * time.Millisecond}),
+	)
+	time.Sleep(10 * time.Millisecond)
+	a.Equal(process.Retrying, processStats(t, wp.Monitor(), "p-1").Status)
+
+	err = wp.Wait()
+	var multi *MultiError
+	a.ErrorAs(err, &multi)
+	a.Len(multi.Errors, 1)
+	a.ErrorIs(err, errFlaky)
+
+	stats := processStats(t, wp.Monitor(), "p-1")
+	a.Equal(process.Succeeded, stats.Status)
+	a.Equal(3, stats.Attempt)
+	a.Equal(int32(3), atomic.LoadInt32(succeedingRuns))
+	stats = processStats(t, wp.Monitor(), "p-2")
+	a.Equal(process.Failed, stats.Status)
+	a.Equal(3, stats.Attempt)
+	a.Equal(int32(3), atomic.LoadInt32(failingRuns))
+	a.Equal(int64(1), wp.Stats().TotalSucceeded)
+	a.Equal(int64(1), wp.Stats().TotalFailed)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Kill and Close should end the backoff wait right away
+func TestWithRetry_BackoffCancellation(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	failing, _ := flakyFunc(10)
+	wp.Register(
+		WithRetry(newTestProcess("retry", 1, 0, failing), 3, FixedBackoff{Delay: time.Minute}),
+		WithRetry(newTestProcess("retry", 2, 0, failing), 3, FixedBackoff{Delay: time.Minute}),
+	)
+	_, err = wp.WaitUntilStatus(context.Background(), "p-1", process.Retrying)
+	a.NoError(err)
+	_, err = wp.WaitUntilStatus(context.Background(), "p-2", process.Retrying)
+	a.NoError(err)
+
+	a.NoError(wp.KillWait("p-1", time.Second))
+	a.Equal(process.Killed, processStats(t, wp.Monitor(), "p-1").Status)
+
+	start := time.Now()
+	err = wp.Close()
+	a.NoError(err)
+	a.Less(time.Since(start), time.Second)
+	stats := processStats(t, wp.Monitor(), "p-2")
+	a.Equal(process.Failed, stats.Status)
+	a.Equal(1, stats.Attempt)
+	a.Error(processError(t, wp.Monitor(), "p-2"))
+}
+
+// A process in backoff should fail as soon as the pool is closed
+func TestWithRetry_BackoffClose(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+
+	failing, _ := flakyFunc(10)
+	a.NoError(wp.Register(WithRetry(newTestProcess("retry", 1, 0, failing), 3, FixedBackoff{Delay: 10 * time.Second})))
+	_, err := wp.WaitUntilStatus(context.Background(), "p-1", process.Retrying)
+	a.NoError(err)
+
+	time.Sleep(100 * time.Millisecond)
+	start := time.Now()
+	a.NoError(wp.Close())
+	status, err := wp.WaitUntilStatus(context.Background(), "p-1", process.Failed)
+	a.NoError(err)
+	a.Equal(process.Failed, status)
+	a.Less(time.Since(start), 200*time.Millisecond)
+	a.Error(processError(t, wp.Monitor(), "p-1"))
+}
+
+// ExponentialBackoff should double the delay up to the maximum
+func TestExponentialBackoff(t *testing.T) {
+	a := assert.New(t)
+	b := ExponentialBackoff{Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond}
+	a.Equal(10*time.Millisecond, b.Next(2))
+	a.Equal(20*time.Millisecond, b.Next(3))
+	a.Equal(40*time.Millisecond, b.Next(4))
+	a.Equal(50*time.Millisecond, b.Next(5))
+	a.Equal(50*time.Millisecond, b.Next(100))
+	a.Equal(time.Second, FixedBackoff{Delay: time.Second}.Next(7))
+}
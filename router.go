@@ -0,0 +1,1027 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/circuit"
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/hamed-yousefi/gowl/status/worker"
+)
+
+const (
+	// routerWorkerSeparator separates the pool index from the worker name in
+	// the worker names of a router.
+	routerWorkerSeparator = "/"
+)
+
+// ErrProcessRouted is returned by a router when a process id is placed on
+// another pool than the one the operation needs.
+var ErrProcessRouted = errors.New("process is routed to another pool")
+
+type (
+	// hashRing is a consistent hash ring of pool indexes.
+	hashRing struct {
+		hashes []uint32
+		owners map[uint32]int
+	}
+
+	// routing decides which pool of a router holds a process.
+	routing interface {
+		// assign returns the index of the pool of a new process.
+		assign(p Process) (int, error)
+
+		// bind records that the pool at index i holds the process id.
+		bind(pid PID, i int) error
+
+		// owner returns the index of the pool that holds the process id.
+		owner(pid PID) (int, bool)
+
+		// fork returns a routing with the same rules and no process, for
+		// the namespaces of the pools.
+		fork() routing
+	}
+
+	// routerPool is a Pool that routes the processes to a list of pools.
+	routerPool struct {
+		pools   []Pool
+		routing routing
+	}
+
+	// routerMonitor is a Monitor over the monitors of the routed pools.
+	routerMonitor struct {
+		monitors []Monitor
+	}
+)
+
+// hashKey returns the ring hash of the key.
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key)) //nolint:errcheck
+
+	return h.Sum32()
+}
+
+// newHashRing places replicas points of each of the n pools on the ring.
+func newHashRing(n, replicas int) *hashRing {
+	r := &hashRing{owners: make(map[uint32]int, n*replicas)}
+	for i := 0; i < n; i++ {
+		for j := 0; j < replicas; j++ {
+			h := hashKey(strconv.Itoa(i) + "-" + strconv.Itoa(j))
+			if _, ok := r.owners[h]; ok {
+				continue
+			}
+			r.owners[h] = i
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool {
+		return r.hashes[i] < r.hashes[j]
+	})
+
+	return r
+}
+
+// locate returns the index of the pool that owns the key.
+func (r *hashRing) locate(key string) int {
+	h := hashKey(key)
+	i := sort.Search(len(r.hashes), func(i int) bool {
+		return r.hashes[i] >= h
+	})
+	if i == len(r.hashes) {
+		i = 0
+	}
+
+	return r.owners[r.hashes[i]]
+}
+
+// assign returns the pool of the process PID on the ring.
+func (r *hashRing) assign(p Process) (int, error) {
+	return r.locate(p.PID().String()), nil
+}
+
+// bind returns an error if the process id is not placed on pool i.
+func (r *hashRing) bind(pid PID, i int) error {
+	if r.locate(pid.String()) != i {
+		return ErrProcessRouted
+	}
+
+	return nil
+}
+
+// owner returns the pool of the process id on the ring.
+func (r *hashRing) owner(pid PID) (int, bool) {
+	return r.locate(pid.String()), true
+}
+
+// fork returns the ring itself, it holds no process.
+func (r *hashRing) fork() routing {
+	return r
+}
+
+// NewConsistentHashRouter returns a Pool that distributes the processes over
+// pools with a consistent hash ring of their PID, so a PID is always routed
+// to the same pool. Each pool is placed replicas times on the ring, more
+// replicas spread the PIDs more evenly. The methods about a process are
+// routed to the pool of its PID, and the other methods apply to all the
+// pools. The worker names of the router are prefixed with the pool index,
+// such as "0/W1". It panics if pools is empty.
+func NewConsistentHashRouter(pools []Pool, replicas int) Pool {
+	if len(pools) == 0 {
+		panic("gowl: consistent hash router needs at least one pool")
+	}
+	if replicas < 1 {
+		replicas = 1
+	}
+
+	return &routerPool{
+		pools:   append([]Pool(nil), pools...),
+		routing: newHashRing(len(pools), replicas),
+	}
+}
+
+// route returns the pool that holds the process id.
+func (r *routerPool) route(pid PID) (Pool, error) {
+	i, ok := r.routing.owner(pid)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProcessNotFound, pid)
+	}
+
+	return r.pools[i], nil
+}
+
+// bind places the process id on pool i.
+func (r *routerPool) bind(pid PID, i int) error {
+	if err := r.routing.bind(pid, i); err != nil {
+		return r.pidError("route the process", err, pid)
+	}
+
+	return nil
+}
+
+// pidError makes a PoolError of the operation about the process, with the
+// status of the router.
+func (r *routerPool) pidError(op string, err error, pid PID) error {
+	status := r.Monitor().PoolStatus()
+	return &PoolError{Op: op, Err: err, CurrentStatus: status, ExpectedStatus: status, PID: pid}
+}
+
+// place assigns the process to a pool and returns the pool and the process
+// to register in it.
+func (r *routerPool) place(p Process) (Pool, Process, error) {
+	i, p, err := r.assign(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := r.bind(p.PID(), i); err != nil {
+		return nil, nil, err
+	}
+
+	return r.pools[i], p, nil
+}
+
+// assign returns the index of the pool of the process. A process that is
+// pinned to a worker of the router by WithWorker goes to the pool of the
+// worker, and it is returned pinned to the worker name within that pool.
+func (r *routerPool) assign(p Process) (int, Process, error) {
+	name, ok := pinnedWorker(p)
+	if !ok {
+		i, err := r.routing.assign(p)
+		return i, p, err
+	}
+
+	i, wn, ok := splitWorkerName(name, len(r.pools))
+	if !ok {
+		return 0, nil, fmt.Errorf("%w: %s", ErrWorkerNotFound, name)
+	}
+
+	return i, WithWorker(p, wn), nil
+}
+
+// each calls fn for each pool and aggregates the errors in a *MultiError.
+func (r *routerPool) each(fn func(p Pool) error) error {
+	var errs []error
+	for _, p := range r.pools {
+		if err := fn(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return &MultiError{Errors: errs}
+	}
+
+	return nil
+}
+
+// Start starts all the pools with ctx, so cancelling ctx closes all of them.
+func (r *routerPool) Start(ctx context.Context) error {
+	return r.each(func(p Pool) error {
+		return p.Start(ctx)
+	})
+}
+
+// Register routes each process to its pool. No process is registered if a
+// process has no pool.
+func (r *routerPool) Register(args ...Process) error {
+	return r.register(Pool.Register, args)
+}
+
+// RegisterBatch routes each process to its pool, and registers the
+// processes of each pool as a batch.
+func (r *routerPool) RegisterBatch(args ...Process) error {
+	return r.register(Pool.RegisterBatch, args)
+}
+
+// BroadcastRegister routes the process to one pool, which registers a copy
+// of it for each of its workers.
+func (r *routerPool) BroadcastRegister(p Process) error {
+	i, err := r.routing.assign(p)
+	if err != nil {
+		return err
+	}
+	if err := r.bind(p.PID(), i); err != nil {
+		return err
+	}
+
+	return r.pools[i].BroadcastRegister(p)
+}
+
+// RegisterSequential routes the sequential group to the pool of its first
+// process, so the whole group is held by one pool.
+func (r *routerPool) RegisterSequential(args ...Process) error {
+	if len(args) == 0 {
+		return nil
+	}
+
+	i, err := r.routing.assign(args[0])
+	if err != nil {
+		return err
+	}
+	for _, p := range args {
+		if err := r.bind(p.PID(), i); err != nil {
+			return err
+		}
+	}
+
+	return r.pools[i].RegisterSequential(args...)
+}
+
+// Version returns the sum of the configuration versions of all the pools,
+// which is incremented by each configuration change of any pool.
+func (r *routerPool) Version() int64 {
+	var version int64
+	for _, p := range r.pools {
+		version += p.Version()
+	}
+
+	return version
+}
+
+// SLOReport combines the reports of all the pools. The availability is the
+// lowest of the pools, the latency percentiles are the highest of the pools,
+// and the rates are computed over all the processes. The report meets the
+// targets if every pool meets its own targets, so Targets is left zero.
+func (r *routerPool) SLOReport(window time.Duration) SLOReport {
+	report := SLOReport{Availability: 1, SuccessRate: 1, MeetsTargets: true}
+	succeeded := 0.0
+	for _, p := range r.pools {
+		pr := p.SLOReport(window)
+		if pr.Window > report.Window {
+			report.Window = pr.Window
+		}
+		if pr.Availability < report.Availability {
+			report.Availability = pr.Availability
+		}
+		report.Processes += pr.Processes
+		succeeded += pr.SuccessRate * float64(pr.Processes)
+		report.LatencyP50 = maxDuration(report.LatencyP50, pr.LatencyP50)
+		report.LatencyP95 = maxDuration(report.LatencyP95, pr.LatencyP95)
+		report.LatencyP99 = maxDuration(report.LatencyP99, pr.LatencyP99)
+		report.Throughput += pr.Throughput
+		report.MeetsTargets = report.MeetsTargets && pr.MeetsTargets
+	}
+	if report.Processes > 0 {
+		report.SuccessRate = succeeded / float64(report.Processes)
+	}
+	report.ErrorRate = 1 - report.SuccessRate
+
+	return report
+}
+
+// Reset resets all the pools.
+func (r *routerPool) Reset() error {
+	return r.each(Pool.Reset)
+}
+
+// Subscribe sends the events of all the pools to ch.
+func (r *routerPool) Subscribe(ch chan<- Event) {
+	for _, p := range r.pools {
+		p.Subscribe(ch)
+	}
+}
+
+// Unsubscribe stops sending the events of all the pools to ch.
+func (r *routerPool) Unsubscribe(ch chan<- Event) {
+	for _, p := range r.pools {
+		p.Unsubscribe(ch)
+	}
+}
+
+// Freeze freezes all the pools.
+func (r *routerPool) Freeze() error {
+	return r.each(Pool.Freeze)
+}
+
+// Thaw thaws all the pools.
+func (r *routerPool) Thaw() error {
+	return r.each(Pool.Thaw)
+}
+
+// Pause pauses all the pools.
+func (r *routerPool) Pause() error {
+	return r.each(Pool.Pause)
+}
+
+// Resume resumes all the pools.
+func (r *routerPool) Resume() error {
+	return r.each(Pool.Resume)
+}
+
+// register groups the processes by pool and registers each group with fn.
+func (r *routerPool) register(fn func(p Pool, args ...Process) error, args []Process) error {
+	groups := make(map[int][]Process)
+	for _, p := range args {
+		i, p, err := r.assign(p)
+		if err != nil {
+			return err
+		}
+		groups[i] = append(groups[i], p)
+	}
+	for i, group := range groups {
+		for _, p := range group {
+			if err := r.bind(p.PID(), i); err != nil {
+				return err
+			}
+		}
+	}
+
+	var errs []error
+	for i, group := range groups {
+		if err := fn(r.pools[i], group...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return &MultiError{Errors: errs}
+	}
+
+	return nil
+}
+
+// Close closes all the pools.
+func (r *routerPool) Close() error {
+	return r.each(Pool.Close)
+}
+
+// CloseContext closes all the pools with the same drain deadline.
+func (r *routerPool) CloseContext(ctx context.Context) error {
+	return r.each(func(p Pool) error {
+		return p.CloseContext(ctx)
+	})
+}
+
+// CloseGraceful closes all the pools gracefully.
+func (r *routerPool) CloseGraceful() error {
+	return r.each(Pool.CloseGraceful)
+}
+
+// Kill cancels the process in its pool.
+func (r *routerPool) Kill(pid PID) error {
+	p, err := r.route(pid)
+	if err != nil {
+		return err
+	}
+
+	return p.Kill(pid)
+}
+
+// KillGroup kills the processes of the group in all the pools. It returns
+// ErrGroupNotFound if no pool has a process of the group.
+func (r *routerPool) KillGroup(name string) error {
+	found := false
+	for _, p := range r.pools {
+		if err := p.KillGroup(name); err == nil {
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("%w: %s", ErrGroupNotFound, name)
+	}
+
+	return nil
+}
+
+// WaitGroup blocks until the processes of the group are finished in all the
+// pools or ctx is done, and returns the errors of all the pools.
+func (r *routerPool) WaitGroup(ctx context.Context, name string) error {
+	return r.each(func(p Pool) error {
+		return p.WaitGroup(ctx, name)
+	})
+}
+
+// KillWithReason kills the process in its pool with a reason.
+func (r *routerPool) KillWithReason(pid PID, reason error) error {
+	p, err := r.route(pid)
+	if err != nil {
+		return err
+	}
+
+	return p.KillWithReason(pid, reason)
+}
+
+// KillWait cancels the process in its pool and waits for it to return.
+func (r *routerPool) KillWait(pid PID, timeout time.Duration) error {
+	p, err := r.route(pid)
+	if err != nil {
+		return err
+	}
+
+	return p.KillWait(pid, timeout)
+}
+
+// Monitor returns a monitor over all the pools.
+func (r *routerPool) Monitor() Monitor {
+	m := &routerMonitor{monitors: make([]Monitor, 0, len(r.pools))}
+	for _, p := range r.pools {
+		m.monitors = append(m.monitors, p.Monitor())
+	}
+
+	return m
+}
+
+// Stats returns the sum of the stats of all the pools.
+func (r *routerPool) Stats() PoolStats {
+	var stats PoolStats
+	for _, p := range r.pools {
+		stats = addPoolStats(stats, p.Stats())
+	}
+
+	return stats
+}
+
+// addPoolStats returns the sum of two pool stats. The uptime is the longest
+// one.
+func addPoolStats(a, b PoolStats) PoolStats {
+	a.TotalRegistered += b.TotalRegistered
+	a.TotalSucceeded += b.TotalSucceeded
+	a.TotalFailed += b.TotalFailed
+	a.TotalKilled += b.TotalKilled
+	a.TotalCancelled += b.TotalCancelled
+	a.TotalDropped += b.TotalDropped
+	a.ActiveWorkers += b.ActiveWorkers
+	a.IdleWorkers += b.IdleWorkers
+	a.QueueDepth += b.QueueDepth
+	if b.Uptime > a.Uptime {
+		a.Uptime = b.Uptime
+	}
+
+	return a
+}
+
+// Resize changes the number of workers of each pool to n.
+func (r *routerPool) Resize(n int) error {
+	return r.each(func(p Pool) error { return p.Resize(n) })
+}
+
+// Scale adds delta workers to each pool.
+func (r *routerPool) Scale(delta int) error {
+	return r.each(func(p Pool) error { return p.Scale(delta) })
+}
+
+// EnsureWorkers makes sure each pool has at least n workers.
+func (r *routerPool) EnsureWorkers(n int) error {
+	return r.each(func(p Pool) error { return p.EnsureWorkers(n) })
+}
+
+// Throttle throttles each pool by factor.
+func (r *routerPool) Throttle(factor float64) error {
+	return r.each(func(p Pool) error { return p.Throttle(factor) })
+}
+
+// Utilization returns the fraction of busy workers of all the pools.
+func (r *routerPool) Utilization() float64 {
+	current, max := r.Capacity()
+	if max == 0 {
+		return 0
+	}
+
+	return float64(max-current) / float64(max)
+}
+
+// Capacity returns the sum of the capacities of all the pools.
+func (r *routerPool) Capacity() (current, max int) {
+	for _, p := range r.pools {
+		c, m := p.Capacity()
+		current += c
+		max += m
+	}
+
+	return current, max
+}
+
+// Lock locks all the pools.
+func (r *routerPool) Lock() error {
+	return r.each(Pool.Lock)
+}
+
+// SubmitWithResult submits the process to its pool.
+func (r *routerPool) SubmitWithResult(p Process) (<-chan interface{}, error) {
+	pool, p, err := r.place(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return pool.SubmitWithResult(p)
+}
+
+// RegisterBarrier registers the barrier to the pool of the group. All the
+// processes of the group must be held by the same pool, which must also be
+// the pool of the barrier PID.
+func (r *routerPool) RegisterBarrier(barrierPID PID, group []PID) error {
+	owner := -1
+	for _, pid := range group {
+		i, ok := r.routing.owner(pid)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrProcessNotFound, pid)
+		}
+		if owner >= 0 && i != owner {
+			return r.pidError("register the barrier", ErrProcessRouted, pid)
+		}
+		owner = i
+	}
+	if owner < 0 {
+		var ok bool
+		if owner, ok = r.routing.owner(barrierPID); !ok {
+			owner = 0
+		}
+	}
+	if err := r.bind(barrierPID, owner); err != nil {
+		return err
+	}
+
+	return r.pools[owner].RegisterBarrier(barrierPID, group)
+}
+
+// AwaitIdle waits until all the pools are idle.
+func (r *routerPool) AwaitIdle(ctx context.Context) error {
+	for _, p := range r.pools {
+		if err := p.AwaitIdle(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Wait blocks until the processes of all the pools are finished and returns
+// the errors of all the pools.
+func (r *routerPool) Wait() error {
+	return r.each(Pool.Wait)
+}
+
+// WaitContext blocks until the processes of all the pools are finished or
+// ctx is done.
+func (r *routerPool) WaitContext(ctx context.Context) error {
+	return r.AwaitIdle(ctx)
+}
+
+// NotifyOn sends the result of the process from its pool to ch.
+func (r *routerPool) NotifyOn(pid PID, ch chan<- ProcessResult) error {
+	p, err := r.route(pid)
+	if err != nil {
+		return err
+	}
+
+	return p.NotifyOn(pid, ch)
+}
+
+// Reorder reorders the queue of each pool and returns the total number of
+// moved processes.
+func (r *routerPool) Reorder(less func(a, b Process) bool) (int, error) {
+	moved := 0
+	err := r.each(func(p Pool) error {
+		n, err := p.Reorder(less)
+		moved += n
+		return err
+	})
+
+	return moved, err
+}
+
+// CompletionStream merges the completion streams of all the pools. The
+// results of each pool are in completion order.
+func (r *routerPool) CompletionStream() <-chan ProcessResult {
+	out := make(chan ProcessResult, len(r.pools))
+	wg := new(sync.WaitGroup)
+	for _, p := range r.pools {
+		wg.Add(1)
+		go func(stream <-chan ProcessResult) {
+			defer wg.Done()
+			for result := range stream {
+				out <- result
+			}
+		}(p.CompletionStream())
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// RegisterSync registers the process to its pool and waits until a worker
+// picks it up.
+func (r *routerPool) RegisterSync(ctx context.Context, p Process) error {
+	pool, p, err := r.place(p)
+	if err != nil {
+		return err
+	}
+
+	return pool.RegisterSync(ctx, p)
+}
+
+// WaitUntilStatus waits for the process in its pool.
+func (r *routerPool) WaitUntilStatus(ctx context.Context, pid PID, statuses ...process.Status) (process.Status, error) {
+	p, err := r.route(pid)
+	if err != nil {
+		return process.Waiting, err
+	}
+
+	return p.WaitUntilStatus(ctx, pid, statuses...)
+}
+
+// ForWorker returns a handle of a worker of the router, whose name is
+// prefixed with the pool index.
+func (r *routerPool) ForWorker(name WorkerName) (WorkerHandle, error) {
+	i, wn, ok := splitWorkerName(name, len(r.pools))
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrWorkerNotFound, name)
+	}
+
+	return r.pools[i].ForWorker(wn)
+}
+
+// Namespace returns a router over the namespaces of the pools.
+func (r *routerPool) Namespace(ns string) Pool {
+	pools := make([]Pool, 0, len(r.pools))
+	for _, p := range r.pools {
+		pools = append(pools, p.Namespace(ns))
+	}
+
+	return &routerPool{pools: pools, routing: r.routing.fork()}
+}
+
+// SubPool returns a router over the sub-pools of the pools. Each pool lends
+// up to maxWorkers of its workers to its sub-pool.
+func (r *routerPool) SubPool(name string, maxWorkers int) Pool {
+	pools := make([]Pool, 0, len(r.pools))
+	for _, p := range r.pools {
+		pools = append(pools, p.SubPool(name, maxWorkers))
+	}
+
+	return &routerPool{pools: pools, routing: r.routing.fork()}
+}
+
+// routerWorkerName prefixes the worker name with the pool index.
+func routerWorkerName(i int, name WorkerName) WorkerName {
+	return WorkerName(strconv.Itoa(i)+routerWorkerSeparator) + name
+}
+
+// splitWorkerName returns the pool index and the worker name of a router
+// worker name.
+func splitWorkerName(name WorkerName, n int) (int, WorkerName, bool) {
+	parts := strings.SplitN(string(name), routerWorkerSeparator, 2)
+	if len(parts) != 2 {
+		return 0, "", false
+	}
+
+	i, err := strconv.Atoi(parts[0])
+	if err != nil || i < 0 || i >= n {
+		return 0, "", false
+	}
+
+	return i, WorkerName(parts[1]), true
+}
+
+// PoolStatus returns Running if a pool is running, Paused if a pool is paused
+// and none is running, Errored if a pool is errored and none is running or
+// paused, Closed if all the pools are closed, and Created otherwise.
+func (m *routerMonitor) PoolStatus() pool.Status {
+	closed, errored, paused := 0, 0, 0
+	for _, mon := range m.monitors {
+		switch mon.PoolStatus() {
+		case pool.Running:
+			return pool.Running
+		case pool.Paused:
+			paused++
+		case pool.Errored:
+			errored++
+		case pool.Closed:
+			closed++
+		}
+	}
+	if paused > 0 {
+		return pool.Paused
+	}
+	if errored > 0 {
+		return pool.Errored
+	}
+	if closed == len(m.monitors) {
+		return pool.Closed
+	}
+
+	return pool.Created
+}
+
+// owner returns the monitor that holds the process.
+func (m *routerMonitor) owner(pid PID) Monitor {
+	for _, mon := range m.monitors {
+		if _, ok := mon.ProcessStats(pid); ok {
+			return mon
+		}
+	}
+
+	return m.monitors[0]
+}
+
+// Error returns the process error from the monitor that holds it.
+func (m *routerMonitor) Error(pid PID) (error, bool) { //nolint:stylecheck
+	return m.owner(pid).Error(pid)
+}
+
+// Histogram returns the histogram of the process from the pool that holds it.
+func (m *routerMonitor) Histogram(pid PID) (*DurationHistogram, bool) {
+	return m.owner(pid).Histogram(pid)
+}
+
+// WorkerList returns the workers of all the pools, prefixed with the pool
+// index.
+func (m *routerMonitor) WorkerList() []WorkerName {
+	list := make([]WorkerName, 0)
+	for i, mon := range m.monitors {
+		for _, wn := range mon.WorkerList() {
+			list = append(list, routerWorkerName(i, wn))
+		}
+	}
+
+	return list
+}
+
+// WorkerStatus returns the status of a worker of the router.
+func (m *routerMonitor) WorkerStatus(name WorkerName) worker.Status {
+	i, wn, ok := splitWorkerName(name, len(m.monitors))
+	if !ok {
+		return worker.Status(0)
+	}
+
+	return m.monitors[i].WorkerStatus(wn)
+}
+
+// WorkerStats returns the stats of a worker of the router, named with the
+// pool index.
+func (m *routerMonitor) WorkerStats(name WorkerName) (WorkerStats, bool) {
+	i, wn, ok := splitWorkerName(name, len(m.monitors))
+	if !ok {
+		return WorkerStats{}, false
+	}
+
+	stats, ok := m.monitors[i].WorkerStats(wn)
+	if ok {
+		stats.WorkerName = name
+	}
+
+	return stats, ok
+}
+
+// ProcessStats returns the process stats from the monitor that holds it.
+func (m *routerMonitor) ProcessStats(pid PID) (ProcessStats, bool) {
+	return m.owner(pid).ProcessStats(pid)
+}
+
+// Delta merges the deltas of all the pools.
+func (m *routerMonitor) Delta(since time.Time) MonitorDelta {
+	delta := MonitorDelta{
+		Since:      since,
+		PoolStatus: m.PoolStatus(),
+		Processes:  make([]ProcessStats, 0),
+	}
+	for _, mon := range m.monitors {
+		d := mon.Delta(since)
+		delta.PoolStats = addPoolStats(delta.PoolStats, d.PoolStats)
+		delta.Processes = append(delta.Processes, d.Processes...)
+	}
+
+	return delta
+}
+
+// CompletedProcesses returns the completed processes of all the pools.
+func (m *routerMonitor) CompletedProcesses() []ProcessStats {
+	completed := make([]ProcessStats, 0)
+	for _, mon := range m.monitors {
+		completed = append(completed, mon.CompletedProcesses()...)
+	}
+
+	return completed
+}
+
+// Purge purges all the pools.
+func (m *routerMonitor) Purge(olderThan time.Time) int {
+	purged := 0
+	for _, mon := range m.monitors {
+		purged += mon.Purge(olderThan)
+	}
+
+	return purged
+}
+
+// ResetStats resets the stats of all the pools.
+func (m *routerMonitor) ResetStats() error {
+	var errs []error
+	for _, mon := range m.monitors {
+		if err := mon.ResetStats(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return &MultiError{Errors: errs}
+	}
+
+	return nil
+}
+
+// Metrics returns the sum of the metrics of all the pools, with the average
+// duration of all the processes.
+func (m *routerMonitor) Metrics() MetricsSnapshot {
+	var snapshot MetricsSnapshot
+	var runTime time.Duration
+	for _, mon := range m.monitors {
+		s := mon.Metrics()
+		snapshot.QueueDepth += s.QueueDepth
+		snapshot.ActiveWorkers += s.ActiveWorkers
+		snapshot.IdleWorkers += s.IdleWorkers
+		snapshot.TotalSucceeded += s.TotalSucceeded
+		snapshot.TotalFailed += s.TotalFailed
+		snapshot.TotalKilled += s.TotalKilled
+		snapshot.TotalCancelled += s.TotalCancelled
+		snapshot.TotalDropped += s.TotalDropped
+		snapshot.ProcessesRun += s.ProcessesRun
+		runTime += s.AverageDuration * time.Duration(s.ProcessesRun)
+	}
+	if snapshot.ProcessesRun > 0 {
+		snapshot.AverageDuration = runTime / time.Duration(snapshot.ProcessesRun)
+	}
+
+	return snapshot
+}
+
+// TotalErrors returns the number of failed processes of all the pools.
+func (m *routerMonitor) TotalErrors() int {
+	n := 0
+	for _, mon := range m.monitors {
+		n += mon.TotalErrors()
+	}
+
+	return n
+}
+
+// GroupStatus returns the rolled-up state of the processes of the group in
+// all the pools.
+func (m *routerMonitor) GroupStatus(name string) (process.Status, bool) {
+	return groupStatus(m.GroupStats(name))
+}
+
+// GroupStats sums the group stats of all the pools.
+func (m *routerMonitor) GroupStats(name string) GroupStats {
+	var stats GroupStats
+	for _, mon := range m.monitors {
+		stats = stats.add(mon.GroupStats(name))
+	}
+
+	return stats
+}
+
+// CircuitStatus returns Open if the circuit of the process name is open in a
+// pool, otherwise HalfOpen if it is half-open in a pool, and Closed
+// otherwise.
+func (m *routerMonitor) CircuitStatus(name string) circuit.Status {
+	status := circuit.Closed
+	for _, mon := range m.monitors {
+		switch s := mon.CircuitStatus(name); {
+		case s == circuit.Open:
+			return s
+		case s == circuit.HalfOpen:
+			status = s
+		}
+	}
+
+	return status
+}
+
+// BroadcastStats sums the broadcast stats of all the pools.
+func (m *routerMonitor) BroadcastStats(pid PID) GroupStats {
+	var stats GroupStats
+	for _, mon := range m.monitors {
+		stats = stats.add(mon.BroadcastStats(pid))
+	}
+
+	return stats
+}
+
+// ActiveWorkerCount returns the number of busy workers of all the pools.
+func (m *routerMonitor) ActiveWorkerCount() int {
+	n := 0
+	for _, mon := range m.monitors {
+		n += mon.ActiveWorkerCount()
+	}
+
+	return n
+}
+
+// IdleWorkerCount returns the number of idle workers of all the pools.
+func (m *routerMonitor) IdleWorkerCount() int {
+	n := 0
+	for _, mon := range m.monitors {
+		n += mon.IdleWorkerCount()
+	}
+
+	return n
+}
+
+// StartRate returns the process start rate of all the pools.
+func (m *routerMonitor) StartRate() float64 {
+	rate := 0.0
+	for _, mon := range m.monitors {
+		rate += mon.StartRate()
+	}
+
+	return rate
+}
+
+// WithFilter returns a router monitor over the filtered monitors.
+func (m *routerMonitor) WithFilter(pattern *regexp.Regexp) Monitor {
+	filtered := &routerMonitor{monitors: make([]Monitor, 0, len(m.monitors))}
+	for _, mon := range m.monitors {
+		filtered.monitors = append(filtered.monitors, mon.WithFilter(pattern))
+	}
+
+	return filtered
+}
+
+// ErrorCatalog returns the unique errors of all the pools. The catalog keys
+// are renumbered, so ProcessStats.ErrorIndex refers to the catalog of the
+// pool of the process.
+func (m *routerMonitor) ErrorCatalog() map[int]error {
+	catalog := make(map[int]error)
+	for _, mon := range m.monitors {
+		errs := mon.ErrorCatalog()
+		keys := make([]int, 0, len(errs))
+		for k := range errs {
+			keys = append(keys, k)
+		}
+		sort.Ints(keys)
+		for _, k := range keys {
+			catalog[len(catalog)+1] = errs[k]
+		}
+	}
+
+	return catalog
+}
+
+// DrainAudit merges the drain audits of all the pools.
+func (m *routerMonitor) DrainAudit() DrainAudit {
+	var audit DrainAudit
+	for _, mon := range m.monitors {
+		a := mon.DrainAudit()
+		audit.InFlightAtClose = append(audit.InFlightAtClose, a.InFlightAtClose...)
+		audit.WaitingAtClose = append(audit.WaitingAtClose, a.WaitingAtClose...)
+		audit.CompletedDuringDrain = append(audit.CompletedDuringDrain, a.CompletedDuringDrain...)
+	}
+
+	return audit
+}
@@ -0,0 +1,118 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test the router always routes a PID to the same pool
+func TestConsistentHashRouter_SamePoolForPID(t *testing.T) {
+	a := assert.New(t)
+	pools := []Pool{NewPool(WithWorkerCount(2)), NewPool(WithWorkerCount(2)), NewPool(WithWorkerCount(2))}
+	r := NewConsistentHashRouter(pools, 50).(*routerPool)
+	route := func(r *routerPool, pid PID) Pool {
+		p, err := r.route(pid)
+		a.NoError(err)
+		return p
+	}
+
+	owners := make(map[PID]Pool)
+	for i := 0; i < 100; i++ {
+		pid := PID("p-" + strconv.Itoa(i))
+		owners[pid] = route(r, pid)
+	}
+
+	for round := 0; round < 3; round++ {
+		for pid, owner := range owners {
+			a.True(owner == route(r, pid))
+		}
+	}
+
+	again := NewConsistentHashRouter(pools, 50).(*routerPool)
+	used := make(map[Pool]bool)
+	for pid, owner := range owners {
+		a.True(owner == route(again, pid))
+		used[owner] = true
+	}
+	a.Len(used, 3)
+}
+
+// Test the registered processes run in the pool of their PID
+func TestConsistentHashRouter_Register(t *testing.T) {
+	a := assert.New(t)
+	pools := []Pool{NewPool(WithWorkerCount(2)), NewPool(WithWorkerCount(2)), NewPool(WithWorkerCount(2))}
+	r := NewConsistentHashRouter(pools, 50)
+	a.NoError(r.Start(context.Background()))
+
+	procs := createProcess(10, 1, 10*time.Millisecond, processFuncWithoutLog)
+	a.NoError(r.Register(procs...))
+	r.Wait()
+
+	router := r.(*routerPool)
+	for _, p := range procs {
+		owner, err := router.route(p.PID())
+		a.NoError(err)
+		for _, pool := range pools {
+			_, ok := pool.Monitor().ProcessStats(p.PID())
+			a.Equal(pool == owner, ok)
+		}
+		a.NoError(processError(t, r.Monitor(), p.PID()))
+	}
+	a.Equal(10, int(r.Stats().TotalSucceeded))
+	a.Len(r.Monitor().WorkerList(), 6)
+
+	a.NoError(r.Close())
+}
+
+// A broadcast process should run on every worker of the pool of its PID
+func TestConsistentHashRouter_BroadcastRegister(t *testing.T) {
+	a := assert.New(t)
+	pools := []Pool{NewPool(WithWorkerCount(2)), NewPool(WithWorkerCount(3))}
+	r := NewConsistentHashRouter(pools, 50)
+	a.NoError(r.Start(context.Background()))
+
+	p := newTestProcess("warmup", 1, time.Second, processFuncWithoutLog)
+	a.NoError(r.BroadcastRegister(p))
+	owner, err := r.(*routerPool).route(p.PID())
+	a.NoError(err)
+	workers := len(owner.Monitor().WorkerList())
+	a.Equal(workers, r.Monitor().BroadcastStats(p.PID()).Total)
+
+	r.Kill(p.PID())
+	_ = r.Wait()
+	a.Equal(GroupStats{Total: workers, Killed: workers}, r.Monitor().BroadcastStats(p.PID()))
+
+	a.NoError(r.Close())
+}
+
+// A barrier should be rejected if its group is routed to different pools
+func TestConsistentHashRouter_RegisterBarrier(t *testing.T) {
+	a := assert.New(t)
+	r := NewConsistentHashRouter([]Pool{NewPool(WithWorkerCount(1)), NewPool(WithWorkerCount(1))}, 50)
+	a.NoError(r.Start(context.Background()))
+
+	ring := r.(*routerPool).routing
+	first, _ := ring.owner("p-1")
+	other := PID("")
+	for i := 2; other == ""; i++ {
+		if owner, _ := ring.owner(PID("p-" + strconv.Itoa(i))); owner != first {
+			other = PID("p-" + strconv.Itoa(i))
+		}
+	}
+
+	err := r.RegisterBarrier("barrier", []PID{"p-1", other})
+	a.ErrorIs(err, ErrProcessRouted)
+	var poolErr *PoolError
+	a.ErrorAs(err, &poolErr)
+	a.Equal(other, poolErr.PID)
+
+	a.NoError(r.Close())
+}
@@ -0,0 +1,125 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// ErrNoRoute is returned by a routing pool when no route matches the name of
+// a process and there is no default route.
+var ErrNoRoute = errors.New("no route for the process")
+
+type (
+	// Route sends the processes whose name matches Pattern to Pool. A route
+	// with a nil Pattern is the default route, it receives the processes
+	// that match no other route.
+	Route struct {
+		// Pattern is the pattern of the process names of the route.
+		Pattern *regexp.Regexp
+
+		// Pool is the pool that runs the processes of the route.
+		Pool Pool
+	}
+
+	// nameRouting assigns the processes to the pools by their name and
+	// remembers the pool of each process id.
+	nameRouting struct {
+		patterns []*regexp.Regexp
+		targets  []int
+		fallback int
+		mutex    sync.RWMutex
+		owners   map[PID]int
+	}
+)
+
+// NewRoutingPool returns a Pool that dispatches each process to the pool of
+// the first route whose Pattern matches the process name, or to the pool of
+// the default route if none matches. Register returns ErrNoRoute if a process
+// has no route. Several routes can share a pool. The methods about a process
+// go to the pool that it has been dispatched to, and the other methods apply
+// to all the pools. The worker names of the routing pool are prefixed with
+// the pool index in the order the pools first appear in routes, such as
+// "0/W1". It panics if routes is empty.
+func NewRoutingPool(routes []Route) Pool {
+	if len(routes) == 0 {
+		panic("gowl: routing pool needs at least one route")
+	}
+
+	r := &routerPool{}
+	routing := &nameRouting{
+		fallback: -1,
+		owners:   make(map[PID]int),
+	}
+	indexes := make(map[Pool]int)
+	for _, route := range routes {
+		i, ok := indexes[route.Pool]
+		if !ok {
+			i = len(r.pools)
+			indexes[route.Pool] = i
+			r.pools = append(r.pools, route.Pool)
+		}
+
+		if route.Pattern == nil {
+			if routing.fallback < 0 {
+				routing.fallback = i
+			}
+			continue
+		}
+		routing.patterns = append(routing.patterns, route.Pattern)
+		routing.targets = append(routing.targets, i)
+	}
+	r.routing = routing
+
+	return r
+}
+
+// assign returns the pool of the first route that matches the process name.
+func (n *nameRouting) assign(p Process) (int, error) {
+	for i, pattern := range n.patterns {
+		if pattern.MatchString(p.Name()) {
+			return n.targets[i], nil
+		}
+	}
+
+	if n.fallback < 0 {
+		return 0, fmt.Errorf("%w: %s", ErrNoRoute, p.Name())
+	}
+
+	return n.fallback, nil
+}
+
+// bind remembers the pool of the process id.
+func (n *nameRouting) bind(pid PID, i int) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.owners[pid] = i
+
+	return nil
+}
+
+// owner returns the pool that the process id has been dispatched to.
+func (n *nameRouting) owner(pid PID) (int, bool) {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	i, ok := n.owners[pid]
+
+	return i, ok
+}
+
+// fork returns a routing with the same routes and no process.
+func (n *nameRouting) fork() routing {
+	return &nameRouting{
+		patterns: n.patterns,
+		targets:  n.targets,
+		fallback: n.fallback,
+		owners:   make(map[PID]int),
+	}
+}
@@ -0,0 +1,64 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// Each process should run in the pool of the route that matches its name
+func TestNewRoutingPool(t *testing.T) {
+	a := assert.New(t)
+	dbPool, httpPool, defaultPool := NewPool(WithWorkerCount(1)), NewPool(WithWorkerCount(1)), NewPool(WithWorkerCount(1))
+	rp := NewRoutingPool([]Route{
+		{Pattern: regexp.MustCompile(`^db-`), Pool: dbPool},
+		{Pattern: regexp.MustCompile(`^http-`), Pool: httpPool},
+		{Pool: defaultPool},
+	})
+	err := rp.Start(context.Background())
+	a.NoError(err)
+
+	err = rp.Register(
+		newTestProcess("db-query", 1, 10*time.Millisecond, processFuncWithoutLog),
+		newTestProcess("http-call", 2, 10*time.Millisecond, processFuncWithoutLog),
+		newTestProcess("cleanup", 3, 10*time.Millisecond, processFuncWithoutLog),
+	)
+	a.NoError(err)
+	a.NoError(rp.Wait())
+
+	for pid, p := range map[PID]Pool{"p-1": dbPool, "p-2": httpPool, "p-3": defaultPool} {
+		a.Equal(int64(1), p.Stats().TotalSucceeded)
+		a.Equal(process.Succeeded, processStats(t, p.Monitor(), pid).Status)
+		status, err := rp.WaitUntilStatus(context.Background(), pid, process.Succeeded)
+		a.NoError(err)
+		a.Equal(process.Succeeded, status)
+	}
+	a.Len(rp.Monitor().WorkerList(), 3)
+
+	err = rp.Close()
+	a.NoError(err)
+}
+
+// A process without a route should not be registered
+func TestNewRoutingPool_NoRoute(t *testing.T) {
+	a := assert.New(t)
+	dbPool := NewPool(WithWorkerCount(1))
+	rp := NewRoutingPool([]Route{{Pattern: regexp.MustCompile(`^db-`), Pool: dbPool}})
+
+	err := rp.Register(
+		newTestProcess("db-query", 1, 0, processFuncWithoutLog),
+		newTestProcess("http-call", 2, 0, processFuncWithoutLog),
+	)
+	a.ErrorIs(err, ErrNoRoute)
+	a.Zero(dbPool.Stats().TotalRegistered)
+	a.ErrorIs(rp.NotifyOn("p-1", make(chan ProcessResult, 1)), ErrProcessNotFound)
+}
@@ -0,0 +1,89 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"math"
+	"time"
+)
+
+type (
+	// ScalingPolicy decides the number of workers of the pool. The pool calls
+	// Evaluate periodically and resizes itself when the returned number is
+	// different from the current number of workers.
+	ScalingPolicy interface {
+		// Evaluate returns the desired number of workers for the given stats.
+		Evaluate(stats PoolStats) int
+	}
+
+	// ScalingPolicyFunc is an adapter to use an ordinary function as a
+	// ScalingPolicy.
+	ScalingPolicyFunc func(stats PoolStats) int
+)
+
+// Evaluate calls f(stats).
+func (f ScalingPolicyFunc) Evaluate(stats PoolStats) int {
+	return f(stats)
+}
+
+// TargetQueueDepth returns a policy that adds a worker for each process
+// beyond depth that is waiting in the queue, and removes the idle workers
+// once the queue is empty. The pool always keeps at least one worker.
+func TargetQueueDepth(depth int) ScalingPolicy {
+	return ScalingPolicyFunc(func(stats PoolStats) int {
+		workers := stats.ActiveWorkers + stats.IdleWorkers
+		switch {
+		case stats.QueueDepth > int64(depth):
+			return workers + int(stats.QueueDepth-int64(depth))
+		case stats.QueueDepth == 0:
+			return atLeastOne(stats.ActiveWorkers)
+		default:
+			return workers
+		}
+	})
+}
+
+// TargetUtilization returns a policy that keeps the fraction of busy workers
+// around pct, which must be in (0, 1]. The pool always keeps at least one
+// worker.
+func TargetUtilization(pct float64) ScalingPolicy {
+	return ScalingPolicyFunc(func(stats PoolStats) int {
+		return atLeastOne(int(math.Ceil(float64(stats.ActiveWorkers) / pct)))
+	})
+}
+
+// atLeastOne returns n, or 1 if n is less than 1.
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+
+	return n
+}
+
+// autoscale evaluates the scaling policy every interval and resizes the pool
+// until the pool is closed.
+func (w *workerPool) autoscale(policy ScalingPolicy, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	done := w.closeSignal()
+	for {
+		select {
+		case <-ticker.C:
+			stats := w.Stats()
+			desired := policy.Evaluate(stats)
+			if desired == stats.ActiveWorkers+stats.IdleWorkers {
+				continue
+			}
+
+			if err := w.Resize(desired); err != nil {
+				w.log(levelError, "unable to resize the pool", Field{"workers", desired}, Field{"error", err})
+			}
+		case <-done:
+			return
+		}
+	}
+}
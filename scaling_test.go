@@ -0,0 +1,41 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Queue depth policy should grow the pool for a burst and shrink it after
+func TestTargetQueueDepth(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithScalingPolicy(TargetQueueDepth(0), 20*time.Millisecond))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	err = wp.Register(createProcess(10, 1, 200*time.Millisecond, processFunc)...)
+	a.NoError(err)
+
+	time.Sleep(100 * time.Millisecond)
+	a.Greater(len(wp.Monitor().WorkerList()), 1)
+
+	time.Sleep(500 * time.Millisecond)
+	a.Len(wp.Monitor().WorkerList(), 1)
+	a.Equal(int64(10), wp.Stats().TotalSucceeded)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Utilization policy should recommend enough workers for the target
+func TestTargetUtilization(t *testing.T) {
+	a := assert.New(t)
+	policy := TargetUtilization(0.5)
+	a.Equal(6, policy.Evaluate(PoolStats{ActiveWorkers: 3, IdleWorkers: 1}))
+	a.Equal(1, policy.Evaluate(PoolStats{IdleWorkers: 4}))
+}
@@ -0,0 +1,49 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+// sequentialProcess wraps a process of a sequential group to run it after
+// the previous process of the group.
+type sequentialProcess struct {
+	Process
+	prev PID
+}
+
+// unwrap returns the wrapped process.
+func (s sequentialProcess) unwrap() Process {
+	return s.Process
+}
+
+// predecessor returns the id of the previous process of the sequential group
+// of the process.
+func predecessor(p Process) (PID, bool) {
+	var prev PID
+	found := findLayer(p, func(l Process) bool {
+		sp, ok := l.(sequentialProcess)
+		if ok {
+			prev = sp.prev
+		}
+		return ok
+	})
+
+	return prev, found
+}
+
+// RegisterSequential registers a group of processes that run one at a time
+// in the given order, on any worker. Each process is Pending until the
+// previous one is finished, whatever its final status, and the processes of
+// different groups interleave freely. It returns the same errors as
+// Register.
+func (w *workerPool) RegisterSequential(args ...Process) error {
+	group := make([]Process, 0, len(args))
+	for i, p := range args {
+		if i > 0 {
+			p = sequentialProcess{Process: p, prev: args[i-1].PID()}
+		}
+		group = append(group, p)
+	}
+
+	return w.Register(group...)
+}
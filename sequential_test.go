@@ -0,0 +1,74 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// The processes of a sequential group should run in order, and the groups
+// should interleave
+func TestWorkerPool_RegisterSequential(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(4))
+	a.NoError(wp.Start(context.Background()))
+
+	type span struct {
+		start, end time.Time
+	}
+	mutex := new(sync.Mutex)
+	spans := make(map[PID]span)
+	record := func(ctx context.Context, pid PID, d time.Duration) error {
+		start := time.Now()
+		time.Sleep(d)
+		mutex.Lock()
+		spans[pid] = span{start: start, end: time.Now()}
+		mutex.Unlock()
+		return nil
+	}
+
+	groups := [][]Process{
+		createProcess(3, 1, 30*time.Millisecond, record),
+		createProcess(3, 2, 30*time.Millisecond, record),
+	}
+	for _, group := range groups {
+		a.NoError(wp.RegisterSequential(group...))
+	}
+	a.Equal(process.Pending, processStats(t, wp.Monitor(), "p-12").Status)
+	a.NoError(wp.Wait())
+
+	for _, group := range groups {
+		for i := 1; i < len(group); i++ {
+			prev, next := spans[group[i-1].PID()], spans[group[i].PID()]
+			a.False(next.start.Before(prev.end), "%s started before %s ended", group[i].PID(), group[i-1].PID())
+		}
+	}
+	a.True(spans["p-21"].start.Before(spans["p-13"].start))
+
+	a.NoError(wp.Close())
+}
+
+// A sequential group should go on after a process of the group fails
+func TestWorkerPool_RegisterSequentialFailure(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Start(context.Background()))
+
+	a.NoError(wp.RegisterSequential(
+		newTestProcess("first", 1, 0, processFuncWithError),
+		newTestProcess("second", 2, 0, processFuncWithoutLog),
+	))
+	a.Error(wp.Wait())
+	a.Equal(process.Failed, processStats(t, wp.Monitor(), "p-1").Status)
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-2").Status)
+
+	a.NoError(wp.Close())
+}
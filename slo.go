@@ -0,0 +1,196 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+type (
+	// SLOTargets are the service level objectives of a pool. A zero target
+	// is not checked.
+	SLOTargets struct {
+		// Availability is the minimum fraction of time the pool is Running.
+		Availability float64
+
+		// SuccessRate is the minimum fraction of finished processes that
+		// succeeded.
+		SuccessRate float64
+
+		// LatencyP99 is the maximum 99th percentile of the running time of
+		// the processes.
+		LatencyP99 time.Duration
+
+		// Throughput is the minimum number of finished processes per second.
+		Throughput float64
+	}
+
+	// SLOReport holds the service level indicators of a pool over a window
+	// of time, for an operational review. It can be encoded to JSON.
+	SLOReport struct {
+		// Window is the duration that the indicators are computed over. It
+		// is shorter than the requested window for a pool that has been
+		// created within the window.
+		Window time.Duration
+
+		// Availability is the fraction of the window the pool was Running.
+		Availability float64
+
+		// Processes is the number of processes that finished within the
+		// window.
+		Processes int
+
+		// SuccessRate is the fraction of the finished processes that
+		// succeeded. It is 1 if no process has finished.
+		SuccessRate float64
+
+		// ErrorRate is the fraction of the finished processes that did not
+		// succeed.
+		ErrorRate float64
+
+		// LatencyP50 is the median running time of the finished processes.
+		LatencyP50 time.Duration
+
+		// LatencyP95 is the 95th percentile of the running time of the
+		// finished processes.
+		LatencyP95 time.Duration
+
+		// LatencyP99 is the 99th percentile of the running time of the
+		// finished processes.
+		LatencyP99 time.Duration
+
+		// Throughput is the number of finished processes per second.
+		Throughput float64
+
+		// Targets are the targets that the report is checked against.
+		Targets SLOTargets
+
+		// MeetsTargets reports whether every target is met.
+		MeetsTargets bool
+	}
+
+	// statusChange is a change of the pool status.
+	statusChange struct {
+		status pool.Status
+		at     time.Time
+	}
+)
+
+// SLOReport returns the service level indicators of the pool over the last
+// window, checked against the targets set by WithSLOTargets. The indicators
+// are computed from the processes whose stats are still in the monitor, so
+// Purge and ResetStats remove the purged processes from the report.
+func (w *workerPool) SLOReport(window time.Duration) SLOReport {
+	now := time.Now()
+	from := now.Add(-window)
+
+	w.statusMutex.RLock()
+	history := append([]statusChange(nil), w.history...)
+	w.statusMutex.RUnlock()
+	if created := history[0].at; from.Before(created) {
+		from = created
+	}
+
+	report := SLOReport{
+		Window:       now.Sub(from),
+		Availability: availability(history, from, now),
+		Targets:      w.config.SLOTargets,
+	}
+
+	succeeded := 0
+	latencies := make([]time.Duration, 0)
+	w.processes.each(func(_ PID, stats ProcessStats) {
+		if !stats.Status.IsTerminal() || stats.FinishedAt.Before(from) {
+			return
+		}
+		report.Processes++
+		if stats.Status == process.Succeeded {
+			succeeded++
+		}
+		if !stats.StartedAt.IsZero() {
+			latencies = append(latencies, stats.FinishedAt.Sub(stats.StartedAt))
+		}
+	})
+
+	report.SuccessRate = 1
+	if report.Processes > 0 {
+		report.SuccessRate = float64(succeeded) / float64(report.Processes)
+	}
+	report.ErrorRate = 1 - report.SuccessRate
+	if report.Window > 0 {
+		report.Throughput = float64(report.Processes) / report.Window.Seconds()
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.LatencyP50 = percentile(latencies, 50)
+	report.LatencyP95 = percentile(latencies, 95)
+	report.LatencyP99 = percentile(latencies, 99)
+	report.MeetsTargets = report.meets(report.Targets)
+
+	return report
+}
+
+// meets reports whether the indicators meet the targets.
+func (r SLOReport) meets(t SLOTargets) bool {
+	return r.Availability >= t.Availability &&
+		r.SuccessRate >= t.SuccessRate &&
+		(t.LatencyP99 == 0 || r.LatencyP99 <= t.LatencyP99) &&
+		r.Throughput >= t.Throughput
+}
+
+// availability returns the fraction of [from, to] that the pool was Running
+// according to its status history.
+func availability(history []statusChange, from, to time.Time) float64 {
+	if !to.After(from) {
+		return 0
+	}
+
+	var running time.Duration
+	for i, change := range history {
+		if change.status != pool.Running {
+			continue
+		}
+		start, end := change.at, to
+		if i+1 < len(history) {
+			end = history[i+1].at
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(start) {
+			running += end.Sub(start)
+		}
+	}
+
+	return float64(running) / float64(to.Sub(from))
+}
+
+// percentile returns the nearest-rank percentile of the sorted durations, or
+// zero if there is none.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// maxDuration returns the longer of the durations.
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+
+	return b
+}
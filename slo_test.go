@@ -0,0 +1,59 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/stretchr/testify/assert"
+)
+
+// A pool with 1% failing processes should just meet a 99% success rate target
+func TestWorkerPool_SLOReport(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(4), WithSLOTargets(SLOTargets{SuccessRate: 0.99}))
+	a.NoError(wp.Register(createProcess(99, 0, 0, processFuncWithoutLog)...))
+	a.NoError(wp.Register(newTestProcess("failing", 100, 0, processFuncWithError)))
+	a.NoError(wp.Start(context.Background()))
+	a.Error(wp.Wait())
+
+	report := wp.SLOReport(time.Minute)
+	a.Equal(100, report.Processes)
+	a.Equal(0.99, report.SuccessRate)
+	a.InDelta(0.01, report.ErrorRate, 1e-9)
+	a.Greater(report.Availability, 0.0)
+	a.Greater(report.Throughput, 0.0)
+	a.LessOrEqual(report.LatencyP50, report.LatencyP99)
+	a.True(report.MeetsTargets)
+
+	data, err := json.Marshal(report)
+	a.NoError(err)
+	var decoded SLOReport
+	a.NoError(json.Unmarshal(data, &decoded))
+	a.Equal(report, decoded)
+
+	a.NoError(wp.Register(newTestProcess("failing", 101, 0, processFuncWithError)))
+	a.Error(wp.Wait())
+	a.False(wp.SLOReport(time.Minute).MeetsTargets)
+	a.NoError(wp.Close())
+}
+
+// The availability should be the fraction of the window the pool was running
+func TestAvailability(t *testing.T) {
+	a := assert.New(t)
+	from := time.Now()
+	history := []statusChange{
+		{status: pool.Created, at: from},
+		{status: pool.Running, at: from.Add(time.Second)},
+		{status: pool.Closed, at: from.Add(3 * time.Second)},
+	}
+	a.Equal(0.5, availability(history, from, from.Add(4*time.Second)))
+	a.Equal(time.Duration(0), percentile(nil, 99))
+	a.Equal(3*time.Second, percentile([]time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, 99))
+}
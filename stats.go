@@ -0,0 +1,201 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/hamed-yousefi/gowl/status/worker"
+)
+
+type (
+	// PoolStats is a snapshot of the pool counters.
+	PoolStats struct {
+		// TotalRegistered is the number of processes registered to the pool.
+		TotalRegistered int64
+
+		// TotalSucceeded is the number of processes that ended without error.
+		TotalSucceeded int64
+
+		// TotalFailed is the number of processes that ended with error.
+		TotalFailed int64
+
+		// TotalKilled is the number of processes that have been killed.
+		TotalKilled int64
+
+		// TotalCancelled is the number of processes that have been cancelled
+		// because the pool has been closed.
+		TotalCancelled int64
+
+		// TotalDropped is the number of processes that have been dropped or
+		// evicted because the queue was full.
+		TotalDropped int64
+
+		// ActiveWorkers is the number of workers that are running a process.
+		ActiveWorkers int
+
+		// IdleWorkers is the number of workers that are waiting for a process.
+		IdleWorkers int
+
+		// QueueDepth is the number of processes waiting to be consumed.
+		QueueDepth int64
+
+		// Uptime is the duration since the pool started.
+		Uptime time.Duration
+	}
+
+	// MetricsSnapshot is a snapshot of the pool metrics, in a shape that maps
+	// to the gauges and counters of a metrics system such as Prometheus.
+	MetricsSnapshot struct {
+		// QueueDepth is the number of processes waiting to be consumed.
+		QueueDepth int64
+
+		// ActiveWorkers is the number of workers that are running a process.
+		ActiveWorkers int
+
+		// IdleWorkers is the number of workers that are waiting for a process.
+		IdleWorkers int
+
+		// TotalSucceeded is the number of processes that ended without error.
+		TotalSucceeded int64
+
+		// TotalFailed is the number of processes that ended with error.
+		TotalFailed int64
+
+		// TotalKilled is the number of processes that have been killed.
+		TotalKilled int64
+
+		// TotalCancelled is the number of processes that have been cancelled
+		// because the pool has been closed.
+		TotalCancelled int64
+
+		// TotalDropped is the number of processes that have been dropped or
+		// evicted because the queue was full.
+		TotalDropped int64
+
+		// ProcessesRun is the number of finished processes that have been
+		// started by a worker.
+		ProcessesRun int64
+
+		// AverageDuration is the average running time of the finished
+		// processes that have been started by a worker.
+		AverageDuration time.Duration
+	}
+
+	// statsSnapshot is the immutable pool stats that Stats returns. The
+	// uptime is computed when the snapshot is read.
+	statsSnapshot struct {
+		stats     PoolStats
+		startedAt time.Time
+	}
+
+	// poolCounters keeps the pool-wide process counters. All fields must be
+	// accessed atomically.
+	poolCounters struct {
+		registered int64
+		succeeded  int64
+		failed     int64
+		killed     int64
+		cancelled  int64
+		dropped    int64
+		waiting    int64
+		weight     int64
+		tracked    int64
+		ran        int64
+		runTime    int64
+	}
+)
+
+// SuccessRate returns the fraction of finished processes that succeeded. It
+// returns 1 if no process has finished yet.
+func (s PoolStats) SuccessRate() float64 {
+	finished := s.TotalSucceeded + s.TotalFailed + s.TotalKilled + s.TotalCancelled
+	if finished == 0 {
+		return 1
+	}
+
+	return float64(s.TotalSucceeded) / float64(finished)
+}
+
+// register counts a process that is added to the queue.
+func (c *poolCounters) register() {
+	atomic.AddInt64(&c.registered, 1)
+}
+
+// dequeue counts a process with the given weight that left the queue.
+func (c *poolCounters) dequeue(weight int64) {
+	atomic.AddInt64(&c.waiting, -1)
+	atomic.AddInt64(&c.weight, -weight)
+}
+
+// reset zeroes the counters of the registered and finished processes.
+func (c *poolCounters) reset() {
+	atomic.StoreInt64(&c.registered, 0)
+	atomic.StoreInt64(&c.succeeded, 0)
+	atomic.StoreInt64(&c.failed, 0)
+	atomic.StoreInt64(&c.killed, 0)
+	atomic.StoreInt64(&c.cancelled, 0)
+	atomic.StoreInt64(&c.dropped, 0)
+	atomic.StoreInt64(&c.ran, 0)
+	atomic.StoreInt64(&c.runTime, 0)
+}
+
+// run counts the running time of a finished process.
+func (c *poolCounters) run(d time.Duration) {
+	atomic.AddInt64(&c.ran, 1)
+	atomic.AddInt64(&c.runTime, int64(d))
+}
+
+// finish counts a process that reached the given final status.
+func (c *poolCounters) finish(status process.Status) {
+	switch status {
+	case process.Succeeded:
+		atomic.AddInt64(&c.succeeded, 1)
+	case process.Failed:
+		atomic.AddInt64(&c.failed, 1)
+	case process.Killed:
+		atomic.AddInt64(&c.killed, 1)
+	case process.Cancelled:
+		atomic.AddInt64(&c.cancelled, 1)
+	}
+}
+
+// publishStats takes a new snapshot of the pool stats for Stats. The worker
+// counts are read from the worker stats, so it can be called while the
+// workers mutex is held.
+func (w *workerPool) publishStats() {
+	w.snapshotLock.Lock()
+	defer w.snapshotLock.Unlock()
+
+	snapshot := &statsSnapshot{
+		stats: PoolStats{
+			TotalRegistered: atomic.LoadInt64(&w.counters.registered),
+			TotalSucceeded:  atomic.LoadInt64(&w.counters.succeeded),
+			TotalFailed:     atomic.LoadInt64(&w.counters.failed),
+			TotalKilled:     atomic.LoadInt64(&w.counters.killed),
+			TotalCancelled:  atomic.LoadInt64(&w.counters.cancelled),
+			TotalDropped:    atomic.LoadInt64(&w.counters.dropped),
+			QueueDepth:      atomic.LoadInt64(&w.counters.waiting),
+		},
+		startedAt: w.startedAt,
+	}
+	w.workersStats.each(func(_ WorkerName, status worker.Status) {
+		if status == worker.Busy {
+			snapshot.stats.ActiveWorkers++
+		} else {
+			snapshot.stats.IdleWorkers++
+		}
+	})
+	w.snapshot.Store(snapshot)
+}
+
+// notify publishes the stats and wakes up the goroutines that wait for a
+// state change of the pool.
+func (w *workerPool) notify() {
+	w.publishStats()
+	w.changes.broadcast()
+}
@@ -0,0 +1,34 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package circuit
+
+const (
+	// Closed is a circuit state when the processes of the name run as usual.
+	Closed Status = iota
+	// Open is a circuit state when the processes of the name fail without
+	// running.
+	Open
+	// HalfOpen is a circuit state when a single probe process of the name
+	// runs to decide whether the circuit closes again.
+	HalfOpen
+)
+
+var (
+	status2String = map[Status]string{
+		Closed:   "Closed",
+		Open:     "Open",
+		HalfOpen: "HalfOpen",
+	}
+)
+
+type (
+	// Status represents circuit breaker current state.
+	Status int
+)
+
+// String returns string value of circuit state.
+func (s Status) String() string {
+	return status2String[s]
+}
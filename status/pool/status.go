@@ -4,6 +4,11 @@
 
 package pool
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 const (
 	// Created is a pool state after pool has been created and before it starts.
 	Created Status = iota
@@ -11,6 +16,12 @@ const (
 	Running
 	// Closed is a pool state when the pool stopped by Close() function.
 	Closed
+	// Errored is a pool state when the pool stopped dequeuing processes
+	// because too many of them failed.
+	Errored
+	// Paused is a pool state when the pool stopped dispatching processes by
+	// Pause() function.
+	Paused
 )
 
 var (
@@ -18,6 +29,8 @@ var (
 		Created: "Created",
 		Running: "Running",
 		Closed:  "Closed",
+		Errored: "Errored",
+		Paused:  "Paused",
 	}
 )
 
@@ -30,3 +43,35 @@ type (
 func (p Status) String() string {
 	return status2string[p]
 }
+
+// IsOpen returns true if the pool has been started and is not closed yet.
+func (p Status) IsOpen() bool {
+	return p == Running || p == Errored || p == Paused
+}
+
+// MarshalJSON encodes the pool state as its string value.
+func (p Status) MarshalJSON() ([]byte, error) {
+	s, ok := status2string[p]
+	if !ok {
+		return nil, fmt.Errorf("unknown pool status: %d", int(p))
+	}
+
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON decodes the pool state from its string value.
+func (p *Status) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	for status, name := range status2string {
+		if name == s {
+			*p = status
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown pool status: %q", s)
+}
@@ -0,0 +1,71 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package pool
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Check the JSON encoding of all pool statuses
+func TestStatus_JSON(t *testing.T) {
+	tests := []struct {
+		status Status
+		json   string
+	}{
+		{status: Created, json: `"Created"`},
+		{status: Running, json: `"Running"`},
+		{status: Closed, json: `"Closed"`},
+		{status: Errored, json: `"Errored"`},
+		{status: Paused, json: `"Paused"`},
+	}
+
+	a := assert.New(t)
+	a.Len(tests, len(status2string))
+	for _, test := range tests {
+		data, err := json.Marshal(test.status)
+		a.NoError(err)
+		a.Equal(test.json, string(data))
+
+		var status Status
+		a.NoError(json.Unmarshal(data, &status))
+		a.Equal(test.status, status)
+	}
+
+	_, err := json.Marshal(Status(42))
+	a.Error(err)
+	var status Status
+	a.Error(json.Unmarshal([]byte(`"Stopped"`), &status))
+	a.Error(json.Unmarshal([]byte(`1`), &status))
+}
+
+// Only the started pools that are not closed should be open
+func TestStatus_IsOpen(t *testing.T) {
+	a := assert.New(t)
+	a.False(Created.IsOpen())
+	a.True(Running.IsOpen())
+	a.False(Closed.IsOpen())
+	a.True(Errored.IsOpen())
+	a.True(Paused.IsOpen())
+}
+
+// Pool status should survive a JSON round trip inside a struct
+func TestStatus_JSONRoundTrip(t *testing.T) {
+	type report struct {
+		Name   string `json:"name"`
+		Status Status `json:"status"`
+	}
+
+	a := assert.New(t)
+	data, err := json.Marshal(report{Name: "pool", Status: Running})
+	a.NoError(err)
+	a.JSONEq(`{"name":"pool","status":"Running"}`, string(data))
+
+	var r report
+	a.NoError(json.Unmarshal(data, &r))
+	a.Equal(Running, r.Status)
+}
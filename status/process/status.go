@@ -13,8 +13,18 @@ const (
 	Succeeded
 	// Failed is a process state when it has been ended with error.
 	Failed
-	// Killed is a process state when the process cancelled before running.
+	// Killed is a process state when the process has been killed by its id.
 	Killed
+	// Retrying is a process state when the process has failed and waits to run again.
+	Retrying
+	// Pending is a process state when the process waits for its dependencies to succeed.
+	Pending
+	// Cancelled is a process state when the process has been stopped by its pool, because the pool has been closed or
+	// the process has been evicted from a full queue.
+	Cancelled
+	// Skipped is a process state when the process has not been queued, because a process with the same id was still
+	// active when it was registered.
+	Skipped
 )
 
 var (
@@ -24,6 +34,10 @@ var (
 		Succeeded: "Succeeded",
 		Failed:    "Failed",
 		Killed:    "Killed",
+		Retrying:  "Retrying",
+		Pending:   "Pending",
+		Cancelled: "Cancelled",
+		Skipped:   "Skipped",
 	}
 )
 
@@ -36,3 +50,14 @@ type (
 func (s Status) String() string {
 	return status2String[s]
 }
+
+// IsTerminal returns true if the process has reached a final state and will
+// not change anymore.
+func (s Status) IsTerminal() bool {
+	return s == Succeeded || s == Failed || s == Killed || s == Cancelled || s == Skipped
+}
+
+// IsError returns true if the process did not complete normally.
+func (s Status) IsError() bool {
+	return s == Failed || s == Killed || s == Cancelled
+}
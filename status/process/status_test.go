@@ -0,0 +1,37 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Check IsError and IsTerminal for all process statuses
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		status     Status
+		isError    bool
+		isTerminal bool
+	}{
+		{status: Waiting, isError: false, isTerminal: false},
+		{status: Running, isError: false, isTerminal: false},
+		{status: Succeeded, isError: false, isTerminal: true},
+		{status: Failed, isError: true, isTerminal: true},
+		{status: Killed, isError: true, isTerminal: true},
+		{status: Retrying, isError: false, isTerminal: false},
+		{status: Pending, isError: false, isTerminal: false},
+		{status: Cancelled, isError: true, isTerminal: true},
+		{status: Skipped, isError: false, isTerminal: true},
+	}
+
+	a := assert.New(t)
+	a.Len(tests, len(status2String))
+	for _, test := range tests {
+		a.Equal(test.isError, test.status.IsError(), test.status.String())
+		a.Equal(test.isTerminal, test.status.IsTerminal(), test.status.String())
+	}
+}
@@ -0,0 +1,139 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import "sync"
+
+type (
+	// completionStream delivers the results of the finished processes in
+	// completion order. The results are buffered without limit, so a slow
+	// reader never blocks the workers.
+	completionStream struct {
+		mutex   sync.Mutex
+		pending []ProcessResult
+		closed  bool
+		signal  chan struct{}
+		out     chan ProcessResult
+	}
+
+	// completionStreams is the list of the open completion streams.
+	completionStreams struct {
+		mutex   sync.Mutex
+		streams []*completionStream
+		closed  bool
+	}
+)
+
+// newCompletionStream makes a new stream and starts delivering its results.
+func newCompletionStream(size int) *completionStream {
+	s := &completionStream{
+		signal: make(chan struct{}, 1),
+		out:    make(chan ProcessResult, size),
+	}
+	go s.deliver()
+
+	return s
+}
+
+// push adds the result to the stream.
+func (s *completionStream) push(r ProcessResult) {
+	s.mutex.Lock()
+	s.pending = append(s.pending, r)
+	s.mutex.Unlock()
+	s.notify()
+}
+
+// close makes the stream close its channel after the pending results are
+// delivered.
+func (s *completionStream) close() {
+	s.mutex.Lock()
+	s.closed = true
+	s.mutex.Unlock()
+	s.notify()
+}
+
+// notify wakes up the delivery goroutine.
+func (s *completionStream) notify() {
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+// deliver sends the pending results to the channel until the stream is
+// closed.
+func (s *completionStream) deliver() {
+	defer close(s.out)
+
+	for range s.signal {
+		s.mutex.Lock()
+		pending, closed := s.pending, s.closed
+		s.pending = nil
+		s.mutex.Unlock()
+
+		for _, r := range pending {
+			s.out <- r
+		}
+		if closed {
+			return
+		}
+	}
+}
+
+// add opens a new stream. The stream is closed right away if the pool is
+// already closed.
+func (c *completionStreams) add(size int) *completionStream {
+	s := newCompletionStream(size)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		s.close()
+	} else {
+		c.streams = append(c.streams, s)
+	}
+
+	return s
+}
+
+// push adds the result to all the open streams.
+func (c *completionStreams) push(r ProcessResult) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, s := range c.streams {
+		s.push(r)
+	}
+}
+
+// reopen lets new streams be added after close.
+func (c *completionStreams) reopen() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.closed = false
+}
+
+// close closes all the streams.
+func (c *completionStreams) close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.closed = true
+	for _, s := range c.streams {
+		s.close()
+	}
+	c.streams = nil
+}
+
+// CompletionStream returns a channel that receives the result of every
+// process that finishes after the call, in completion order. The channel is
+// buffered with the pool size and it is closed after the pool is closed and
+// the remaining results are delivered. Results are kept until they are
+// received, so the channel should be drained.
+func (w *workerPool) CompletionStream() <-chan ProcessResult {
+	return w.completions.add(w.size).out
+}
@@ -0,0 +1,44 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Completion stream should deliver the results in completion order
+func TestWorkerPool_CompletionStream(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(5))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	stream := wp.CompletionStream()
+
+	for i, d := range []int{250, 50, 200, 100, 150} {
+		wp.Register(newTestProcess("stream", i+1, time.Duration(d)*time.Millisecond, processFuncWithoutLog))
+	}
+	wp.Wait()
+	err = wp.Close()
+	a.NoError(err)
+
+	results := make([]ProcessResult, 0)
+	for r := range stream {
+		results = append(results, r)
+	}
+	a.Len(results, 5)
+	a.Equal([]PID{"p-2", "p-4", "p-5", "p-3", "p-1"}, []PID{
+		results[0].PID, results[1].PID, results[2].PID, results[3].PID, results[4].PID,
+	})
+	for i := 1; i < len(results); i++ {
+		a.False(results[i].FinishedAt.Before(results[i-1].FinishedAt))
+	}
+
+	_, ok := <-wp.CompletionStream()
+	a.False(ok)
+}
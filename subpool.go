@@ -0,0 +1,307 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+)
+
+// ErrSubPoolClosed is returned when a process is registered in a sub-pool
+// that has been closed.
+var ErrSubPoolClosed = errors.New("sub-pool is closed")
+
+type (
+	// subPool is a namespace of a pool that runs its processes on at most
+	// a number of the pool workers at a time and can be closed on its own.
+	subPool struct {
+		*namespacePool
+	}
+
+	// subPoolMonitor is the monitor of a sub-pool, whose status is Closed
+	// once the sub-pool has been closed.
+	subPoolMonitor struct {
+		*namespaceMonitor
+	}
+
+	// subPoolLimits tracks the sub-pools of a pool by their namespace.
+	subPoolLimits struct {
+		mutex   sync.Mutex
+		limits  map[string]*subPoolLimit
+		changed chan struct{}
+	}
+
+	// subPoolLimit is the state of a sub-pool.
+	subPoolLimit struct {
+		max     int
+		running int
+		closed  bool
+	}
+)
+
+// newSubPoolLimits makes a new instance of subPoolLimits.
+func newSubPoolLimits() *subPoolLimits {
+	return &subPoolLimits{limits: make(map[string]*subPoolLimit), changed: make(chan struct{})}
+}
+
+// SubPool returns a view of the pool for the sub-pool name, which runs its
+// processes on at most maxWorkers of the pool workers at a time. Zero or a
+// negative maxWorkers means that the sub-pool has no limit of its own. Like
+// a namespace, the processes of the sub-pool get name prepended to their PID
+// and name, so the pool monitor shows them as well, while the monitor of the
+// sub-pool only shows its own processes with their original PID. Closing
+// the sub-pool stops it from taking processes, without closing the pool. A
+// closed sub-pool is opened again by calling SubPool with its name.
+func (w *workerPool) SubPool(name string, maxWorkers int) Pool {
+	w.subPools.open(name, maxWorkers)
+
+	return &subPool{namespacePool: &namespacePool{workerPool: w, ns: name}}
+}
+
+// SubPool returns a sub-pool of the namespace.
+func (n *namespacePool) SubPool(name string, maxWorkers int) Pool {
+	return n.workerPool.SubPool(n.ns+namespaceSeparator+name, maxWorkers)
+}
+
+// Monitor returns a monitor that only shows the processes of the sub-pool.
+func (s *subPool) Monitor() Monitor {
+	return &subPoolMonitor{namespaceMonitor: &namespaceMonitor{workerPool: s.workerPool, ns: s.ns}}
+}
+
+// Close stops the sub-pool. The processes of the sub-pool that are waiting
+// in the queue, for their dependencies, or for their start jitter are
+// Cancelled with ErrPoolClosed, and Close waits for the running processes of
+// the sub-pool to finish. The pool and the processes of
+// the other namespaces are not affected. It returns an error if the sub-pool
+// is already closed.
+func (s *subPool) Close() error {
+	return s.CloseContext(context.Background())
+}
+
+// CloseContext closes the sub-pool like Close, but waits for its processes
+// only until ctx is done. The processes that are still not finished then are
+// killed, and CloseContext returns the context error with their ids.
+func (s *subPool) CloseContext(ctx context.Context) error {
+	if !s.subPools.close(s.ns) {
+		return s.opError("close the sub-pool", ErrSubPoolClosed)
+	}
+
+	for _, p := range s.queue.extract(s.contains) {
+		s.cancel(p)
+	}
+	s.notify()
+
+	if err := s.waitGroup(ctx, s.members); ctx.Err() == nil || !errors.Is(err, ctx.Err()) {
+		return nil
+	}
+
+	ids := make([]string, 0)
+	for _, stats := range s.members() {
+		if !stats.Status.IsTerminal() {
+			pid := stats.Process.PID()
+			_ = s.workerPool.KillWithReason(pid, fmt.Errorf("%w: %v", ErrPoolClosed, ctx.Err()))
+			ids = append(ids, strings.TrimPrefix(string(pid), s.ns+namespaceSeparator))
+		}
+	}
+
+	return fmt.Errorf("%w: processes have been killed: %s", ctx.Err(), strings.Join(ids, ", "))
+}
+
+// CloseGraceful stops the sub-pool from taking new processes and waits for
+// all of its processes to finish. It returns the errors of the processes
+// that were not finished when it was called.
+func (s *subPool) CloseGraceful() error {
+	pending := make([]PID, 0)
+	for _, stats := range s.members() {
+		if !stats.Status.IsTerminal() {
+			pending = append(pending, stats.Process.PID())
+		}
+	}
+
+	if !s.subPools.close(s.ns) {
+		return s.opError("close the sub-pool", ErrSubPoolClosed)
+	}
+	_ = s.waitGroup(context.Background(), s.members)
+
+	drained := make([]ProcessStats, 0, len(pending))
+	for _, pid := range pending {
+		drained = append(drained, s.processes.get(pid))
+	}
+
+	return processErrors(drained)
+}
+
+// contains reports whether the process belongs to the sub-pool or to one of
+// its namespaces.
+func (s *subPool) contains(p Process) bool {
+	ns, ok := processNamespace(p)
+	return ok && (ns == s.ns || strings.HasPrefix(ns, s.ns+namespaceSeparator))
+}
+
+// members returns the stats of the processes of the sub-pool.
+func (s *subPool) members() []ProcessStats {
+	list := make([]ProcessStats, 0)
+	s.processes.each(func(_ PID, stats ProcessStats) {
+		if stats.Process != nil && s.contains(stats.Process) {
+			list = append(list, stats)
+		}
+	})
+
+	return list
+}
+
+// PoolStatus returns Closed if the sub-pool has been closed, otherwise the
+// status of the pool.
+func (m *subPoolMonitor) PoolStatus() pool.Status {
+	if m.subPools.isClosed(m.ns) {
+		return pool.Closed
+	}
+
+	return m.workerPool.PoolStatus()
+}
+
+// processNamespace returns the namespace of the process, if it has been
+// registered through a namespace.
+func processNamespace(p Process) (string, bool) {
+	var ns string
+	found := findLayer(p, func(l Process) bool {
+		np, ok := l.(namespacedProcess)
+		if ok {
+			ns = np.ns
+		}
+		return ok
+	})
+
+	return ns, found
+}
+
+// open adds the sub-pool, or opens it again with a new limit.
+func (l *subPoolLimits) open(name string, max int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if limit, ok := l.limits[name]; ok {
+		limit.max = max
+		limit.closed = false
+		return
+	}
+	l.limits[name] = &subPoolLimit{max: max}
+}
+
+// close closes the sub-pool. It returns false if the sub-pool is already
+// closed.
+func (l *subPoolLimits) close(name string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	limit, ok := l.limits[name]
+	if !ok || limit.closed {
+		return false
+	}
+	limit.closed = true
+	close(l.changed)
+	l.changed = make(chan struct{})
+
+	return true
+}
+
+// closing returns a channel that is closed when a sub-pool is closed.
+func (l *subPoolLimits) closing() <-chan struct{} {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.changed
+}
+
+// isClosed reports whether the sub-pool is closed.
+func (l *subPoolLimits) isClosed(name string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	limit, ok := l.limits[name]
+	return ok && limit.closed
+}
+
+// lookup returns the sub-pools that the process belongs to, which are the
+// sub-pool of its namespace and the sub-pools of the parent namespaces. The
+// caller must hold the mutex.
+func (l *subPoolLimits) lookup(p Process) []*subPoolLimit {
+	if len(l.limits) == 0 {
+		return nil
+	}
+
+	ns, ok := processNamespace(p)
+	if !ok {
+		return nil
+	}
+
+	var list []*subPoolLimit
+	for {
+		if limit, ok := l.limits[ns]; ok {
+			list = append(list, limit)
+		}
+		i := strings.LastIndex(ns, namespaceSeparator)
+		if i < 0 {
+			return list
+		}
+		ns = ns[:i]
+	}
+}
+
+// check returns ErrSubPoolClosed if a process belongs to a closed sub-pool.
+func (l *subPoolLimits) check(args []Process) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for _, p := range args {
+		for _, limit := range l.lookup(p) {
+			if limit.closed {
+				return fmt.Errorf("%w: %s", ErrSubPoolClosed, p.PID())
+			}
+		}
+	}
+
+	return nil
+}
+
+// acquire takes a worker of the sub-pools of the process. It returns false
+// if one of them already runs its maximum number of processes.
+func (l *subPoolLimits) acquire(p Process) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	limits := l.lookup(p)
+	for _, limit := range limits {
+		if limit.max > 0 && limit.running >= limit.max {
+			return false
+		}
+	}
+	for _, limit := range limits {
+		limit.running++
+	}
+
+	return true
+}
+
+// release gives back the worker of the sub-pools of the process. It returns
+// true if the process belongs to a sub-pool.
+func (l *subPoolLimits) release(p Process) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	limits := l.lookup(p)
+	for _, limit := range limits {
+		if limit.running > 0 {
+			limit.running--
+		}
+	}
+
+	return len(limits) > 0
+}
@@ -0,0 +1,184 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// A sub-pool should run its processes on at most maxWorkers workers
+func TestWorkerPool_SubPool(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(4))
+	a.NoError(wp.Start(context.Background()))
+	sub := wp.SubPool("batch", 2)
+
+	var running, peak int64
+	task := func(ctx context.Context) error {
+		n := atomic.AddInt64(&running, 1)
+		for {
+			p := atomic.LoadInt64(&peak)
+			if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt64(&running, -1)
+		return nil
+	}
+	for _, pid := range []PID{"p-1", "p-2", "p-3", "p-4", "p-5", "p-6"} {
+		a.NoError(sub.Register(ProcessFunc(pid, "report", task)))
+	}
+	a.NoError(wp.Register(newTestProcess("other", 7, 20*time.Millisecond, processFuncWithoutLog)))
+	a.NoError(wp.Wait())
+
+	a.Equal(int64(2), atomic.LoadInt64(&peak))
+	a.Equal(process.Succeeded, processStats(t, sub.Monitor(), "p-1").Status)
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "batch/p-6").Status)
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-7").Status)
+	_, ok := sub.Monitor().ProcessStats("p-7")
+	a.False(ok)
+	a.NoError(wp.Close())
+}
+
+// Closing a sub-pool should cancel its waiting processes and keep the pool
+// running
+func TestWorkerPool_SubPoolClose(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Start(context.Background()))
+	sub := wp.SubPool("batch", 1)
+	a.NoError(sub.Register(
+		newTestProcess("report", 1, 50*time.Millisecond, processFuncWithoutLog),
+		newTestProcess("report", 2, 50*time.Millisecond, processFuncWithoutLog),
+	))
+	_, err := sub.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.NoError(err)
+
+	a.NoError(sub.Close())
+	a.ErrorIs(sub.Close(), ErrSubPoolClosed)
+	m := sub.Monitor()
+	a.Equal(pool.Closed, m.PoolStatus())
+	a.Equal(pool.Running, wp.Monitor().PoolStatus())
+	a.Equal(process.Succeeded, processStats(t, m, "p-1").Status)
+	a.Equal(process.Cancelled, processStats(t, m, "p-2").Status)
+	a.ErrorIs(processError(t, m, "p-2"), ErrPoolClosed)
+	a.ErrorIs(sub.Register(newTestProcess("report", 3, 0, processFuncWithoutLog)), ErrSubPoolClosed)
+
+	a.NoError(wp.Register(newTestProcess("other", 4, 0, processFuncWithoutLog)))
+	sub = wp.SubPool("batch", 1)
+	a.Equal(pool.Running, sub.Monitor().PoolStatus())
+	a.NoError(sub.Register(newTestProcess("report", 3, 0, processFuncWithoutLog)))
+	_ = wp.Wait()
+	a.Equal(process.Succeeded, processStats(t, sub.Monitor(), "p-3").Status)
+	a.NoError(wp.Close())
+}
+
+// CloseContext should kill the processes of the sub-pool once ctx is done
+func TestWorkerPool_SubPoolCloseContext(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Start(context.Background()))
+	sub := wp.Namespace("team").SubPool("batch", 1)
+	a.NoError(sub.Register(newTestProcess("report", 1, time.Minute, processFuncWithoutLog)))
+	_, err := sub.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = sub.CloseContext(ctx)
+	a.ErrorIs(err, context.DeadlineExceeded)
+	a.Contains(err.Error(), "p-1")
+	_ = wp.Wait()
+	a.Equal(process.Killed, processStats(t, wp.Monitor(), "team/batch/p-1").Status)
+	a.ErrorIs(processError(t, sub.Monitor(), "p-1"), ErrPoolClosed)
+	a.NoError(wp.Close())
+}
+
+// Close should cancel the processes of the sub-pool that wait for their
+// dependencies or their start jitter
+func TestWorkerPool_SubPoolCloseNotQueued(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Register(newTestProcess("blocker", 1, time.Minute, processFuncWithoutLog)))
+	sub := wp.SubPool("batch", 1)
+	a.NoError(sub.Register(WithDependsOn(newTestProcess("report", 2, 0, processFuncWithoutLog), "p-1")))
+
+	jittered := NewPool(WithWorkerCount(1), WithStartJitter(time.Hour))
+	a.NoError(jittered.Start(context.Background()))
+	jitteredSub := jittered.SubPool("batch", 1)
+	a.NoError(jitteredSub.Register(newTestProcess("report", 1, 0, processFuncWithoutLog)))
+
+	for _, sub := range []Pool{sub, jitteredSub} {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		a.NoError(sub.CloseContext(ctx))
+		cancel()
+		stats := sub.Monitor().CompletedProcesses()
+		if a.Len(stats, 1) {
+			a.Equal(process.Cancelled, stats[0].Status)
+			a.ErrorIs(processError(t, sub.Monitor(), stats[0].Process.PID()), ErrPoolClosed)
+		}
+	}
+
+	_ = wp.Kill("p-1")
+	a.NoError(wp.Close())
+	a.NoError(jittered.Close())
+}
+
+// A worker released by CloseContext should give back its sub-pool worker
+// once its process returns
+func TestWorkerPool_SubPoolRelease(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+	sub := wp.SubPool("batch", 1)
+
+	release := make(chan struct{})
+	a.NoError(sub.Register(newTestProcess("stuck", 1, 0, func(ctx context.Context, pid PID, duration time.Duration) error {
+		<-release
+		return nil
+	})))
+	_, err := sub.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	a.ErrorIs(wp.CloseContext(ctx), context.DeadlineExceeded)
+	close(release)
+
+	limits := wp.(*workerPool).subPools
+	a.Eventually(func() bool {
+		limits.mutex.Lock()
+		defer limits.mutex.Unlock()
+		return limits.limits["batch"].running == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+// CloseGraceful should wait for the processes of the sub-pool
+func TestWorkerPool_SubPoolCloseGraceful(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.NoError(wp.Start(context.Background()))
+	sub := wp.SubPool("batch", 1)
+	a.NoError(sub.Register(
+		newTestProcess("report", 1, 10*time.Millisecond, processFuncWithoutLog),
+		newTestProcess("report", 2, 0, processFuncWithError),
+	))
+
+	a.Error(sub.CloseGraceful())
+	a.Equal(process.Succeeded, processStats(t, sub.Monitor(), "p-1").Status)
+	a.Equal(process.Failed, processStats(t, sub.Monitor(), "p-2").Status)
+	a.Equal(pool.Closed, sub.Monitor().PoolStatus())
+	a.NoError(wp.Close())
+}
@@ -0,0 +1,584 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+// Package testutil provides fakes of the gowl Pool and Monitor interfaces for
+// the unit tests of the code that uses them. The fakes record the calls that
+// they receive, return the responses that the test has programmed, and never
+// start a goroutine.
+package testutil
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hamed-yousefi/gowl"
+	"github.com/hamed-yousefi/gowl/status/circuit"
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/hamed-yousefi/gowl/status/worker"
+)
+
+type (
+	// Call is a method call that a fake has received.
+	Call struct {
+		// Method is the name of the called method.
+		Method string
+
+		// Args are the arguments of the call.
+		Args []interface{}
+	}
+
+	// recorder records the calls of a fake.
+	recorder struct {
+		mutex sync.Mutex
+		calls []Call
+	}
+
+	// FakeMonitor is a gowl.Monitor that returns the state programmed by the
+	// Set methods. The processes are read from the stats set by
+	// SetProcessStats, and the workers from the statuses set by
+	// SetWorkerStatus or SetWorkerStats. It is safe for concurrent use.
+	FakeMonitor struct {
+		recorder
+
+		mutex       sync.RWMutex
+		poolStatus  pool.Status
+		processes   map[gowl.PID]gowl.ProcessStats
+		errors      map[gowl.PID]error
+		workers     []gowl.WorkerName
+		workerStats map[gowl.WorkerName]worker.Status
+		workerInfo  map[gowl.WorkerName]gowl.WorkerStats
+		histograms  map[gowl.PID]*gowl.DurationHistogram
+		groups      map[string]gowl.GroupStats
+		broadcasts  map[gowl.PID]gowl.GroupStats
+		circuits    map[string]circuit.Status
+		metrics     gowl.MetricsSnapshot
+		catalog     map[int]error
+		audit       gowl.DrainAudit
+		startRate   float64
+		changes     chan struct{}
+	}
+)
+
+var _ gowl.Monitor = (*FakeMonitor)(nil)
+
+// record adds a call to the recorder.
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls that the fake has received, in order.
+func (r *recorder) Calls() []Call {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]Call(nil), r.calls...)
+}
+
+// CallCount returns the number of calls of the method.
+func (r *recorder) CallCount(method string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	n := 0
+	for _, c := range r.calls {
+		if c.Method == method {
+			n++
+		}
+	}
+
+	return n
+}
+
+// NewFakeMonitor makes a new instance of FakeMonitor with a Created pool and
+// no process nor worker.
+func NewFakeMonitor() *FakeMonitor {
+	return &FakeMonitor{
+		poolStatus:  pool.Created,
+		processes:   make(map[gowl.PID]gowl.ProcessStats),
+		errors:      make(map[gowl.PID]error),
+		workerStats: make(map[gowl.WorkerName]worker.Status),
+		workerInfo:  make(map[gowl.WorkerName]gowl.WorkerStats),
+		histograms:  make(map[gowl.PID]*gowl.DurationHistogram),
+		groups:      make(map[string]gowl.GroupStats),
+		broadcasts:  make(map[gowl.PID]gowl.GroupStats),
+		circuits:    make(map[string]circuit.Status),
+		changes:     make(chan struct{}),
+	}
+}
+
+// changed wakes up the goroutines that wait for a change of the state. The
+// caller must hold the mutex.
+func (m *FakeMonitor) changed() {
+	close(m.changes)
+	m.changes = make(chan struct{})
+}
+
+// wait returns a channel that is closed on the next change of the state.
+func (m *FakeMonitor) wait() <-chan struct{} {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.changes
+}
+
+// SetPoolStatus sets the status that PoolStatus returns.
+func (m *FakeMonitor) SetPoolStatus(status pool.Status) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.poolStatus = status
+	m.changed()
+}
+
+// SetProcessStats sets the stats of the process pid.
+func (m *FakeMonitor) SetProcessStats(pid gowl.PID, stats gowl.ProcessStats) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.processes[pid] = stats
+	m.changed()
+}
+
+// SetError sets the error of the process pid. The process must have stats
+// for Error to return it.
+func (m *FakeMonitor) SetError(pid gowl.PID, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.errors[pid] = err
+	m.changed()
+}
+
+// SetWorkerStatus sets the status of the worker, and adds the worker to the
+// worker list if it is new.
+func (m *FakeMonitor) SetWorkerStatus(name gowl.WorkerName, status worker.Status) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.workerStats[name]; !ok {
+		m.workers = append(m.workers, name)
+	}
+	m.workerStats[name] = status
+	m.changed()
+}
+
+// SetWorkerStats sets the stats of the worker, including its status, and
+// adds the worker to the worker list if it is new.
+func (m *FakeMonitor) SetWorkerStats(stats gowl.WorkerStats) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.workerStats[stats.WorkerName]; !ok {
+		m.workers = append(m.workers, stats.WorkerName)
+	}
+	m.workerStats[stats.WorkerName] = stats.Status
+	m.workerInfo[stats.WorkerName] = stats
+	m.changed()
+}
+
+// SetHistogram sets the histogram of the process pid.
+func (m *FakeMonitor) SetHistogram(pid gowl.PID, h *gowl.DurationHistogram) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.histograms[pid] = h
+}
+
+// SetGroupStats sets the stats that GroupStats returns for the group name.
+// GroupStatus rolls them up.
+func (m *FakeMonitor) SetGroupStats(name string, stats gowl.GroupStats) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.groups[name] = stats
+}
+
+// SetBroadcastStats sets the stats that BroadcastStats returns for the
+// broadcast process pid.
+func (m *FakeMonitor) SetBroadcastStats(pid gowl.PID, stats gowl.GroupStats) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.broadcasts[pid] = stats
+}
+
+// SetCircuitStatus sets the circuit state of the process name.
+func (m *FakeMonitor) SetCircuitStatus(name string, status circuit.Status) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.circuits[name] = status
+}
+
+// SetMetrics sets the snapshot that Metrics returns.
+func (m *FakeMonitor) SetMetrics(metrics gowl.MetricsSnapshot) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.metrics = metrics
+}
+
+// SetErrorCatalog sets the catalog that ErrorCatalog returns.
+func (m *FakeMonitor) SetErrorCatalog(catalog map[int]error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.catalog = catalog
+}
+
+// SetDrainAudit sets the audit that DrainAudit returns.
+func (m *FakeMonitor) SetDrainAudit(audit gowl.DrainAudit) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.audit = audit
+}
+
+// SetStartRate sets the rate that StartRate returns.
+func (m *FakeMonitor) SetStartRate(rate float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.startRate = rate
+}
+
+// PoolStatus returns the status set by SetPoolStatus.
+func (m *FakeMonitor) PoolStatus() pool.Status {
+	m.record("PoolStatus")
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.poolStatus
+}
+
+// Error returns the error set by SetError, or false if the process has no
+// stats.
+func (m *FakeMonitor) Error(pid gowl.PID) (error, bool) { //nolint:stylecheck
+	m.record("Error", pid)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if _, ok := m.processes[pid]; !ok {
+		return nil, false
+	}
+
+	return m.errors[pid], true
+}
+
+// WorkerList returns the workers in the order of their first
+// SetWorkerStatus.
+func (m *FakeMonitor) WorkerList() []gowl.WorkerName {
+	m.record("WorkerList")
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append([]gowl.WorkerName(nil), m.workers...)
+}
+
+// WorkerStatus returns the status set by SetWorkerStatus.
+func (m *FakeMonitor) WorkerStatus(name gowl.WorkerName) worker.Status {
+	m.record("WorkerStatus", name)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.workerStats[name]
+}
+
+// WorkerStats returns the stats set by SetWorkerStats. A worker that only
+// has a status set by SetWorkerStatus has no other stats.
+func (m *FakeMonitor) WorkerStats(name gowl.WorkerName) (gowl.WorkerStats, bool) {
+	m.record("WorkerStats", name)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	status, ok := m.workerStats[name]
+	if !ok {
+		return gowl.WorkerStats{}, false
+	}
+	stats := m.workerInfo[name]
+	stats.WorkerName = name
+	stats.Status = status
+
+	return stats, true
+}
+
+// Histogram returns the histogram set by SetHistogram.
+func (m *FakeMonitor) Histogram(pid gowl.PID) (*gowl.DurationHistogram, bool) {
+	m.record("Histogram", pid)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	h, ok := m.histograms[pid]
+	return h, ok
+}
+
+// ProcessStats returns the stats set by SetProcessStats.
+func (m *FakeMonitor) ProcessStats(pid gowl.PID) (gowl.ProcessStats, bool) {
+	m.record("ProcessStats", pid)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	stats, ok := m.processes[pid]
+	return stats, ok
+}
+
+// Delta returns all the processes, since the fake does not track when they
+// have changed.
+func (m *FakeMonitor) Delta(since time.Time) gowl.MonitorDelta {
+	m.record("Delta", since)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return gowl.MonitorDelta{Since: since, PoolStatus: m.poolStatus, Processes: m.list(nil)}
+}
+
+// CompletedProcesses returns the processes in a final state.
+func (m *FakeMonitor) CompletedProcesses() []gowl.ProcessStats {
+	m.record("CompletedProcesses")
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.list(func(stats gowl.ProcessStats) bool {
+		return stats.Status.IsTerminal()
+	})
+}
+
+// Purge removes the processes that finished before olderThan.
+func (m *FakeMonitor) Purge(olderThan time.Time) int {
+	m.record("Purge", olderThan)
+
+	return m.remove(func(stats gowl.ProcessStats) bool {
+		return stats.Status.IsTerminal() && stats.FinishedAt.Before(olderThan)
+	})
+}
+
+// ResetStats removes the processes in a final state.
+func (m *FakeMonitor) ResetStats() error {
+	m.record("ResetStats")
+	m.remove(func(stats gowl.ProcessStats) bool {
+		return stats.Status.IsTerminal()
+	})
+
+	return nil
+}
+
+// Metrics returns the snapshot set by SetMetrics.
+func (m *FakeMonitor) Metrics() gowl.MetricsSnapshot {
+	m.record("Metrics")
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.metrics
+}
+
+// TotalErrors returns the number of Failed processes.
+func (m *FakeMonitor) TotalErrors() int {
+	m.record("TotalErrors")
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return len(m.list(func(stats gowl.ProcessStats) bool {
+		return stats.Status == process.Failed
+	}))
+}
+
+// ActiveWorkerCount returns the number of Busy workers.
+func (m *FakeMonitor) ActiveWorkerCount() int {
+	m.record("ActiveWorkerCount")
+	active, _ := m.workerCounts()
+
+	return active
+}
+
+// IdleWorkerCount returns the number of Waiting workers.
+func (m *FakeMonitor) IdleWorkerCount() int {
+	m.record("IdleWorkerCount")
+	_, idle := m.workerCounts()
+
+	return idle
+}
+
+// StartRate returns the rate set by SetStartRate.
+func (m *FakeMonitor) StartRate() float64 {
+	m.record("StartRate")
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.startRate
+}
+
+// WithFilter returns a snapshot of the monitor with only the processes whose
+// name matches pattern. The snapshot does not follow the later changes of
+// the monitor.
+func (m *FakeMonitor) WithFilter(pattern *regexp.Regexp) gowl.Monitor {
+	m.record("WithFilter", pattern)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	filtered := NewFakeMonitor()
+	filtered.poolStatus = m.poolStatus
+	filtered.workers = append(filtered.workers, m.workers...)
+	for name, status := range m.workerStats {
+		filtered.workerStats[name] = status
+	}
+	for name, stats := range m.workerInfo {
+		filtered.workerInfo[name] = stats
+	}
+	for pid, stats := range m.processes {
+		if stats.Process == nil || !pattern.MatchString(stats.Process.Name()) {
+			continue
+		}
+		filtered.processes[pid] = stats
+		if err, ok := m.errors[pid]; ok {
+			filtered.errors[pid] = err
+		}
+		if h, ok := m.histograms[pid]; ok {
+			filtered.histograms[pid] = h
+		}
+	}
+	filtered.metrics = m.metrics
+	filtered.startRate = m.startRate
+
+	return filtered
+}
+
+// ErrorCatalog returns the catalog set by SetErrorCatalog.
+func (m *FakeMonitor) ErrorCatalog() map[int]error {
+	m.record("ErrorCatalog")
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.catalog
+}
+
+// DrainAudit returns the audit set by SetDrainAudit.
+func (m *FakeMonitor) DrainAudit() gowl.DrainAudit {
+	m.record("DrainAudit")
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.audit
+}
+
+// GroupStats returns the stats set by SetGroupStats.
+func (m *FakeMonitor) GroupStats(name string) gowl.GroupStats {
+	m.record("GroupStats", name)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.groups[name]
+}
+
+// GroupStatus rolls up the stats set by SetGroupStats, or returns false if
+// the group has no process.
+func (m *FakeMonitor) GroupStatus(name string) (process.Status, bool) {
+	m.record("GroupStatus", name)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	stats := m.groups[name]
+	if stats.Total == 0 {
+		return process.Waiting, false
+	}
+
+	return stats.Status(), true
+}
+
+// CircuitStatus returns the state set by SetCircuitStatus, or Closed.
+func (m *FakeMonitor) CircuitStatus(name string) circuit.Status {
+	m.record("CircuitStatus", name)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.circuits[name]
+}
+
+// BroadcastStats returns the stats set by SetBroadcastStats.
+func (m *FakeMonitor) BroadcastStats(pid gowl.PID) gowl.GroupStats {
+	m.record("BroadcastStats", pid)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.broadcasts[pid]
+}
+
+// list returns the stats of the processes that match, or of all of them if
+// match is nil, ordered by process id. The caller must hold the mutex.
+func (m *FakeMonitor) list(match func(gowl.ProcessStats) bool) []gowl.ProcessStats {
+	pids := make([]gowl.PID, 0, len(m.processes))
+	for pid, stats := range m.processes {
+		if match == nil || match(stats) {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Slice(pids, func(i, j int) bool {
+		return pids[i] < pids[j]
+	})
+
+	list := make([]gowl.ProcessStats, 0, len(pids))
+	for _, pid := range pids {
+		list = append(list, m.processes[pid])
+	}
+
+	return list
+}
+
+// remove removes the processes that match and returns their number.
+func (m *FakeMonitor) remove(match func(gowl.ProcessStats) bool) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	n := 0
+	for pid, stats := range m.processes {
+		if match(stats) {
+			delete(m.processes, pid)
+			delete(m.errors, pid)
+			n++
+		}
+	}
+	if n > 0 {
+		m.changed()
+	}
+
+	return n
+}
+
+// workerCounts returns the number of busy and idle workers.
+func (m *FakeMonitor) workerCounts() (active, idle int) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, status := range m.workerStats {
+		if status == worker.Busy {
+			active++
+		} else {
+			idle++
+		}
+	}
+
+	return active, idle
+}
+
+// stats returns the stats of the process without recording a call.
+func (m *FakeMonitor) stats(pid gowl.PID) (gowl.ProcessStats, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	stats, ok := m.processes[pid]
+	return stats, ok
+}
+
+// processError returns the error of the process without recording a call.
+func (m *FakeMonitor) processError(pid gowl.PID) error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.errors[pid]
+}
@@ -0,0 +1,107 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package testutil
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl"
+	"github.com/hamed-yousefi/gowl/status/circuit"
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/hamed-yousefi/gowl/status/worker"
+)
+
+// testProcess is a process that does nothing.
+type testProcess struct {
+	name string
+	pid  gowl.PID
+}
+
+func (p testProcess) Start(ctx context.Context) error {
+	return nil
+}
+
+func (p testProcess) Name() string {
+	return p.name
+}
+
+func (p testProcess) PID() gowl.PID {
+	return p.pid
+}
+
+// The fake monitor should return the programmed responses and record the calls
+func TestFakeMonitor(t *testing.T) {
+	a := assert.New(t)
+	m := NewFakeMonitor()
+	errFailed := errors.New("failed")
+
+	a.Equal(pool.Created, m.PoolStatus())
+	m.SetPoolStatus(pool.Running)
+	a.Equal(pool.Running, m.PoolStatus())
+
+	_, ok := m.ProcessStats("p-1")
+	a.False(ok)
+	m.SetProcessStats("p-1", gowl.ProcessStats{Process: testProcess{"db-sync", "p-1"}, Status: process.Failed,
+		FinishedAt: time.Now().Add(-time.Hour)})
+	m.SetProcessStats("p-2", gowl.ProcessStats{Process: testProcess{"mail", "p-2"}, Status: process.Running})
+	m.SetError("p-1", errFailed)
+	stats, ok := m.ProcessStats("p-1")
+	a.True(ok)
+	a.Equal(process.Failed, stats.Status)
+	err, ok := m.Error("p-1")
+	a.True(ok)
+	a.Equal(errFailed, err)
+	a.Equal(1, m.TotalErrors())
+	a.Len(m.CompletedProcesses(), 1)
+	a.Len(m.Delta(time.Now()).Processes, 2)
+
+	m.SetWorkerStatus("W0", worker.Busy)
+	m.SetWorkerStatus("W1", worker.Waiting)
+	a.Equal([]gowl.WorkerName{"W0", "W1"}, m.WorkerList())
+	a.Equal(worker.Busy, m.WorkerStatus("W0"))
+	a.Equal(1, m.ActiveWorkerCount())
+	a.Equal(1, m.IdleWorkerCount())
+	m.SetWorkerStats(gowl.WorkerStats{WorkerName: "W2", Status: worker.Busy, CurrentPID: "p-2", TotalProcessed: 3})
+	info, ok := m.WorkerStats("W2")
+	a.True(ok)
+	a.Equal(gowl.PID("p-2"), info.CurrentPID)
+	a.Equal(int64(3), info.TotalProcessed)
+	info, ok = m.WorkerStats("W1")
+	a.True(ok)
+	a.Equal(gowl.WorkerStats{WorkerName: "W1", Status: worker.Waiting}, info)
+	_, ok = m.WorkerStats("W9")
+	a.False(ok)
+
+	m.SetGroupStats("g", gowl.GroupStats{Total: 2, Running: 1, Succeeded: 1})
+	status, ok := m.GroupStatus("g")
+	a.True(ok)
+	a.Equal(process.Running, status)
+	_, ok = m.GroupStatus("other")
+	a.False(ok)
+	m.SetCircuitStatus("mail", circuit.Open)
+	a.Equal(circuit.Open, m.CircuitStatus("mail"))
+	a.Equal(circuit.Closed, m.CircuitStatus("db-sync"))
+
+	filtered := m.WithFilter(regexp.MustCompile("^db-"))
+	_, ok = filtered.ProcessStats("p-1")
+	a.True(ok)
+	_, ok = filtered.ProcessStats("p-2")
+	a.False(ok)
+
+	a.Equal(1, m.Purge(time.Now()))
+	_, ok = m.ProcessStats("p-1")
+	a.False(ok)
+
+	a.Equal(2, m.CallCount("PoolStatus"))
+	calls := m.Calls()
+	a.Equal(Call{Method: "ProcessStats", Args: []interface{}{gowl.PID("p-1")}}, calls[len(calls)-1])
+}
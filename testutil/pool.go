@@ -0,0 +1,902 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hamed-yousefi/gowl"
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+const (
+	// streamBuffer is the size of the channels of CompletionStream.
+	streamBuffer = 64
+)
+
+type (
+	// FakePool is a gowl.Pool that runs nothing. It records the registered
+	// processes as Waiting in its FakeMonitor, and the test moves them
+	// forward with Run and Complete, which trigger the completion callbacks
+	// of the pool: the NotifyOn, SubmitWithResult, and CompletionStream
+	// channels, the events of Subscribe, and the blocked Wait calls. The
+	// methods that return an error return the one set by SetMethodError,
+	// and the others only record their call. It is safe for concurrent use.
+	FakePool struct {
+		recorder
+
+		mutex       sync.Mutex
+		monitor     *FakeMonitor
+		registered  []gowl.Process
+		errs        map[string]error
+		stats       gowl.PoolStats
+		notify      map[gowl.PID][]chan<- gowl.ProcessResult
+		results     map[gowl.PID]chan interface{}
+		streams     []chan gowl.ProcessResult
+		subscribers []chan<- gowl.Event
+		namespaces  map[string]*FakePool
+	}
+
+	// fakeHandle is the WorkerHandle of a FakePool.
+	fakeHandle struct {
+		pool *FakePool
+		name gowl.WorkerName
+	}
+
+	// barrierProcess is the process that FakePool registers for
+	// RegisterBarrier.
+	barrierProcess struct {
+		pid gowl.PID
+	}
+)
+
+var _ gowl.Pool = (*FakePool)(nil)
+
+// NewFakePool makes a new instance of FakePool that keeps its state in
+// monitor, or in a new FakeMonitor if monitor is nil.
+func NewFakePool(monitor *FakeMonitor) *FakePool {
+	if monitor == nil {
+		monitor = NewFakeMonitor()
+	}
+
+	return &FakePool{
+		monitor:    monitor,
+		errs:       make(map[string]error),
+		notify:     make(map[gowl.PID][]chan<- gowl.ProcessResult),
+		results:    make(map[gowl.PID]chan interface{}),
+		namespaces: make(map[string]*FakePool),
+	}
+}
+
+// SetMethodError makes the method return err, or nothing if err is nil. A
+// method that fails does not change the state of the pool.
+func (f *FakePool) SetMethodError(method string, err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.errs[method] = err
+}
+
+// SetStats sets the stats that Stats returns.
+func (f *FakePool) SetStats(stats gowl.PoolStats) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.stats = stats
+}
+
+// Registered returns the processes that have been registered, in order.
+func (f *FakePool) Registered() []gowl.Process {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]gowl.Process(nil), f.registered...)
+}
+
+// Run marks the Waiting process pid as Running on the worker, like a worker
+// that picks it up. It returns gowl.ErrProcessNotFound if the process is not
+// registered, and an error if it is not Waiting.
+func (f *FakePool) Run(pid gowl.PID, name gowl.WorkerName) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	stats, ok := f.monitor.stats(pid)
+	if !ok {
+		return fmt.Errorf("%w: %s", gowl.ErrProcessNotFound, pid)
+	}
+	if stats.Status != process.Waiting {
+		return errors.New("unable to run the process, status: " + stats.Status.String())
+	}
+
+	stats.Status = process.Running
+	stats.WorkerName = name
+	stats.StartedAt = time.Now()
+	stats.Attempt++
+	f.monitor.SetProcessStats(pid, stats)
+	f.emit(gowl.ProcessStarted, pid, name)
+
+	return nil
+}
+
+// Complete finishes the process of the result with its status, error, and
+// output, and triggers the completion callbacks. A zero FinishedAt is set to
+// the current time. It returns gowl.ErrProcessNotFound if the process is not
+// registered, and an error if the status is not final or the process has
+// already finished.
+func (f *FakePool) Complete(result gowl.ProcessResult) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.complete(result)
+}
+
+// complete finishes the process of the result. The caller must hold the
+// mutex.
+func (f *FakePool) complete(result gowl.ProcessResult) error {
+	stats, ok := f.monitor.stats(result.PID)
+	if !ok {
+		return fmt.Errorf("%w: %s", gowl.ErrProcessNotFound, result.PID)
+	}
+	if !result.Status.IsTerminal() {
+		return errors.New("unable to complete the process, status is not final: " + result.Status.String())
+	}
+	if stats.Status.IsTerminal() {
+		return errors.New("unable to complete the process, process has finished, status: " + stats.Status.String())
+	}
+
+	if result.StartedAt.IsZero() {
+		result.StartedAt = stats.StartedAt
+	}
+	if result.FinishedAt.IsZero() {
+		result.FinishedAt = time.Now()
+	}
+	stats.Status = result.Status
+	stats.Output = result.Output
+	stats.StartedAt = result.StartedAt
+	stats.FinishedAt = result.FinishedAt
+	f.monitor.SetError(result.PID, result.Err)
+	f.monitor.SetProcessStats(result.PID, stats)
+
+	// The fake never blocks, so the channels that are full miss the result.
+	for _, ch := range f.notify[result.PID] {
+		select {
+		case ch <- result:
+		default:
+		}
+		close(ch)
+	}
+	delete(f.notify, result.PID)
+	if ch, ok := f.results[result.PID]; ok {
+		if result.Status == process.Succeeded {
+			ch <- result.Output
+		}
+		close(ch)
+		delete(f.results, result.PID)
+	}
+	for _, ch := range f.streams {
+		select {
+		case ch <- result:
+		default:
+		}
+	}
+
+	switch result.Status {
+	case process.Succeeded:
+		f.emit(gowl.ProcessSucceeded, result.PID, stats.WorkerName)
+	case process.Failed:
+		f.emit(gowl.ProcessFailed, result.PID, stats.WorkerName)
+	case process.Killed:
+		f.emit(gowl.ProcessKilled, result.PID, stats.WorkerName)
+	case process.Cancelled:
+		f.emit(gowl.ProcessCancelled, result.PID, stats.WorkerName)
+	}
+
+	return nil
+}
+
+// emit sends the event to the subscribers without blocking. The caller must
+// hold the mutex.
+func (f *FakePool) emit(t gowl.EventType, pid gowl.PID, name gowl.WorkerName) {
+	e := gowl.Event{Type: t, PID: pid, WorkerName: name, Timestamp: time.Now()}
+	for _, ch := range f.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// fail returns the error set by SetMethodError for the method. The caller
+// must hold the mutex.
+func (f *FakePool) fail(method string) error {
+	return f.errs[method]
+}
+
+// call records the call of a method that only returns its programmed
+// error.
+func (f *FakePool) call(method string, args ...interface{}) error {
+	f.record(method, args...)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.fail(method)
+}
+
+// register records the call of the method and adds the processes.
+func (f *FakePool) register(method string, name gowl.WorkerName, args []gowl.Process) error {
+	f.record(method, processArgs(args)...)
+
+	return f.add(method, name, args)
+}
+
+// add adds the processes as Waiting on the worker, or on no worker if name
+// is empty, unless the method fails.
+func (f *FakePool) add(method string, name gowl.WorkerName, args []gowl.Process) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.fail(method); err != nil {
+		return err
+	}
+	for _, p := range args {
+		f.registered = append(f.registered, p)
+		f.monitor.SetError(p.PID(), nil)
+		f.monitor.SetProcessStats(p.PID(), gowl.ProcessStats{Process: p, WorkerName: name, Status: process.Waiting,
+			EnqueuedAt: time.Now()})
+	}
+
+	return nil
+}
+
+// processArgs converts the processes to call arguments.
+func processArgs(args []gowl.Process) []interface{} {
+	list := make([]interface{}, 0, len(args))
+	for _, p := range args {
+		list = append(list, p)
+	}
+
+	return list
+}
+
+// setStatus sets the pool status and sends its event. The caller must hold
+// the mutex.
+func (f *FakePool) setStatus(status pool.Status, t gowl.EventType) {
+	f.monitor.SetPoolStatus(status)
+	f.emit(t, "", "")
+}
+
+// Start sets the pool status to Running. The pool does not watch ctx.
+func (f *FakePool) Start(ctx context.Context) error {
+	f.record("Start", ctx)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.fail("Start"); err != nil {
+		return err
+	}
+	f.setStatus(pool.Running, gowl.PoolStarted)
+
+	return nil
+}
+
+// Register adds the processes as Waiting.
+func (f *FakePool) Register(args ...gowl.Process) error {
+	return f.register("Register", "", args)
+}
+
+// Close sets the pool status to Closed, cancels the unfinished processes,
+// and closes the CompletionStream channels.
+func (f *FakePool) Close() error {
+	return f.close("Close")
+}
+
+// CloseContext closes the pool like Close.
+func (f *FakePool) CloseContext(ctx context.Context) error {
+	return f.close("CloseContext")
+}
+
+// CloseGraceful closes the pool like Close.
+func (f *FakePool) CloseGraceful() error {
+	return f.close("CloseGraceful")
+}
+
+// close closes the pool for the method.
+func (f *FakePool) close(method string) error {
+	f.record(method)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.fail(method); err != nil {
+		return err
+	}
+	for _, p := range f.registered {
+		_ = f.complete(gowl.ProcessResult{PID: p.PID(), Status: process.Cancelled})
+	}
+	for _, ch := range f.streams {
+		close(ch)
+	}
+	f.streams = nil
+	f.setStatus(pool.Closed, gowl.PoolClosed)
+
+	return nil
+}
+
+// Kill completes the unfinished process as Killed. It returns
+// gowl.ErrProcessNotFound if the process is not registered.
+func (f *FakePool) Kill(pid gowl.PID) error {
+	f.record("Kill", pid)
+	return f.kill(pid, nil)
+}
+
+// KillWithReason completes the unfinished process as Killed with reason as
+// its error.
+func (f *FakePool) KillWithReason(pid gowl.PID, reason error) error {
+	f.record("KillWithReason", pid, reason)
+	return f.kill(pid, reason)
+}
+
+// kill completes the process as Killed if it is not finished.
+func (f *FakePool) kill(pid gowl.PID, reason error) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	stats, ok := f.monitor.stats(pid)
+	if !ok {
+		return fmt.Errorf("%w: %s", gowl.ErrProcessNotFound, pid)
+	}
+	if stats.Status.IsTerminal() {
+		return nil
+	}
+
+	return f.complete(gowl.ProcessResult{PID: pid, Status: process.Killed, Err: reason})
+}
+
+// Freeze records the call.
+func (f *FakePool) Freeze() error {
+	return f.call("Freeze")
+}
+
+// Thaw records the call.
+func (f *FakePool) Thaw() error {
+	return f.call("Thaw")
+}
+
+// RegisterSequential adds the processes as Waiting.
+func (f *FakePool) RegisterSequential(args ...gowl.Process) error {
+	return f.register("RegisterSequential", "", args)
+}
+
+// Pause sets the pool status to Paused.
+func (f *FakePool) Pause() error {
+	f.record("Pause")
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.fail("Pause"); err != nil {
+		return err
+	}
+	f.setStatus(pool.Paused, gowl.PoolPaused)
+
+	return nil
+}
+
+// Resume sets the pool status to Running.
+func (f *FakePool) Resume() error {
+	f.record("Resume")
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.fail("Resume"); err != nil {
+		return err
+	}
+	f.setStatus(pool.Running, gowl.PoolResumed)
+
+	return nil
+}
+
+// RegisterBatch adds the processes as Waiting.
+func (f *FakePool) RegisterBatch(args ...gowl.Process) error {
+	return f.register("RegisterBatch", "", args)
+}
+
+// BroadcastRegister adds the process as Waiting. The fake has no worker to
+// copy the process to.
+func (f *FakePool) BroadcastRegister(p gowl.Process) error {
+	return f.register("BroadcastRegister", "", []gowl.Process{p})
+}
+
+// KillWait kills the process like Kill and returns right away. It returns
+// gowl.ErrProcessNotFound if the process is not registered.
+func (f *FakePool) KillWait(pid gowl.PID, timeout time.Duration) error {
+	f.record("KillWait", pid, timeout)
+	if _, ok := f.monitor.stats(pid); !ok {
+		return fmt.Errorf("%w: %s", gowl.ErrProcessNotFound, pid)
+	}
+	f.kill(pid, nil)
+
+	return nil
+}
+
+// Monitor returns the FakeMonitor of the pool.
+func (f *FakePool) Monitor() gowl.Monitor {
+	f.record("Monitor")
+
+	return f.monitor
+}
+
+// Stats returns the stats set by SetStats.
+func (f *FakePool) Stats() gowl.PoolStats {
+	f.record("Stats")
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.stats
+}
+
+// Resize records the call.
+func (f *FakePool) Resize(n int) error {
+	return f.call("Resize", n)
+}
+
+// Scale records the call.
+func (f *FakePool) Scale(delta int) error {
+	return f.call("Scale", delta)
+}
+
+// EnsureWorkers records the call.
+func (f *FakePool) EnsureWorkers(n int) error {
+	return f.call("EnsureWorkers", n)
+}
+
+// Throttle records the call.
+func (f *FakePool) Throttle(factor float64) error {
+	return f.call("Throttle", factor)
+}
+
+// Utilization returns the fraction of the Busy workers of the monitor.
+func (f *FakePool) Utilization() float64 {
+	f.record("Utilization")
+	active, idle := f.monitor.workerCounts()
+	if active+idle == 0 {
+		return 0
+	}
+
+	return float64(active) / float64(active+idle)
+}
+
+// Capacity returns the number of Waiting workers and the number of workers
+// of the monitor.
+func (f *FakePool) Capacity() (current, max int) {
+	f.record("Capacity")
+	active, idle := f.monitor.workerCounts()
+
+	return idle, active + idle
+}
+
+// Lock records the call.
+func (f *FakePool) Lock() error {
+	return f.call("Lock")
+}
+
+// Reset sets the pool status to Created.
+func (f *FakePool) Reset() error {
+	f.record("Reset")
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.fail("Reset"); err != nil {
+		return err
+	}
+	f.monitor.SetPoolStatus(pool.Created)
+
+	return nil
+}
+
+// KillGroup kills the unfinished processes whose stats have the group
+// name. It returns gowl.ErrGroupNotFound if the group has no process.
+func (f *FakePool) KillGroup(name string) error {
+	f.record("KillGroup", name)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.fail("KillGroup"); err != nil {
+		return err
+	}
+	pids := f.group(name)
+	if len(pids) == 0 {
+		return fmt.Errorf("%w: %s", gowl.ErrGroupNotFound, name)
+	}
+	for _, pid := range pids {
+		_ = f.complete(gowl.ProcessResult{PID: pid, Status: process.Killed})
+	}
+
+	return nil
+}
+
+// WaitGroup blocks until the processes whose stats have the group name are
+// finished, and returns their errors, or the error of ctx.
+func (f *FakePool) WaitGroup(ctx context.Context, name string) error {
+	f.record("WaitGroup", ctx, name)
+	f.mutex.Lock()
+	err := f.fail("WaitGroup")
+	f.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return f.wait(ctx, func() []gowl.PID {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+
+		return f.group(name)
+	})
+}
+
+// group returns the registered processes whose stats have the group name.
+// The caller must hold the mutex.
+func (f *FakePool) group(name string) []gowl.PID {
+	var pids []gowl.PID
+	for _, pid := range f.pids() {
+		if stats, ok := f.monitor.stats(pid); ok && stats.Group == name {
+			pids = append(pids, pid)
+		}
+	}
+
+	return pids
+}
+
+// pids returns the ids of the registered processes. The caller must hold
+// the mutex.
+func (f *FakePool) pids() []gowl.PID {
+	pids := make([]gowl.PID, 0, len(f.registered))
+	for _, p := range f.registered {
+		pids = append(pids, p.PID())
+	}
+
+	return pids
+}
+
+// wait blocks until the processes returned by members are finished, and
+// returns their errors in order, or the error of ctx.
+func (f *FakePool) wait(ctx context.Context, members func() []gowl.PID) error {
+	for {
+		changed := f.monitor.wait()
+		var errs []error
+		finished := true
+		for _, pid := range members() {
+			stats, _ := f.monitor.stats(pid)
+			finished = finished && stats.Status.IsTerminal()
+			if err := f.monitor.processError(pid); stats.Status.IsError() && err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if finished {
+			if len(errs) == 0 {
+				return nil
+			}
+			return &gowl.MultiError{Errors: errs}
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// SLOReport returns a zero report.
+func (f *FakePool) SLOReport(window time.Duration) gowl.SLOReport {
+	f.record("SLOReport", window)
+
+	return gowl.SLOReport{}
+}
+
+// Subscribe makes the pool send its events to ch without blocking.
+func (f *FakePool) Subscribe(ch chan<- gowl.Event) {
+	f.record("Subscribe", ch)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.subscribers = append(f.subscribers, ch)
+}
+
+// Unsubscribe stops sending the events to ch.
+func (f *FakePool) Unsubscribe(ch chan<- gowl.Event) {
+	f.record("Unsubscribe", ch)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	kept := make([]chan<- gowl.Event, 0, len(f.subscribers))
+	for _, c := range f.subscribers {
+		if c != ch {
+			kept = append(kept, c)
+		}
+	}
+	f.subscribers = kept
+}
+
+// Version returns 1.
+func (f *FakePool) Version() int64 {
+	f.record("Version")
+
+	return 1
+}
+
+// SubmitWithResult adds the process as Waiting and returns a channel that
+// receives the output that Complete gives to the process if it succeeds.
+func (f *FakePool) SubmitWithResult(p gowl.Process) (<-chan interface{}, error) {
+	if err := f.register("SubmitWithResult", "", []gowl.Process{p}); err != nil {
+		return nil, err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	ch := make(chan interface{}, 1)
+	f.results[p.PID()] = ch
+
+	return ch, nil
+}
+
+// RegisterBarrier adds a Waiting process with the barrier id. It does not
+// wait for the group.
+func (f *FakePool) RegisterBarrier(barrierPID gowl.PID, group []gowl.PID) error {
+	f.record("RegisterBarrier", barrierPID, group)
+
+	return f.add("RegisterBarrier", "", []gowl.Process{barrierProcess{pid: barrierPID}})
+}
+
+// AwaitIdle blocks until all the registered processes are finished, or ctx
+// is done.
+func (f *FakePool) AwaitIdle(ctx context.Context) error {
+	f.record("AwaitIdle", ctx)
+	if err := f.wait(ctx, f.members); err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+// Wait blocks until all the registered processes are finished and returns
+// the errors of the failed ones.
+func (f *FakePool) Wait() error {
+	f.record("Wait")
+
+	return f.wait(context.Background(), f.members)
+}
+
+// WaitContext blocks like Wait, or until ctx is done.
+func (f *FakePool) WaitContext(ctx context.Context) error {
+	f.record("WaitContext", ctx)
+
+	return f.wait(ctx, f.members)
+}
+
+// members returns the ids of the registered processes.
+func (f *FakePool) members() []gowl.PID {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.pids()
+}
+
+// NotifyOn sends the result that Complete gives to the process to ch, and
+// then closes ch. The send does not block, so ch should be buffered. It
+// returns gowl.ErrProcessNotFound if the process is not registered.
+func (f *FakePool) NotifyOn(pid gowl.PID, ch chan<- gowl.ProcessResult) error {
+	f.record("NotifyOn", pid, ch)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.fail("NotifyOn"); err != nil {
+		return err
+	}
+	stats, ok := f.monitor.stats(pid)
+	if !ok {
+		return fmt.Errorf("%w: %s", gowl.ErrProcessNotFound, pid)
+	}
+	if stats.Status.IsTerminal() {
+		err := f.monitor.processError(pid)
+		select {
+		case ch <- gowl.ProcessResult{PID: pid, Status: stats.Status, Output: stats.Output, Err: err,
+			StartedAt: stats.StartedAt, FinishedAt: stats.FinishedAt}:
+		default:
+		}
+		close(ch)
+		return nil
+	}
+	f.notify[pid] = append(f.notify[pid], ch)
+
+	return nil
+}
+
+// Reorder sorts the Waiting processes of Registered with less, and returns
+// the number of processes whose position changed.
+func (f *FakePool) Reorder(less func(a, b gowl.Process) bool) (int, error) {
+	f.record("Reorder", less)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.fail("Reorder"); err != nil {
+		return 0, err
+	}
+	var positions []int
+	var waiting []gowl.Process
+	for i, p := range f.registered {
+		if stats, _ := f.monitor.stats(p.PID()); stats.Status == process.Waiting {
+			positions = append(positions, i)
+			waiting = append(waiting, p)
+		}
+	}
+	sorted := append([]gowl.Process(nil), waiting...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+
+	moved := 0
+	for i, p := range sorted {
+		if p.PID() != waiting[i].PID() {
+			moved++
+		}
+		f.registered[positions[i]] = p
+	}
+
+	return moved, nil
+}
+
+// CompletionStream returns a channel that receives the results given to
+// Complete after the call. It is buffered and the results that do not fit
+// are dropped. It is closed when the pool is closed.
+func (f *FakePool) CompletionStream() <-chan gowl.ProcessResult {
+	f.record("CompletionStream")
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	ch := make(chan gowl.ProcessResult, streamBuffer)
+	f.streams = append(f.streams, ch)
+
+	return ch
+}
+
+// RegisterSync adds the process as Waiting and blocks until it leaves the
+// Waiting state, which is when the test calls Run or Complete, or until ctx
+// is done. It returns an error if the process is killed or cancelled before
+// it runs.
+func (f *FakePool) RegisterSync(ctx context.Context, p gowl.Process) error {
+	if err := f.register("RegisterSync", "", []gowl.Process{p}); err != nil {
+		return err
+	}
+
+	status, err := f.waitStatus(ctx, p.PID(), func(s process.Status) bool {
+		return s != process.Waiting
+	})
+	if err != nil {
+		return err
+	}
+	if status == process.Killed || status == process.Cancelled {
+		return fmt.Errorf("%w: %s", gowl.ErrInvalidProcessStatus, status)
+	}
+
+	return nil
+}
+
+// WaitUntilStatus blocks until the process reaches one of the statuses, and
+// returns an error if it reaches another final state first.
+func (f *FakePool) WaitUntilStatus(ctx context.Context, pid gowl.PID, statuses ...process.Status) (process.Status, error) {
+	f.record("WaitUntilStatus", ctx, pid, statuses)
+	if len(statuses) == 0 {
+		return 0, fmt.Errorf("%w: no status is given", gowl.ErrInvalidArgument)
+	}
+
+	contains := func(s process.Status) bool {
+		for _, status := range statuses {
+			if s == status {
+				return true
+			}
+		}
+		return false
+	}
+	status, err := f.waitStatus(ctx, pid, func(s process.Status) bool {
+		return s.IsTerminal() || contains(s)
+	})
+	if err != nil {
+		return status, err
+	}
+	if !contains(status) {
+		return status, fmt.Errorf("%w: %s", gowl.ErrInvalidProcessStatus, status)
+	}
+
+	return status, nil
+}
+
+// waitStatus blocks until the status of the process matches, or ctx is done.
+func (f *FakePool) waitStatus(ctx context.Context, pid gowl.PID, match func(process.Status) bool) (process.Status, error) {
+	for {
+		changed := f.monitor.wait()
+		stats, ok := f.monitor.stats(pid)
+		if !ok {
+			return 0, fmt.Errorf("%w: %s", gowl.ErrProcessNotFound, pid)
+		}
+		if match(stats.Status) {
+			return stats.Status, nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return stats.Status, ctx.Err()
+		}
+	}
+}
+
+// ForWorker returns a handle that adds the processes as Waiting on the
+// worker.
+func (f *FakePool) ForWorker(name gowl.WorkerName) (gowl.WorkerHandle, error) {
+	if err := f.call("ForWorker", name); err != nil {
+		return nil, err
+	}
+
+	return fakeHandle{pool: f, name: name}, nil
+}
+
+// Namespace returns the FakePool of the namespace, which is created on the
+// first call and has its own FakeMonitor. The namespace does not change the
+// process ids.
+func (f *FakePool) Namespace(ns string) gowl.Pool {
+	f.record("Namespace", ns)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	child, ok := f.namespaces[ns]
+	if !ok {
+		child = NewFakePool(nil)
+		f.namespaces[ns] = child
+	}
+
+	return child
+}
+
+// SubPool returns the FakePool of the sub-pool, which is the same as the
+// FakePool of the namespace name.
+func (f *FakePool) SubPool(name string, maxWorkers int) gowl.Pool {
+	f.record("SubPool", name, maxWorkers)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	child, ok := f.namespaces[name]
+	if !ok {
+		child = NewFakePool(nil)
+		f.namespaces[name] = child
+	}
+
+	return child
+}
+
+// Submit adds the process as Waiting on the worker of the handle.
+func (h fakeHandle) Submit(p gowl.Process) error {
+	return h.pool.register("Submit", h.name, []gowl.Process{p})
+}
+
+// Start returns nil, the barrier is never run.
+func (b barrierProcess) Start(context.Context) error {
+	return nil
+}
+
+// Name returns "barrier".
+func (b barrierProcess) Name() string {
+	return "barrier"
+}
+
+// PID returns the barrier id.
+func (b barrierProcess) PID() gowl.PID {
+	return b.pid
+}
@@ -0,0 +1,114 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package testutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl"
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// The fake pool should record the registered processes and trigger the
+// completion callbacks on Complete
+func TestFakePool(t *testing.T) {
+	a := assert.New(t)
+	f := NewFakePool(nil)
+	events := make(chan gowl.Event, 10)
+	f.Subscribe(events)
+
+	a.NoError(f.Start(context.Background()))
+	a.Equal(pool.Running, f.Monitor().PoolStatus())
+	a.NoError(f.Register(testProcess{"job", "p-1"}, testProcess{"job", "p-2"}))
+	a.Len(f.Registered(), 2)
+	stats, ok := f.Monitor().ProcessStats("p-1")
+	a.True(ok)
+	a.Equal(process.Waiting, stats.Status)
+
+	notified := make(chan gowl.ProcessResult, 1)
+	a.NoError(f.NotifyOn("p-1", notified))
+	a.ErrorIs(f.NotifyOn("p-3", notified), gowl.ErrProcessNotFound)
+	stream := f.CompletionStream()
+
+	a.NoError(f.Run("p-1", "W0"))
+	a.NoError(f.Complete(gowl.ProcessResult{PID: "p-1", Status: process.Succeeded, Output: 42}))
+	a.Error(f.Complete(gowl.ProcessResult{PID: "p-1", Status: process.Failed}))
+	result := <-notified
+	a.Equal(42, result.Output)
+	a.Equal(gowl.PID("p-1"), (<-stream).PID)
+
+	errFailed := errors.New("failed")
+	a.NoError(f.Complete(gowl.ProcessResult{PID: "p-2", Status: process.Failed, Err: errFailed}))
+	a.ErrorIs(f.Wait(), errFailed)
+
+	a.Equal(gowl.PoolStarted, (<-events).Type)
+	a.Equal(gowl.ProcessStarted, (<-events).Type)
+	a.Equal(gowl.ProcessSucceeded, (<-events).Type)
+	a.Equal(gowl.ProcessFailed, (<-events).Type)
+
+	a.Equal(gowl.PID("p-2"), (<-stream).PID)
+	a.NoError(f.Close())
+	_, open := <-stream
+	a.False(open)
+	a.Equal(1, f.CallCount("Register"))
+}
+
+// The blocked calls should return once the test moves the process forward
+func TestFakePool_Wait(t *testing.T) {
+	a := assert.New(t)
+	f := NewFakePool(nil)
+
+	done := make(chan error)
+	go func() {
+		done <- f.RegisterSync(context.Background(), testProcess{"job", "p-1"})
+	}()
+	a.Eventually(func() bool {
+		return len(f.Registered()) == 1
+	}, time.Second, time.Millisecond)
+	a.NoError(f.Run("p-1", "W0"))
+	a.NoError(<-done)
+
+	result, err := f.SubmitWithResult(testProcess{"job", "p-2"})
+	a.NoError(err)
+	a.NoError(f.Kill("p-2"))
+	a.ErrorIs(f.Kill("p-9"), gowl.ErrProcessNotFound)
+	_, ok := <-result
+	a.False(ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	a.ErrorIs(f.WaitContext(ctx), context.DeadlineExceeded)
+	f.KillWithReason("p-1", errors.New("stop"))
+	a.NoError(f.AwaitIdle(context.Background()))
+	status, err := f.WaitUntilStatus(context.Background(), "p-1", process.Succeeded)
+	a.Error(err)
+	a.Equal(process.Killed, status)
+}
+
+// A method should return the error that is programmed for it
+func TestFakePool_SetMethodError(t *testing.T) {
+	a := assert.New(t)
+	f := NewFakePool(nil)
+	errLocked := errors.New("locked")
+
+	f.SetMethodError("Register", errLocked)
+	a.ErrorIs(f.Register(testProcess{"job", "p-1"}), errLocked)
+	a.Empty(f.Registered())
+	a.Equal(1, f.CallCount("Register"))
+
+	f.SetMethodError("Register", nil)
+	a.NoError(f.Register(testProcess{"job", "p-1"}))
+	a.Len(f.Registered(), 1)
+
+	f.SetMethodError("Resize", errLocked)
+	a.ErrorIs(f.Resize(4), errLocked)
+	a.Equal([]interface{}{4}, f.Calls()[len(f.Calls())-1].Args)
+}
@@ -0,0 +1,41 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import "time"
+
+// timeoutProcess wraps a process to run it with its own timeout.
+type timeoutProcess struct {
+	Process
+	timeout time.Duration
+}
+
+// WithTimeout wraps the process to cancel its context once it has been
+// running for timeout, instead of the timeout of the pool. A process that
+// exceeds its timeout is marked as Failed and its error matches
+// context.DeadlineExceeded. Killing the process after its timeout is reached
+// does not change its status to Killed.
+func WithTimeout(p Process, timeout time.Duration) Process {
+	return timeoutProcess{Process: p, timeout: timeout}
+}
+
+// unwrap returns the wrapped process.
+func (t timeoutProcess) unwrap() Process {
+	return t.Process
+}
+
+// ownTimeout returns the timeout of the process that is set by WithTimeout.
+func ownTimeout(p Process) (time.Duration, bool) {
+	var timeout time.Duration
+	found := findLayer(p, func(l Process) bool {
+		tp, ok := l.(timeoutProcess)
+		if ok {
+			timeout = tp.timeout
+		}
+		return ok
+	})
+
+	return timeout, found
+}
@@ -0,0 +1,61 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// A process should fail once its own timeout is reached
+func TestWithTimeout(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(3), WithProcessTimeout(time.Second))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(
+		WithTimeout(newTestProcess("timeout", 1, time.Second, processFuncWithoutLog), 50*time.Millisecond),
+		WithTimeout(newTestProcess("timeout", 2, 10*time.Millisecond, processFuncWithoutLog), 50*time.Millisecond),
+		newTestProcess("timeout", 3, 200*time.Millisecond, processFuncWithoutLog),
+	)
+	wp.Wait()
+
+	stats := processStats(t, wp.Monitor(), "p-1")
+	a.Equal(process.Failed, stats.Status)
+	a.ErrorIs(processError(t, wp.Monitor(), "p-1"), context.DeadlineExceeded)
+	a.InDelta(50*time.Millisecond, stats.FinishedAt.Sub(stats.StartedAt), float64(30*time.Millisecond))
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-2").Status)
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-3").Status)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Killing a process after its timeout should not mark it as Killed
+func TestWithTimeout_KillAfterTimeout(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	slowCleanup := func(ctx context.Context, pid PID, d time.Duration) error {
+		<-ctx.Done()
+		time.Sleep(d)
+		return ctx.Err()
+	}
+	wp.Register(WithTimeout(newTestProcess("timeout", 1, 100*time.Millisecond, slowCleanup), 50*time.Millisecond))
+	time.Sleep(80 * time.Millisecond)
+	a.NoError(wp.KillWait("p-1", time.Second))
+
+	a.Equal(process.Failed, processStats(t, wp.Monitor(), "p-1").Status)
+	a.ErrorIs(processError(t, wp.Monitor(), "p-1"), context.DeadlineExceeded)
+
+	err = wp.Close()
+	a.NoError(err)
+}
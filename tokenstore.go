@@ -0,0 +1,48 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type (
+	// TokenStore persists the tokens of the rate limiter across pool
+	// restarts.
+	TokenStore interface {
+		// Save stores the number of available tokens.
+		Save(tokens float64) error
+		// Load returns the stored number of tokens, or a negative number if
+		// nothing has been stored yet.
+		Load() (float64, error)
+	}
+
+	// FileTokenStore is a TokenStore that keeps the tokens in the file at
+	// the given path.
+	FileTokenStore string
+)
+
+// Save writes the tokens to the file.
+func (f FileTokenStore) Save(tokens float64) error {
+	return os.WriteFile(string(f), []byte(strconv.FormatFloat(tokens, 'f', -1, 64)), 0o600)
+}
+
+// Load reads the tokens from the file. It returns -1 if the file does not
+// exist.
+func (f FileTokenStore) Load() (float64, error) {
+	data, err := os.ReadFile(string(f))
+	if errors.Is(err, fs.ErrNotExist) {
+		return -1, nil
+	}
+	if err != nil {
+		return -1, err
+	}
+
+	return strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+}
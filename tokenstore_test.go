@@ -0,0 +1,53 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Restarted pool should not get more tokens than it had before the restart
+func TestWithPersistentRateLimit(t *testing.T) {
+	a := assert.New(t)
+	store := FileTokenStore(filepath.Join(t.TempDir(), "tokens"))
+	tokens, err := store.Load()
+	a.NoError(err)
+	a.Less(tokens, 0.0)
+
+	wp := NewPool(WithWorkerCount(2), WithRateLimit(1, 5), WithPersistentRateLimit(store))
+	a.InDelta(5, wp.(*workerPool).limiter.available(), 0.01)
+	err = wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(createProcess(4, 1, 0, processFuncWithoutLog)...)
+	wp.Wait()
+	err = wp.Close()
+	a.NoError(err)
+
+	saved, err := store.Load()
+	a.NoError(err)
+	a.GreaterOrEqual(saved, 0.0)
+	a.LessOrEqual(saved, 1.1)
+
+	wp = NewPool(WithWorkerCount(2), WithRateLimit(1, 5), WithPersistentRateLimit(store))
+	a.LessOrEqual(wp.(*workerPool).limiter.available(), saved+0.1)
+}
+
+// Token store errors should be logged with the pool loggers
+func TestWithPersistentRateLimit_Error(t *testing.T) {
+	a := assert.New(t)
+	logger := &recordingLogger{}
+	store := FileTokenStore(filepath.Join(t.TempDir(), "missing", "tokens"))
+
+	wp := NewPool(WithWorkerCount(1), WithRateLimit(1, 5), WithPersistentRateLimit(store), WithLogger(logger))
+	a.InDelta(5, wp.(*workerPool).limiter.available(), 0.01)
+	a.NoError(wp.Start(context.Background()))
+	a.NoError(wp.Close())
+
+	a.Contains(logger.messages, "unable to save the rate limit tokens")
+}
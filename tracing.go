@@ -0,0 +1,98 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import "context"
+
+type (
+	// ExecutionTracer wraps each attempt of a process, for example in a
+	// tracing span. The gowl/otel package implements it with OpenTelemetry.
+	ExecutionTracer interface {
+		// StartExecution is called right before the process starts. It
+		// returns the context that is passed to the Start method of the
+		// process and a function that is called with the error of the
+		// attempt once the process returns.
+		StartExecution(ctx context.Context, e Execution) (context.Context, func(err error))
+	}
+
+	// Execution describes an attempt of a process.
+	Execution struct {
+		// PID is the process id.
+		PID PID
+		// Name is the process name.
+		Name string
+		// WorkerName is the worker that runs the attempt.
+		WorkerName WorkerName
+		// Attempt is the number of the attempt, starting at 1.
+		Attempt int
+		// Parent is the context that is attached to the process by
+		// WithParentContext. It is nil otherwise.
+		Parent context.Context
+	}
+
+	// parentContextProcess wraps a process to attach the context it has been
+	// registered from.
+	parentContextProcess struct {
+		Process
+		parent context.Context
+	}
+)
+
+// WithParentContext wraps the process to attach ctx, usually the context of
+// the caller that registers it, so the execution tracers can link the
+// attempts of the process to it. Only the Parent of Execution sees ctx, its
+// values and cancellation do not reach the process.
+func WithParentContext(p Process, ctx context.Context) Process {
+	return parentContextProcess{Process: p, parent: ctx}
+}
+
+// unwrap returns the wrapped process.
+func (pc parentContextProcess) unwrap() Process {
+	return pc.Process
+}
+
+// parentContext returns the context that is attached to the process by
+// WithParentContext.
+func parentContext(p Process) context.Context {
+	var parent context.Context
+	findLayer(p, func(l Process) bool {
+		pc, ok := l.(parentContextProcess)
+		if ok {
+			parent = pc.parent
+		}
+		return ok
+	})
+
+	return parent
+}
+
+// trace starts the attempt of the process with the execution tracers of the
+// pool in order. It returns the context of the process and a function that
+// ends the attempt in the reverse order.
+func (w *workerPool) trace(ctx context.Context, wn WorkerName, p Process, attempt int) (context.Context, func(err error)) {
+	if len(w.config.ExecutionTracers) == 0 {
+		return ctx, func(error) {}
+	}
+
+	e := Execution{
+		PID:        p.PID(),
+		Name:       p.Name(),
+		WorkerName: wn,
+		Attempt:    attempt,
+		Parent:     parentContext(p),
+	}
+	ends := make([]func(error), 0, len(w.config.ExecutionTracers))
+	for _, t := range w.config.ExecutionTracers {
+		var end func(error)
+		ctx, end = t.StartExecution(ctx, e)
+		ends = append(ends, end)
+	}
+
+	return ctx, func(err error) {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](err)
+		}
+	}
+}
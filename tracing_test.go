@@ -0,0 +1,76 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	tracingKey struct{}
+
+	// recordingTracer records the attempts and the errors it traces.
+	recordingTracer struct {
+		mutex      sync.Mutex
+		name       string
+		executions []Execution
+		errors     []error
+		calls      *[]string
+	}
+)
+
+func (r *recordingTracer) StartExecution(ctx context.Context, e Execution) (context.Context, func(err error)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.executions = append(r.executions, e)
+	*r.calls = append(*r.calls, "start "+r.name)
+
+	return context.WithValue(ctx, tracingKey{}, r.name), func(err error) {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		r.errors = append(r.errors, err)
+		*r.calls = append(*r.calls, "end "+r.name)
+	}
+}
+
+// The tracers should wrap each attempt in order and see the parent context
+func TestWithExecutionTracer(t *testing.T) {
+	a := assert.New(t)
+	calls := make([]string, 0)
+	first := &recordingTracer{name: "first", calls: &calls}
+	second := &recordingTracer{name: "second", calls: &calls}
+	wp := NewPool(WithWorkerCount(1), WithExecutionTracer(first), WithExecutionTracer(second))
+	a.NoError(wp.Start(context.Background()))
+
+	var traced interface{}
+	parent := context.WithValue(context.Background(), tracingKey{}, "caller")
+	p := ProcessFunc("p-1", "job", func(ctx context.Context) error {
+		traced = ctx.Value(tracingKey{})
+		return errCancelled
+	})
+	a.NoError(wp.Register(WithParentContext(WithRetry(p, 2, FixedBackoff{Delay: time.Millisecond}), parent)))
+	_ = wp.Wait()
+	a.NoError(wp.Close())
+
+	a.Equal("second", traced)
+	a.Equal([]string{"start first", "start second", "end second", "end first",
+		"start first", "start second", "end second", "end first"}, calls)
+	a.Len(first.executions, 2)
+	for i, e := range first.executions {
+		a.Equal(PID("p-1"), e.PID)
+		a.Equal("job", e.Name)
+		a.Equal(WorkerName("W0"), e.WorkerName)
+		a.Equal(i+1, e.Attempt)
+		a.Equal("caller", e.Parent.Value(tracingKey{}))
+	}
+	a.Equal([]error{errCancelled, errCancelled}, first.errors)
+}
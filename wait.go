@@ -0,0 +1,92 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// RegisterSync registers the process and blocks until a worker picks it up
+// and the process leaves the Waiting state, or ctx is done. If ctx is done
+// first, the context error is returned and the process stays registered. It
+// returns an error if the process is killed or cancelled before it starts,
+// or the error of Register if the pool rejects the process.
+func (w *workerPool) RegisterSync(ctx context.Context, p Process) error {
+	if err := w.Register(p); err != nil {
+		return err
+	}
+
+	status, err := w.waitStatus(ctx, p.PID(), func(s process.Status) bool {
+		return s != process.Waiting && s != process.Pending
+	})
+	if err != nil {
+		return err
+	}
+
+	if status == process.Killed || status == process.Cancelled {
+		return w.pidError("start the process", fmt.Errorf("%w: %s", ErrInvalidProcessStatus, status), p.PID())
+	}
+
+	return nil
+}
+
+// WaitUntilStatus blocks until the process reaches one of the statuses and
+// returns that status. Since a process never leaves a final state, it
+// returns an error if the process reaches a final state that is not one of
+// the statuses. It returns the context error if ctx is done first, and
+// ErrProcessNotFound if the process is not registered.
+func (w *workerPool) WaitUntilStatus(ctx context.Context, pid PID, statuses ...process.Status) (process.Status, error) {
+	if len(statuses) == 0 {
+		return 0, w.pidError("wait for the process", fmt.Errorf("%w: no status is given", ErrInvalidArgument), pid)
+	}
+
+	status, err := w.waitStatus(ctx, pid, func(s process.Status) bool {
+		return s.IsTerminal() || containsStatus(statuses, s)
+	})
+	if err != nil {
+		return status, err
+	}
+
+	if !containsStatus(statuses, status) {
+		return status, w.pidError("wait for the process", fmt.Errorf("%w: %s", ErrInvalidProcessStatus, status), pid)
+	}
+
+	return status, nil
+}
+
+// containsStatus reports whether status is in the list.
+func containsStatus(statuses []process.Status, status process.Status) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// waitStatus blocks until the status of the process matches, or ctx is done.
+// It returns ErrProcessNotFound if the process is not registered.
+func (w *workerPool) waitStatus(ctx context.Context, pid PID, match func(process.Status) bool) (process.Status, error) {
+	for {
+		changed := w.changes.wait()
+		stats := w.processes.get(pid)
+		if stats.Process == nil {
+			return stats.Status, w.pidError("wait for the process", ErrProcessNotFound, pid)
+		}
+		if match(stats.Status) {
+			return stats.Status, nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return stats.Status, ctx.Err()
+		}
+	}
+}
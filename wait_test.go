@@ -0,0 +1,79 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// RegisterSync should return once a worker picked the process up
+func TestWorkerPool_RegisterSync(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	a.NoError(wp.RegisterSync(context.Background(), newTestProcess("sync", 1, 200*time.Millisecond, processFuncWithoutLog)))
+	a.Equal(process.Running, processStats(t, wp.Monitor(), "p-1").Status)
+
+	// The only worker is busy, so the next process keeps waiting.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = wp.RegisterSync(ctx, newTestProcess("sync", 2, 0, processFuncWithoutLog))
+	a.ErrorIs(err, context.DeadlineExceeded)
+	a.Equal(process.Waiting, processStats(t, wp.Monitor(), "p-2").Status)
+
+	// A process that is killed before it starts is reported.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = wp.Kill("p-3")
+	}()
+	err = wp.RegisterSync(context.Background(), newTestProcess("sync", 3, 0, processFuncWithoutLog))
+	a.ErrorIs(err, ErrInvalidProcessStatus)
+	stats := processStats(t, wp.Monitor(), "p-3")
+	a.Equal(process.Killed, stats.Status)
+	a.True(stats.StartedAt.IsZero())
+
+	wp.Wait()
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// WaitUntilStatus should return as soon as the process reaches the status
+func TestWorkerPool_WaitUntilStatus(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(newTestProcess("wait", 1, 200*time.Millisecond, processFuncWithoutLog))
+
+	status, err := wp.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.NoError(err)
+	a.Equal(process.Running, status)
+	a.Equal(process.Running, processStats(t, wp.Monitor(), "p-1").Status)
+
+	status, err = wp.WaitUntilStatus(context.Background(), "p-1", process.Failed, process.Succeeded)
+	a.NoError(err)
+	a.Equal(process.Succeeded, status)
+
+	// A finished process never reaches the other statuses.
+	status, err = wp.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.ErrorIs(err, ErrInvalidProcessStatus)
+	a.Equal(process.Succeeded, status)
+	_, err = wp.WaitUntilStatus(context.Background(), "p-1")
+	a.ErrorIs(err, ErrInvalidArgument)
+
+	_, err = wp.WaitUntilStatus(context.Background(), "p-0", process.Running)
+	a.ErrorIs(err, ErrProcessNotFound)
+
+	err = wp.Close()
+	a.NoError(err)
+}
@@ -0,0 +1,96 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrWatchdogTimeout is the error of a process that is cancelled by its
+// watchdog.
+var ErrWatchdogTimeout = errors.New("watchdog timeout")
+
+// watchdogProcess wraps a process to watch its liveness while it runs.
+type watchdogProcess struct {
+	Process
+	heartbeat func() bool
+	timeout   time.Duration
+}
+
+// WithWatchdog wraps the process to cancel it once it stops making progress.
+// While the process runs, the pool calls heartbeat periodically, and if
+// heartbeat keeps returning false for timeout, the pool cancels the context
+// of the process and marks it as Failed with an error that matches
+// ErrWatchdogTimeout. The process is Failed even if Start ignores the
+// cancellation and returns nil. The watchdog is disabled if timeout is not
+// positive.
+func WithWatchdog(p Process, heartbeat func() bool, timeout time.Duration) Process {
+	return watchdogProcess{Process: p, heartbeat: heartbeat, timeout: timeout}
+}
+
+// unwrap returns the wrapped process.
+func (d watchdogProcess) unwrap() Process {
+	return d.Process
+}
+
+// ownWatchdog returns the watchdog of the process that is set by
+// WithWatchdog.
+func ownWatchdog(p Process) (watchdogProcess, bool) {
+	var wd watchdogProcess
+	found := findLayer(p, func(l Process) bool {
+		dp, ok := l.(watchdogProcess)
+		if ok {
+			wd = dp
+		}
+		return ok
+	})
+
+	return wd, found && wd.timeout > 0 && wd.heartbeat != nil
+}
+
+// watch runs the watchdog of an attempt until ctx is done or the returned
+// function is called. The watchdog calls cancel when the heartbeat has
+// failed for the whole timeout. The returned function stops the watchdog and
+// reports whether it has cancelled the attempt.
+func (d watchdogProcess) watch(ctx context.Context, cancel context.CancelFunc) func() bool {
+	stop := make(chan struct{})
+	fired := make(chan bool, 1)
+	go func() {
+		// Probing a few times per timeout bounds the delay of the watchdog
+		// to a fraction of the timeout.
+		interval := d.timeout / 4
+		if interval <= 0 {
+			interval = d.timeout
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		alive := time.Now()
+		for {
+			select {
+			case now := <-ticker.C:
+				if d.heartbeat() {
+					alive = now
+				} else if now.Sub(alive) >= d.timeout {
+					cancel()
+					fired <- true
+					return
+				}
+			case <-ctx.Done():
+				fired <- false
+				return
+			case <-stop:
+				fired <- false
+				return
+			}
+		}
+	}()
+
+	return func() bool {
+		close(stop)
+		return <-fired
+	}
+}
@@ -0,0 +1,82 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// A process should fail once its heartbeat has failed for the watchdog timeout
+func TestWithWatchdog(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	var progress int32
+	alive := func() bool { return atomic.AddInt32(&progress, 1) < 3 }
+	wp.Register(
+		WithWatchdog(newTestProcess("watchdog", 1, time.Second, processFuncWithoutLog), alive, 50*time.Millisecond),
+		WithWatchdog(newTestProcess("watchdog", 2, 100*time.Millisecond, processFuncWithoutLog),
+			func() bool { return true }, 20*time.Millisecond),
+	)
+	wp.Wait()
+
+	stats := processStats(t, wp.Monitor(), "p-1")
+	a.Equal(process.Failed, stats.Status)
+	a.ErrorIs(processError(t, wp.Monitor(), "p-1"), ErrWatchdogTimeout)
+	a.Less(stats.FinishedAt.Sub(stats.StartedAt), 500*time.Millisecond)
+	a.Equal(process.Succeeded, processStats(t, wp.Monitor(), "p-2").Status)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// A process that ignores the cancellation of its watchdog should still fail
+func TestWithWatchdog_IgnoredCancellation(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	stubborn := func(ctx context.Context, pid PID, d time.Duration) error {
+		<-ctx.Done()
+		return nil
+	}
+	wp.Register(WithWatchdog(newTestProcess("watchdog", 1, 0, stubborn), func() bool { return false },
+		20*time.Millisecond))
+	wp.Wait()
+
+	a.Equal(process.Failed, processStats(t, wp.Monitor(), "p-1").Status)
+	a.ErrorIs(processError(t, wp.Monitor(), "p-1"), ErrWatchdogTimeout)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Killing a process should not be reported as a watchdog timeout
+func TestWithWatchdog_Kill(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(WithWatchdog(newTestProcess("watchdog", 1, time.Second, processFuncWithoutLog),
+		func() bool { return true }, 20*time.Millisecond))
+	_, err = wp.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.NoError(err)
+	time.Sleep(30 * time.Millisecond)
+	a.NoError(wp.KillWait("p-1", time.Second))
+
+	a.Equal(process.Killed, processStats(t, wp.Monitor(), "p-1").Status)
+	a.NotErrorIs(processError(t, wp.Monitor(), "p-1"), ErrWatchdogTimeout)
+
+	err = wp.Close()
+	a.NoError(err)
+}
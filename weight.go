@@ -0,0 +1,33 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import "errors"
+
+// ErrQueueWeightExceeded is returned by Register when the total weight of
+// the waiting processes would exceed the maximum queue weight.
+var ErrQueueWeightExceeded = errors.New("queue weight exceeded")
+
+// WeightedProcess is a Process that declares how much of the queue capacity
+// it takes. A process that does not implement WeightedProcess weighs 1.
+type WeightedProcess interface {
+	Process
+	// Weight returns the process weight.
+	Weight() int
+}
+
+// processWeight returns the weight of the process.
+func processWeight(p Process) int64 {
+	weight := int64(1)
+	findLayer(p, func(l Process) bool {
+		wp, ok := l.(WeightedProcess)
+		if ok {
+			weight = int64(wp.Weight())
+		}
+		return ok
+	})
+
+	return weight
+}
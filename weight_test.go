@@ -0,0 +1,51 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// weightedProcess is a process with a weight.
+type weightedProcess struct {
+	Process
+	weight int
+}
+
+func (p weightedProcess) Weight() int {
+	return p.weight
+}
+
+// Register should reject the processes that do not fit in the queue weight
+func TestWithMaxQueueWeight(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithMaxQueueWeight(10))
+
+	processes := createProcess(5, 1, 0, processFuncWithoutLog)
+	for i, p := range processes {
+		err := wp.Register(weightedProcess{Process: p, weight: 3})
+		if i < 3 {
+			a.NoError(err)
+		} else {
+			a.ErrorIs(err, ErrQueueWeightExceeded)
+		}
+	}
+	a.Equal(int64(3), wp.Stats().TotalRegistered)
+
+	// The weight is released once the processes leave the queue.
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Wait()
+	a.NoError(wp.Register(weightedProcess{Process: processes[3], weight: 3}))
+	a.NoError(wp.Register(newTestProcess("light", 99, 0, processFuncWithoutLog)))
+	wp.Wait()
+
+	err = wp.Close()
+	a.NoError(err)
+	a.Equal(int64(5), wp.Stats().TotalSucceeded)
+}
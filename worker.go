@@ -0,0 +1,711 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/hamed-yousefi/gowl/status/worker"
+)
+
+type (
+	// WorkerHandle submits processes to a specific worker.
+	WorkerHandle interface {
+		// Submit registers the process to run on the worker.
+		Submit(p Process) error
+	}
+
+	// workerControl holds the channels that are used to control a worker.
+	workerControl struct {
+		// quit is closed when the worker is retired.
+		quit chan struct{}
+
+		// inbox receives the processes that are pinned to the worker.
+		inbox chan Process
+
+		// exited is closed when the worker goroutine returns.
+		exited chan struct{}
+	}
+
+	// workerHandle is an implementation of WorkerHandle.
+	workerHandle struct {
+		pool *workerPool
+		name WorkerName
+	}
+)
+
+// addWorkers creates n new workers and starts them. The caller must hold the
+// workers mutex.
+func (w *workerPool) addWorkers(n int) {
+	for i := 0; i < n; i++ {
+		w.startWorker(WorkerName(fmt.Sprintf(defaultWorkerName, w.nextWorker)))
+		w.nextWorker++
+	}
+}
+
+// startWorker creates the worker with the given name and starts it. The
+// caller must hold the workers mutex.
+func (w *workerPool) startWorker(wName WorkerName) {
+	// For each worker add one to the waitGroup.
+	w.wg.Add(1)
+	w.workers = append(w.workers, wName)
+	w.workersStats.put(wName, worker.Waiting)
+	control := &workerControl{
+		quit:   make(chan struct{}),
+		inbox:  make(chan Process),
+		exited: make(chan struct{}),
+	}
+	w.controls[wName] = control
+	w.publishStats()
+
+	// Create worker.
+	go w.work(wName, control)
+}
+
+// removeWorkers retires n workers. Idle workers are retired first. A busy
+// worker finishes its current process and then exits. The caller must hold
+// the workers mutex.
+func (w *workerPool) removeWorkers(n int) {
+	// Order the candidates by putting the idle workers first and keeping the
+	// newest workers at the front of each group.
+	candidates := make([]WorkerName, 0, len(w.workers))
+	for i := len(w.workers) - 1; i >= 0; i-- {
+		if w.workersStats.get(w.workers[i]) == worker.Waiting {
+			candidates = append(candidates, w.workers[i])
+		}
+	}
+	for i := len(w.workers) - 1; i >= 0; i-- {
+		if w.workersStats.get(w.workers[i]) != worker.Waiting {
+			candidates = append(candidates, w.workers[i])
+		}
+	}
+
+	retired := make(map[WorkerName]bool, n)
+	for _, wn := range candidates[:n] {
+		close(w.controls[wn].quit)
+		delete(w.controls, wn)
+		retired[wn] = true
+	}
+
+	workers := make([]WorkerName, 0, len(w.workers)-n)
+	for _, wn := range w.workers {
+		if !retired[wn] {
+			workers = append(workers, wn)
+		}
+	}
+	w.workers = workers
+}
+
+// work consumes processes from the queue and the worker inbox until the
+// queue is closed or the worker is retired. With an idle worker timeout, it
+// also returns when the worker goes to sleep.
+func (w *workerPool) work(wn WorkerName, control *workerControl) {
+	w.log(levelDebug, "worker has started", Field{"worker", wn})
+	released := false
+	defer func() {
+		if released {
+			// The pool has moved on without the worker, which only had to
+			// wait for its process to return.
+			close(control.exited)
+			return
+		}
+		w.workersStats.delete(wn)
+		w.workerCounters.delete(wn)
+		w.publishStats()
+		w.log(levelDebug, "worker has stopped", Field{"worker", wn})
+		close(control.exited)
+		w.wg.Done()
+	}()
+
+	done := w.closeSignal()
+	idleSince := time.Now()
+	for {
+		// Check the retire signal first, so a retired worker never picks up
+		// a new process.
+		select {
+		case <-control.quit:
+			return
+		default:
+		}
+
+		// The pinned processes can only run on this worker, so they go
+		// before the shared queue.
+		select {
+		case p := <-control.inbox:
+			if !w.throttle(control, p) {
+				return
+			}
+			if released = !w.execute(wn, p); released {
+				return
+			}
+			idleSince = time.Now()
+			continue
+		default:
+		}
+
+		// Wait for the rate limiter only when there is a process to take,
+		// so the idle workers do not hold tokens. With backpressure, the
+		// token has already been taken by Register.
+		changed := w.queue.wait()
+		token := false
+		if w.throttled() && !w.queue.isEmpty() {
+			if !w.limiter.wait(control.quit, done) {
+				return
+			}
+			token = true
+		}
+		if p, ok := w.queue.popFirst(w.subPools.acquire); ok {
+			if released = !w.executeQueued(wn, p); released {
+				return
+			}
+			idleSince = time.Now()
+			continue
+		}
+		if token {
+			w.limiter.put()
+		}
+		if w.queue.isClosed() {
+			return
+		}
+
+		var idle <-chan time.Time
+		timer := w.idleTimer(idleSince)
+		if timer != nil {
+			idle = timer.C
+		}
+		select {
+		case <-changed:
+		case p := <-control.inbox:
+			if !w.throttle(control, p) {
+				return
+			}
+			if released = !w.execute(wn, p); released {
+				return
+			}
+			idleSince = time.Now()
+		case <-control.quit:
+			return
+		case <-idle:
+			if w.sleep(wn, control) {
+				return
+			}
+			idleSince = time.Now()
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// executeQueued runs the process that has been taken from the queue like
+// execute, and then gives back the worker of its sub-pools, even if the
+// worker has been released by CloseContext.
+func (w *workerPool) executeQueued(wn WorkerName, p Process) bool {
+	defer func() {
+		// The other workers may wait for the sub-pool to have room.
+		if w.subPools.release(p) {
+			w.queue.signal()
+		}
+	}()
+
+	return w.execute(wn, p)
+}
+
+// execute runs the process and keeps its stats up to date. It returns false
+// if CloseContext has given up on the process while it was running, so the
+// worker has been released from the pool.
+func (w *workerPool) execute(wn WorkerName, p Process) bool {
+	// A process that has been killed while it was waiting is finished
+	// without ever looking Running.
+	pContext := w.controlPanel.get(p.PID())
+	if pContext.ctx.Err() != nil {
+		w.log(levelInfo, "process has been killed", Field{"name", w.processName(p)}, Field{"pid", p.PID()})
+		stats := w.processes.get(p.PID())
+		stats.Status = process.Killed
+		w.abandon(p, stats)
+		return true
+	}
+
+	allowed, probe := w.circuits.allow(w.processName(p))
+	if !allowed {
+		w.rejectCircuit(p)
+		return true
+	}
+
+	// Mark the worker busy before the process leaves the queue, so the pool
+	// never looks idle in between.
+	w.workersStats.put(wn, worker.Busy)
+	w.counters.dequeue(processWeight(p))
+	pContext.begin()
+	pStats := w.processes.get(p.PID())
+	pStats.Status = process.Running
+	pStats.StartedAt = time.Now()
+	pStats.updatedAt = pStats.StartedAt
+	w.starts.mark(pStats.StartedAt)
+	w.workerCounters.begin(wn, p.PID(), pStats.StartedAt)
+	pStats.WorkerName = wn
+	pStats.Attempt++
+	w.processes.put(p.PID(), pStats)
+	w.notify()
+	w.log(levelDebug, "process has been dispatched", Field{"name", w.processName(p)}, Field{"pid", p.PID()},
+		Field{"worker", wn}, Field{"attempt", pStats.Attempt})
+	w.observeDequeue(wn, p, pStats.QueueWaitDuration())
+	wgp := new(sync.WaitGroup)
+	wgp.Add(1)
+	owned := true
+
+	go func() {
+		stats := w.processes.get(p.PID())
+		defer func() {
+			if owned = pContext.end(); owned {
+				w.processes.put(p.PID(), stats)
+			}
+			wgp.Done()
+		}()
+		select {
+		case <-pContext.ctx.Done():
+			w.log(levelInfo, "process has been killed", Field{"name", w.processName(p)}, Field{"pid", p.PID()})
+			stats.Status = process.Killed
+			return
+		default:
+			if err := validate(p); err != nil {
+				stats.err = err
+				stats.Status = process.Failed
+				pContext.cancel()
+				return
+			}
+
+			// Each attempt has its own context, so a process that is retried
+			// keeps the context that Kill cancels.
+			ctx, cancel := context.WithCancel(pContext.ctx)
+			if timeout := w.processTimeout(p); timeout > 0 {
+				ctx, cancel = context.WithTimeout(pContext.ctx, timeout)
+			}
+			defer cancel()
+			timed := ctx
+			stalled := func() bool { return false }
+			if wd, ok := ownWatchdog(p); ok {
+				var stop context.CancelFunc
+				ctx, stop = context.WithCancel(ctx)
+				defer stop()
+				stalled = wd.watch(ctx, stop)
+			}
+
+			if w.config.CPUTracking {
+				stop := startCPUProfile()
+				defer func() {
+					stats.CPUProfile = stop()
+				}()
+			}
+
+			ctx = w.enrich(w.withLogger(w.withCheckpointScope(ctx, p)), p)
+			ctx, end := w.trace(ctx, wn, p, stats.Attempt)
+			defer func() {
+				end(stats.err)
+			}()
+			w.observeStart(wn, p)
+			w.emit(ProcessStarted, p.PID(), wn)
+			err := w.start(ctx, p) //nolint:typecheck
+			switch {
+			case stalled():
+				// A kill after the watchdog timeout does not change the status.
+				w.log(levelWarn, "process has been stopped by its watchdog", Field{"name", w.processName(p)},
+					Field{"pid", p.PID()})
+				stats.err = ErrWatchdogTimeout
+				if err != nil {
+					stats.err = fmt.Errorf("%w: %v", ErrWatchdogTimeout, err)
+				}
+				stats.Status = process.Failed
+			case err != nil:
+				stats.err = err
+				stats.Status = process.Failed
+				switch {
+				case errors.Is(timed.Err(), context.DeadlineExceeded):
+					// A kill after the timeout does not change the status.
+					if !errors.Is(err, context.DeadlineExceeded) {
+						stats.err = fmt.Errorf("%w: %v", context.DeadlineExceeded, err)
+					}
+				case errors.Is(pContext.ctx.Err(), context.Canceled):
+					stats.Status = process.Killed
+				}
+			default:
+				stats.Status = process.Succeeded
+			}
+
+			if stats.Status == process.Failed && retryable(p, stats) {
+				stats.Status = process.Retrying
+			} else {
+				pContext.cancel()
+			}
+		}
+	}()
+
+	wgp.Wait()
+	if !owned {
+		return false
+	}
+	pStats = w.processes.get(p.PID())
+	pStats.FinishedAt = time.Now()
+	pStats.updatedAt = pStats.FinishedAt
+	w.histograms.record(p.PID(), pStats.FinishedAt.Sub(pStats.StartedAt), w.config.HistogramWindow)
+	w.workerCounters.end(wn, pStats.Status, pStats.FinishedAt.Sub(pStats.StartedAt))
+	opened := w.circuits.record(w.processName(p), probe, pStats.Status)
+	if !w.retry(p, pStats) {
+		w.finish(p, pStats)
+	}
+	if opened {
+		w.openCircuit(w.processName(p))
+	}
+	w.workersStats.put(wn, worker.Waiting)
+	w.notify()
+
+	return true
+}
+
+// finish records the final state of the process and notifies the observers,
+// the collectors, and the listeners of the process.
+func (w *workerPool) finish(p Process, pStats ProcessStats) {
+	if reason := w.controlPanel.get(p.PID()).killReason(); reason != nil && pStats.Status == process.Killed {
+		pStats.err = reason
+	}
+	w.storeResult(&pStats)
+	w.dedupError(&pStats)
+	w.processes.put(p.PID(), pStats)
+	w.counters.finish(pStats.Status)
+	w.checkErrors()
+	if pStats.Status == process.Succeeded {
+		w.clearCheckpoints(p)
+	}
+	if !pStats.StartedAt.IsZero() {
+		w.counters.run(pStats.FinishedAt.Sub(pStats.StartedAt))
+	}
+	w.observeComplete(pStats.WorkerName, pStats.result())
+	w.collect(pStats)
+	w.completions.push(pStats.result())
+	w.complete(p, pStats)
+	w.emitFinish(pStats)
+	w.publishStats()
+	close(w.controlPanel.get(p.PID()).done)
+	w.notify()
+}
+
+// processTimeout returns the timeout of the process. It is the timeout set by
+// WithTimeout if any, otherwise the timeout of the pool including a random
+// jitter. It returns zero if there is no timeout.
+func (w *workerPool) processTimeout(p Process) time.Duration {
+	if timeout, ok := ownTimeout(p); ok {
+		return timeout
+	}
+
+	timeout := w.config.ProcessTimeout
+	if timeout > 0 && w.config.TimeoutJitter > 0 {
+		timeout += time.Duration(rand.Int63n(int64(w.config.TimeoutJitter) + 1)) //nolint:gosec
+	}
+
+	return timeout
+}
+
+// Resize changes the number of workers to n. New workers start consuming the
+// queue immediately. When the pool shrinks, idle workers are retired first
+// and busy workers finish their current process before they exit, so no
+// running process is interrupted. It returns an error if the pool is not
+// running, or ErrPoolLocked if the pool is locked.
+func (w *workerPool) Resize(n int) error {
+	return w.resize(func(int) int {
+		return n
+	})
+}
+
+// Scale adds delta workers to the pool. A negative delta removes workers the
+// same way Resize does. The worker count is read and changed atomically, so
+// concurrent calls to Scale add up.
+func (w *workerPool) Scale(delta int) error {
+	return w.resize(func(current int) int {
+		return current + delta
+	})
+}
+
+// resize changes the number of workers to the target of the current number
+// of workers.
+func (w *workerPool) resize(target func(current int) int) error {
+	if err := w.configurable(); err != nil {
+		return err
+	}
+
+	w.workersMutex.Lock()
+	defer w.workersMutex.Unlock()
+
+	w.wakeLocked(w.sleepingWorkers()...)
+	current := len(w.workers)
+	n := target(current)
+	if n < 0 {
+		return w.opError("resize the pool", fmt.Errorf("%w: number of workers is %d", ErrInvalidArgument, n))
+	}
+
+	switch {
+	case n > current:
+		w.addWorkers(n - current)
+	case n < current:
+		w.removeWorkers(current - n)
+	}
+	atomic.AddInt64(&w.version, 1)
+
+	return nil
+}
+
+// EnsureWorkers brings the number of workers up to n. It is a no-op if the
+// pool already has n or more workers. Unlike Resize, it never removes
+// workers.
+func (w *workerPool) EnsureWorkers(n int) error {
+	if err := w.configurable(); err != nil {
+		return err
+	}
+
+	w.workersMutex.Lock()
+	defer w.workersMutex.Unlock()
+
+	w.wakeLocked(w.sleepingWorkers()...)
+	if missing := n - len(w.workers); missing > 0 {
+		w.addWorkers(missing)
+		atomic.AddInt64(&w.version, 1)
+	}
+
+	return nil
+}
+
+// Capacity returns the number of processes that can be executed right now,
+// which is the number of idle workers, and the maximum number of processes
+// that can be executed concurrently, which is the number of workers.
+func (w *workerPool) Capacity() (current, max int) {
+	active, idle := w.workerCounts()
+
+	return idle, active + idle
+}
+
+// Utilization returns the fraction of workers that are running a process, a
+// value in [0, 1]. It returns 0 if the pool has no worker.
+func (w *workerPool) Utilization() float64 {
+	active, idle := w.workerCounts()
+	if active+idle == 0 {
+		return 0
+	}
+
+	return float64(active) / float64(active+idle)
+}
+
+// workerCounts returns the number of busy and idle workers.
+func (w *workerPool) workerCounts() (active, idle int) {
+	for _, wn := range w.WorkerList() {
+		if w.workersStats.get(wn) == worker.Busy {
+			active++
+		} else {
+			idle++
+		}
+	}
+
+	return active, idle
+}
+
+// ActiveWorkerCount returns the number of workers that are running a process.
+func (w *workerPool) ActiveWorkerCount() int {
+	active, _ := w.workerCounts()
+	return active
+}
+
+// IdleWorkerCount returns the number of workers that are waiting for a
+// process.
+func (w *workerPool) IdleWorkerCount() int {
+	_, idle := w.workerCounts()
+	return idle
+}
+
+// AwaitIdle blocks until there is no process waiting in the queue and all
+// workers are idle. It is the synchronization point to use between two
+// batches of processes. It returns the context error if ctx is done first,
+// and ErrPoolClosed if the pool is closed while processes are still waiting.
+// It returns ErrMaxErrorsReached if the pool is errored while processes are
+// still waiting.
+func (w *workerPool) AwaitIdle(ctx context.Context) error {
+	done, closed := w.closeSignal(), false
+	for {
+		changed := w.changes.wait()
+		if w.ActiveWorkerCount() == 0 {
+			if atomic.LoadInt64(&w.counters.waiting) == 0 {
+				return nil
+			}
+			if closed {
+				return ErrPoolClosed
+			}
+			if err := w.errored(); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+			// The waiting processes will not run anymore, wait for the
+			// running ones only.
+			done, closed = nil, true
+		}
+	}
+}
+
+// Wait blocks until all registered processes are finished, the same way
+// sync.WaitGroup.Wait does, and then returns a *MultiError that aggregates
+// the errors of the failed processes of the current pool lifecycle, or nil if
+// all of them succeeded. It returns ErrPoolClosed if the pool is closed
+// before the processes are finished. It is safe to call Wait from multiple
+// goroutines. Use WaitContext to stop waiting on cancellation.
+func (w *workerPool) Wait() error {
+	if err := w.WaitContext(context.Background()); err != nil {
+		return err
+	}
+
+	cycle := int(atomic.LoadInt64(&w.cycle))
+	list := make([]ProcessStats, 0)
+	w.processes.each(func(_ PID, stats ProcessStats) {
+		if stats.Cycle == cycle {
+			list = append(list, stats)
+		}
+	})
+
+	return processErrors(list)
+}
+
+// WaitContext blocks until all registered processes are finished. It is an
+// alias of AwaitIdle and returns the context error if ctx is done first.
+func (w *workerPool) WaitContext(ctx context.Context) error {
+	return w.AwaitIdle(ctx)
+}
+
+// Throttle multiplies the rate limit of the pool by factor at runtime. The
+// factor must be in (0, 1] and it is always applied to the rate that has
+// been set by WithRateLimit, so Throttle(0.5) halves the throughput and
+// Throttle(1) restores it. It returns an error if the pool is not running, is
+// locked, or has no rate limit.
+func (w *workerPool) Throttle(factor float64) error {
+	if err := w.configurable(); err != nil {
+		return err
+	}
+
+	if w.limiter == nil {
+		return w.opError("throttle the pool", ErrRateLimitNotSet)
+	}
+
+	if factor <= 0 || factor > 1 {
+		return w.opError("throttle the pool", fmt.Errorf("%w: throttle factor is %g", ErrInvalidArgument, factor))
+	}
+
+	w.limiter.setFactor(factor)
+	atomic.AddInt64(&w.version, 1)
+
+	return nil
+}
+
+// throttled reports whether the workers wait for the rate limiter before
+// they run a process.
+func (w *workerPool) throttled() bool {
+	return w.limiter != nil && !w.config.RateLimitBackpressure
+}
+
+// throttle waits for the rate limiter before the worker runs the process it
+// has received in its inbox. It returns false if the worker is retired or
+// the pool is closed meanwhile, in which case the process is published again
+// or cancelled.
+func (w *workerPool) throttle(control *workerControl, p Process) bool {
+	done := w.closeSignal()
+	if !w.throttled() || w.limiter.wait(control.quit, done) {
+		return true
+	}
+
+	select {
+	case <-done:
+		w.cancel(p)
+	default:
+		if !w.publish(p) {
+			w.cancel(p)
+		}
+	}
+
+	return false
+}
+
+// ForWorker returns a handle to submit processes to the worker with the given
+// name, for cache warmth or debugging. It returns ErrWorkerNotFound if the
+// pool has no worker with that name.
+func (w *workerPool) ForWorker(name WorkerName) (WorkerHandle, error) {
+	w.wakeWorker(name)
+	w.workersMutex.RLock()
+	defer w.workersMutex.RUnlock()
+
+	if _, ok := w.controls[name]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrWorkerNotFound, name)
+	}
+
+	return &workerHandle{pool: w, name: name}, nil
+}
+
+// Submit registers the process and publishes it to the worker inbox. The
+// process waits for the worker even if other workers are idle. If the worker
+// is retired before it picks up the process, the process is published to
+// the pool queue instead.
+func (h *workerHandle) Submit(p Process) error {
+	if err := h.pool.checkName(p); err != nil {
+		return err
+	}
+
+	var control *workerControl
+	err := h.pool.claimPIDs([]Process{p}, func(args []Process) error {
+		if len(args) == 0 {
+			return nil
+		}
+
+		if len(dependencies(p)) > 0 {
+			return h.pool.pidError("submit the process to a worker",
+				fmt.Errorf("%w: process has dependencies", ErrInvalidArgument), p.PID())
+		}
+
+		h.pool.wakeWorker(h.name)
+		h.pool.workersMutex.RLock()
+		c, ok := h.pool.controls[h.name]
+		h.pool.workersMutex.RUnlock()
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrWorkerNotFound, h.name)
+		}
+
+		if err := h.pool.admit(p); err != nil {
+			return err
+		}
+		h.pool.prepare(p)
+		control = c
+
+		return nil
+	})
+	if err != nil || control == nil {
+		return err
+	}
+
+	done := h.pool.closeSignal()
+	go func() {
+		select {
+		case control.inbox <- p:
+		case <-control.quit:
+			h.pool.publish(p)
+		case <-done:
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,385 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/pool"
+	"github.com/hamed-yousefi/gowl/status/process"
+)
+
+// Scale the pool down and bring it back with EnsureWorkers
+func TestWorkerPool_EnsureWorkers(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(3))
+	err := wp.EnsureWorkers(3)
+	a.Error(err)
+	a.ErrorIs(err, ErrPoolNotRunning)
+	err = wp.Start(context.Background())
+	a.NoError(err)
+	a.Len(wp.Monitor().WorkerList(), 3)
+
+	err = wp.Scale(-2)
+	a.NoError(err)
+	a.Len(wp.Monitor().WorkerList(), 1)
+
+	err = wp.EnsureWorkers(3)
+	a.NoError(err)
+	a.Len(wp.Monitor().WorkerList(), 3)
+
+	// EnsureWorkers never removes workers.
+	err = wp.EnsureWorkers(2)
+	a.NoError(err)
+	a.Len(wp.Monitor().WorkerList(), 3)
+
+	wp.Register(createProcess(6, 1, 50*time.Millisecond, processFunc)...)
+	time.Sleep(300 * time.Millisecond)
+	err = wp.Close()
+	a.NoError(err)
+	a.Equal(int64(6), wp.Stats().TotalSucceeded)
+}
+
+// Shrinking the pool should not interrupt running processes
+func TestWorkerPool_Resize(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(4))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(createProcess(4, 1, 300*time.Millisecond, processFunc)...)
+	time.Sleep(100 * time.Millisecond)
+
+	err = wp.Resize(1)
+	a.NoError(err)
+	a.Len(wp.Monitor().WorkerList(), 1)
+	err = wp.Resize(-1)
+	a.Error(err)
+
+	time.Sleep(400 * time.Millisecond)
+	err = wp.Close()
+	a.NoError(err)
+	a.Equal(int64(4), wp.Stats().TotalSucceeded)
+}
+
+// Concurrent Scale calls should add up and keep the queued processes
+func TestWorkerPool_Scale(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	a.Error(wp.Scale(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(createProcess(10, 1, 50*time.Millisecond, processFuncWithoutLog)...)
+
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.NoError(wp.Scale(1))
+		}()
+	}
+	wg.Wait()
+	a.Len(wp.Monitor().WorkerList(), 10)
+
+	a.NoError(wp.Scale(-9))
+	a.Len(wp.Monitor().WorkerList(), 1)
+	a.Error(wp.Scale(-2))
+
+	a.NoError(wp.Wait())
+	err = wp.Close()
+	a.NoError(err)
+	a.Equal(int64(10), wp.Stats().TotalSucceeded)
+	a.ErrorIs(wp.Scale(1), ErrPoolNotRunning)
+}
+
+// Throttle should reduce the throughput of a rate limited pool
+func TestWorkerPool_Throttle(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Throttle(0.5)
+	a.ErrorIs(err, ErrPoolNotRunning)
+	a.NoError(wp.Start(context.Background()))
+	a.ErrorIs(wp.Throttle(0.5), ErrRateLimitNotSet)
+	a.NoError(wp.Close())
+
+	wp = NewPool(WithWorkerCount(1), WithRateLimit(100, 1))
+	err = wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(createProcess(300, 1, 0, processFuncWithoutLog)...)
+
+	time.Sleep(time.Second)
+	baseline := wp.Stats().TotalSucceeded
+	err = wp.Throttle(0.25)
+	a.NoError(err)
+	time.Sleep(time.Second)
+	throttled := wp.Stats().TotalSucceeded - baseline
+
+	ratio := float64(throttled) / float64(baseline)
+	a.GreaterOrEqual(ratio, 0.2)
+	a.LessOrEqual(ratio, 0.3)
+	a.ErrorIs(wp.Throttle(1.5), ErrInvalidArgument)
+	a.NoError(wp.Throttle(1))
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Idle workers should not hold tokens, so the burst of a rate limited pool
+// does not grow with the number of workers
+func TestWithRateLimit_Burst(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(8), WithRateLimit(10, 2))
+	a.NoError(wp.Start(context.Background()))
+	time.Sleep(time.Second)
+
+	procs := createProcess(8, 1, 0, processFuncWithoutLog)
+	wp.Register(procs...)
+	time.Sleep(50 * time.Millisecond)
+	started := 0
+	for _, p := range procs {
+		if !processStats(t, wp.Monitor(), p.PID()).StartedAt.IsZero() {
+			started++
+		}
+	}
+	a.Equal(2, started)
+
+	a.NoError(wp.Close())
+	a.Less(wp.(*workerPool).limiter.available(), 1.0)
+}
+
+// Capacity should return the idle and total number of workers
+func TestWorkerPool_Capacity(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(3))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	current, max := wp.Capacity()
+	a.Equal(3, current)
+	a.Equal(3, max)
+
+	wp.Register(createProcess(2, 1, 300*time.Millisecond, processFunc)...)
+	time.Sleep(100 * time.Millisecond)
+	current, max = wp.Capacity()
+	a.Equal(1, current)
+	a.Equal(3, max)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Configuration changes should fail after the pool is locked
+func TestWorkerPool_Lock(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2), WithRateLimit(100, 1))
+	a.Error(wp.Lock())
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	a.NoError(wp.Resize(3))
+
+	err = wp.Lock()
+	a.NoError(err)
+	err = wp.Resize(1)
+	a.ErrorIs(err, ErrPoolLocked)
+	var poolErr *PoolError
+	a.ErrorAs(err, &poolErr)
+	a.Equal(pool.Running, poolErr.CurrentStatus)
+	a.ErrorIs(wp.Scale(1), ErrPoolLocked)
+	a.ErrorIs(wp.EnsureWorkers(5), ErrPoolLocked)
+	a.ErrorIs(wp.Throttle(0.5), ErrPoolLocked)
+	a.Len(wp.Monitor().WorkerList(), 3)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// AwaitIdle should return once all processes are done and workers are idle
+func TestWorkerPool_AwaitIdle(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(3))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	err = wp.Register(createProcess(5, 1, 100*time.Millisecond, processFunc)...)
+	a.NoError(err)
+
+	err = wp.AwaitIdle(context.Background())
+	a.NoError(err)
+	a.Equal(0, wp.Monitor().ActiveWorkerCount())
+	a.Equal(3, wp.Monitor().IdleWorkerCount())
+	a.Equal(int64(5), wp.Stats().TotalSucceeded)
+
+	err = wp.Register(createProcess(1, 2, time.Second, processFunc)...)
+	a.NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	a.ErrorIs(wp.AwaitIdle(ctx), context.DeadlineExceeded)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Processes submitted to a worker handle should run on that worker
+func TestWorkerPool_ForWorker(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(3))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+
+	_, err = wp.ForWorker("W9")
+	a.ErrorIs(err, ErrWorkerNotFound)
+	handle, err := wp.ForWorker("W0")
+	a.NoError(err)
+	for _, p := range createProcess(5, 1, 10*time.Millisecond, processFunc) {
+		a.NoError(handle.Submit(p))
+	}
+	err = handle.Submit(WithDependsOn(newTestProcess("dependent", 21, 0, processFunc), "p-11"))
+	a.ErrorIs(err, ErrInvalidArgument)
+
+	err = wp.AwaitIdle(context.Background())
+	a.NoError(err)
+	for i := 11; i <= 15; i++ {
+		stats := processStats(t, wp.Monitor(), PID("p-"+strconv.Itoa(i)))
+		a.Equal(process.Succeeded, stats.Status)
+		a.Equal(WorkerName("W0"), stats.WorkerName)
+	}
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// WaitContext should return the context error if the processes are not
+// finished in time
+func TestWorkerPool_WaitContext(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(createProcess(2, 1, 300*time.Millisecond, processFunc)...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	a.ErrorIs(wp.WaitContext(ctx), context.DeadlineExceeded)
+	a.NoError(wp.WaitContext(context.Background()))
+	a.Equal(int64(2), wp.Stats().TotalSucceeded)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Wait should return the errors of the failed processes to all callers
+func TestWorkerPool_Wait(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(2))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(createProcess(2, 1, 50*time.Millisecond, processFuncWithoutLog)...)
+	wp.Register(createProcess(2, 2, 100*time.Millisecond, processFuncWithError)...)
+
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			errs <- wp.Wait()
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		var multi *MultiError
+		a.ErrorAs(<-errs, &multi)
+		a.Len(multi.Errors, 2)
+	}
+	a.Equal(int64(2), wp.Stats().TotalSucceeded)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Close should unblock Wait when processes are still waiting
+func TestWorkerPool_WaitClosed(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(createProcess(3, 1, 100*time.Millisecond, processFuncWithoutLog)...)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- wp.Wait()
+	}()
+	time.Sleep(50 * time.Millisecond)
+	a.NoError(wp.Close())
+
+	select {
+	case err := <-errs:
+		a.ErrorIs(err, ErrPoolClosed)
+	case <-time.After(time.Second):
+		a.Fail("Wait is not unblocked by Close")
+	}
+}
+
+// Processes should fail once their timeout is reached
+func TestWithProcessTimeout(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1), WithProcessTimeout(50*time.Millisecond))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(createProcess(1, 1, time.Second, processFuncWithoutLog)...)
+	wp.Wait()
+
+	stats := processStats(t, wp.Monitor(), "p-11")
+	a.Equal(process.Failed, stats.Status)
+	a.InDelta(50*time.Millisecond, stats.FinishedAt.Sub(stats.StartedAt), float64(30*time.Millisecond))
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Timeout jitter should spread the expiry of processes with the same timeout
+func TestWithTimeoutJitter(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(50), WithProcessTimeout(100*time.Millisecond), WithTimeoutJitter(50*time.Millisecond))
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	wp.Register(createProcess(50, 1, time.Second, processFuncWithoutLog)...)
+	wp.Wait()
+
+	var first, last time.Time
+	for _, stats := range wp.Monitor().CompletedProcesses() {
+		a.Equal(process.Failed, stats.Status)
+		a.GreaterOrEqual(stats.FinishedAt.Sub(stats.StartedAt), 100*time.Millisecond)
+		if first.IsZero() || stats.FinishedAt.Before(first) {
+			first = stats.FinishedAt
+		}
+		if stats.FinishedAt.After(last) {
+			last = stats.FinishedAt
+		}
+	}
+	a.Greater(last.Sub(first), 25*time.Millisecond)
+
+	err = wp.Close()
+	a.NoError(err)
+}
+
+// Utilization should be the fraction of busy workers
+func TestWorkerPool_Utilization(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(4))
+	a.Zero(wp.Utilization())
+	err := wp.Start(context.Background())
+	a.NoError(err)
+	a.Zero(wp.Utilization())
+
+	wp.Register(createProcess(3, 1, 200*time.Millisecond, processFunc)...)
+	time.Sleep(50 * time.Millisecond)
+	a.InDelta(0.75, wp.Utilization(), 1e-9)
+
+	wp.Wait()
+	a.Zero(wp.Utilization())
+	err = wp.Close()
+	a.NoError(err)
+}
@@ -0,0 +1,154 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/hamed-yousefi/gowl/status/worker"
+)
+
+type (
+	// WorkerStats represents worker statistics.
+	WorkerStats struct {
+		// WorkerName is the name of the worker.
+		WorkerName WorkerName
+
+		// Status represents the current state of the worker.
+		Status worker.Status
+
+		// CurrentPID is the id of the process that the worker is running. It
+		// is empty if the worker is waiting.
+		CurrentPID PID
+
+		// CurrentProcessStartedAt is the time the worker has started the
+		// current process. It is zero if the worker is waiting.
+		CurrentProcessStartedAt time.Time
+
+		// TotalProcessed is the number of attempts that the worker has
+		// finished since it has started.
+		TotalProcessed int64
+
+		// TotalFailed is the number of the finished attempts that have
+		// failed, including the ones that are retried.
+		TotalFailed int64
+
+		// AverageProcessDuration is the average running time of the finished
+		// attempts.
+		AverageProcessDuration time.Duration
+	}
+
+	// workerCounters tracks the current process and the finished attempts
+	// of each worker.
+	workerCounters struct {
+		mutex   sync.Mutex
+		workers map[WorkerName]*workerCounter
+	}
+
+	// workerCounter is the state of one worker.
+	workerCounter struct {
+		pid       PID
+		startedAt time.Time
+		processed int64
+		failed    int64
+		runTime   time.Duration
+	}
+)
+
+// newWorkerCounters makes a new instance of workerCounters.
+func newWorkerCounters() *workerCounters {
+	return &workerCounters{workers: make(map[WorkerName]*workerCounter)}
+}
+
+// begin records that the worker has started the process.
+func (c *workerCounters) begin(name WorkerName, pid PID, startedAt time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	counter, ok := c.workers[name]
+	if !ok {
+		counter = new(workerCounter)
+		c.workers[name] = counter
+	}
+	counter.pid = pid
+	counter.startedAt = startedAt
+}
+
+// end records that the worker has finished its current attempt with the
+// status.
+func (c *workerCounters) end(name WorkerName, status process.Status, d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	counter, ok := c.workers[name]
+	if !ok {
+		return
+	}
+	counter.pid = ""
+	counter.startedAt = time.Time{}
+	counter.processed++
+	counter.runTime += d
+	if status == process.Failed || status == process.Retrying {
+		counter.failed++
+	}
+}
+
+// delete removes the worker.
+func (c *workerCounters) delete(name WorkerName) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.workers, name)
+}
+
+// reset zeroes the totals of all the workers. The current processes are
+// kept.
+func (c *workerCounters) reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, counter := range c.workers {
+		counter.processed = 0
+		counter.failed = 0
+		counter.runTime = 0
+	}
+}
+
+// fill copies the state of the worker to stats.
+func (c *workerCounters) fill(stats *WorkerStats) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	counter, ok := c.workers[stats.WorkerName]
+	if !ok {
+		return
+	}
+	stats.CurrentPID = counter.pid
+	stats.CurrentProcessStartedAt = counter.startedAt
+	stats.TotalProcessed = counter.processed
+	stats.TotalFailed = counter.failed
+	if counter.processed > 0 {
+		stats.AverageProcessDuration = counter.runTime / time.Duration(counter.processed)
+	}
+}
+
+// WorkerStats returns the stats of the worker. Like a map lookup, it returns
+// false if the pool has no such worker. The totals are counted since the
+// worker has started, and they are zeroed by ResetStats. The workers are
+// shared by the pool, so the namespaced and filtered monitors return the
+// same stats as the pool monitor.
+func (w *workerPool) WorkerStats(name WorkerName) (WorkerStats, bool) {
+	status, ok := w.workersStats.lookup(name)
+	if !ok {
+		return WorkerStats{}, false
+	}
+
+	stats := WorkerStats{WorkerName: name, Status: status}
+	w.workerCounters.fill(&stats)
+
+	return stats, true
+}
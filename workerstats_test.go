@@ -0,0 +1,87 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ */
+
+package gowl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hamed-yousefi/gowl/status/process"
+	"github.com/hamed-yousefi/gowl/status/worker"
+)
+
+// WorkerStats should show the running process of a worker and count its
+// finished attempts
+func TestWorkerPool_WorkerStats(t *testing.T) {
+	a := assert.New(t)
+	wp := NewPool(WithWorkerCount(1))
+	a.NoError(wp.Start(context.Background()))
+	wn := wp.Monitor().WorkerList()[0]
+
+	stats, ok := wp.Monitor().WorkerStats(wn)
+	a.True(ok)
+	a.Equal(WorkerStats{WorkerName: wn, Status: worker.Waiting}, stats)
+	_, ok = wp.Monitor().WorkerStats("unknown")
+	a.False(ok)
+
+	wp.Register(newTestProcess("job", 1, time.Second, processFuncWithoutLog))
+	_, err := wp.WaitUntilStatus(context.Background(), "p-1", process.Running)
+	a.NoError(err)
+	stats, ok = wp.Monitor().WorkerStats(wn)
+	a.True(ok)
+	a.Equal(worker.Busy, stats.Status)
+	a.Equal(PID("p-1"), stats.CurrentPID)
+	a.Equal(processStats(t, wp.Monitor(), "p-1").StartedAt, stats.CurrentProcessStartedAt)
+	a.Zero(stats.TotalProcessed)
+	wp.Kill("p-1")
+	_ = wp.Wait()
+
+	wp.Register(
+		newTestProcess("job", 2, 10*time.Millisecond, processFuncWithoutLog),
+		newTestProcess("job", 3, 0, processFuncWithError),
+	)
+	_ = wp.Wait()
+	stats, _ = wp.Monitor().WorkerStats(wn)
+	a.Equal(worker.Waiting, stats.Status)
+	a.Empty(stats.CurrentPID)
+	a.True(stats.CurrentProcessStartedAt.IsZero())
+	a.Equal(int64(3), stats.TotalProcessed)
+	a.Equal(int64(1), stats.TotalFailed)
+	a.Greater(stats.AverageProcessDuration, time.Duration(0))
+
+	a.NoError(wp.Monitor().ResetStats())
+	stats, _ = wp.Monitor().WorkerStats(wn)
+	a.Zero(stats.TotalProcessed)
+	a.Zero(stats.AverageProcessDuration)
+
+	a.NoError(wp.Close())
+	_, ok = wp.Monitor().WorkerStats(wn)
+	a.False(ok)
+}
+
+// The router should return the stats of its workers with the pool index
+func TestRouterMonitor_WorkerStats(t *testing.T) {
+	a := assert.New(t)
+	r := NewConsistentHashRouter([]Pool{NewPool(WithWorkerCount(1)), NewPool(WithWorkerCount(1))}, 50)
+	a.NoError(r.Start(context.Background()))
+	a.NoError(r.Register(createProcess(4, 1, 0, processFuncWithoutLog)...))
+	_ = r.Wait()
+
+	total := int64(0)
+	for _, wn := range r.Monitor().WorkerList() {
+		stats, ok := r.Monitor().WorkerStats(wn)
+		a.True(ok)
+		a.Equal(wn, stats.WorkerName)
+		total += stats.TotalProcessed
+	}
+	a.Equal(int64(4), total)
+	_, ok := r.Monitor().WorkerStats("W0")
+	a.False(ok)
+
+	a.NoError(r.Close())
+}